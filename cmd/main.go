@@ -2,11 +2,27 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"medrussia_news_bot/internal"
+	"os"
 )
 
 func main() {
 	ctx := context.Background()
+
+	// reencrypt перешифровывает данные под активный ключ после его смены,
+	// reencrypt --full - новыми ключами данных, если старый ключ скомпрометирован
+	if len(os.Args) > 1 && os.Args[1] == "reencrypt" {
+		flags := flag.NewFlagSet("reencrypt", flag.ExitOnError)
+		full := flags.Bool("full", false, "encrypt all data with new data keys, not only rewrap them")
+		_ = flags.Parse(os.Args[2:])
+
+		if err := internal.NewReencryptApp(ctx).Reencrypt(ctx, *full); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	log.Fatal(internal.NewApp(ctx).Run(ctx))
 }
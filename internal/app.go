@@ -7,8 +7,18 @@ import (
 	"medrussia_news_bot/internal/infrastructure/controller"
 	"medrussia_news_bot/internal/infrastructure/controller/bot_controller"
 	"medrussia_news_bot/internal/infrastructure/repo"
+	"medrussia_news_bot/internal/pkg/envelope"
+	"medrussia_news_bot/internal/pkg/filestore"
 	"medrussia_news_bot/internal/pkg/postgres"
 	"medrussia_news_bot/internal/pkg/telegram"
+	"medrussia_news_bot/internal/service/access"
+	"medrussia_news_bot/internal/service/alert"
+	"medrussia_news_bot/internal/service/anonymity"
+	"medrussia_news_bot/internal/service/audit"
+	"medrussia_news_bot/internal/service/evidence"
+	"medrussia_news_bot/internal/service/sanitize"
+	"medrussia_news_bot/internal/service/spam"
+	"medrussia_news_bot/internal/service/triage"
 	"net/http"
 )
 
@@ -26,6 +36,16 @@ type App struct {
 	controllers controllers
 	server      *http.Server
 	repo        *repo.Repo
+	audit       *audit.Service
+	access      *access.Service
+	alerts      *alert.Service
+	spam        *spam.Filter
+	triage      *triage.Matcher
+	anonymity   *anonymity.Service
+	sanitizer   *sanitize.Service
+	evidence    *evidence.Service
+	keyring     *envelope.Keyring
+	files       *filestore.Encrypted
 }
 
 func NewApp(ctx context.Context) *App {
@@ -34,8 +54,18 @@ func NewApp(ctx context.Context) *App {
 	a.initLogger(ctx).
 		initConfig(ctx).
 		initPgxConn(ctx).
+		initKeyring(ctx).
 		initRepo(ctx).
+		initAudit(ctx).
+		initAccess(ctx).
 		initBot(ctx).
+		initAlerts(ctx).
+		initSpam(ctx).
+		initTriage(ctx).
+		initAnonymity(ctx).
+		initFileStore(ctx).
+		initSanitize(ctx).
+		initEvidence(ctx).
 		iniControllers(ctx).
 		initBotController(ctx).
 		initServer(ctx)
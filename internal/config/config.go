@@ -36,10 +36,139 @@ type StorageConfig struct {
 }
 
 type BotConfig struct {
-	Token         string        `yaml:"token"`
-	WebhookURL    string        `yaml:"webhook"`
-	UpdatesConfig UpdatesConfig `yaml:"updates_config"`
-	AdminChatID   string        `yaml:"admin_chat_id"`
+	Token         string           `yaml:"token"`
+	WebhookURL    string           `yaml:"webhook"`
+	UpdatesConfig UpdatesConfig    `yaml:"updates_config"`
+	AdminChatID   string           `yaml:"admin_chat_id"`
+	Roles         RolesConfig      `yaml:"roles"`
+	Alerts        AlertsConfig     `yaml:"alerts"`
+	RateLimit     RateLimitConfig  `yaml:"rate_limit"`
+	Spam          SpamConfig       `yaml:"spam"`
+	Triage        TriageConfig     `yaml:"triage"`
+	Anonymity     AnonymityConfig  `yaml:"anonymity"`
+	Sanitize      SanitizeConfig   `yaml:"sanitize"`
+	Evidence      EvidenceConfig   `yaml:"evidence"`
+	Files         FilesConfig      `yaml:"files"`
+	Encryption    EncryptionConfig `yaml:"encryption"`
+}
+
+// EncryptionConfig шифрование переписки, данных источников и файлов в хранилище (AES-256-GCM).
+// Новые данные шифруются ключом ActiveKey. Включение и смена ключей описаны в README.md
+type EncryptionConfig struct {
+	Enabled   bool            `yaml:"enabled" env-default:"false"`
+	ActiveKey string          `yaml:"active_key" env:"ENCRYPTION_ACTIVE_KEY"`
+	Keys      []EncryptionKey `yaml:"keys"`
+}
+
+// EncryptionKey ключ шифрования: 32 байта в base64 в Key или в файле File
+type EncryptionKey struct {
+	ID   string `yaml:"id"`
+	Key  string `yaml:"key"`
+	File string `yaml:"file"`
+}
+
+// FilesConfig хранилище файлов: local - каталог Dir на сервере, s3 - S3-совместимое хранилище.
+// Archive включает архив всех полученных файлов, документы архивируются уже очищенными
+// от метаданных, а их оригиналы лежат там же под закрытым префиксом originals/
+type FilesConfig struct {
+	Store       string   `yaml:"store" env-default:"local"`
+	Dir         string   `yaml:"dir" env-default:"./data/files"`
+	Archive     bool     `yaml:"archive" env-default:"true"`
+	MaxFileSize int64    `yaml:"max_file_size" env-default:"20971520"`
+	S3          S3Config `yaml:"s3"`
+}
+
+// S3Config S3-совместимое хранилище. PathStyle - адрес вида endpoint/bucket, нужен для MinIO
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint"`
+	Region    string `yaml:"region" env-default:"us-east-1"`
+	Bucket    string `yaml:"bucket"`
+	AccessKey string `yaml:"access_key" env:"S3_ACCESS_KEY"`
+	SecretKey string `yaml:"secret_key" env:"S3_SECRET_KEY"`
+	PathStyle bool   `yaml:"path_style" env-default:"true"`
+}
+
+// EvidenceConfig учет полученных материалов: для каждого файла сохраняются SHA-256, размер и время
+// получения. SigningKey - base64 seed ключа Ed25519 (32 байта), которым /evidence подписывает опись.
+// Без ключа опись выдается неподписанной. Bot API не отдает файлы больше 20 МБ, их хеш не вычисляется
+type EvidenceConfig struct {
+	Enabled     bool   `yaml:"enabled" env-default:"true"`
+	SigningKey  string `yaml:"signing_key" env:"EVIDENCE_SIGNING_KEY"`
+	MaxFileSize int64  `yaml:"max_file_size" env-default:"20971520"`
+}
+
+// SanitizeConfig очистка документов от метаданных перед пересылкой редакции: EXIF и XMP фото,
+// присланных файлом, и свойства PDF и документов Office. Оригиналы сохраняются в хранилище файлов
+// под закрытым префиксом originals/. Документ неподдерживаемого формата или больше MaxFileSize
+// пересылается как есть с пометкой, что метаданные не удалены, а со Strict задерживается.
+// Поврежденный документ поддерживаемого формата или с неудаленными метаданными редакции
+// не пересылается: оригинал выдает суперадминам /original
+type SanitizeConfig struct {
+	Enabled     bool  `yaml:"enabled" env-default:"true"`
+	MaxFileSize int64 `yaml:"max_file_size" env-default:"20971520"`
+	Strict      bool  `yaml:"strict" env-default:"false"`
+}
+
+// AnonymityConfig режим защиты источников: в чате админов вместо имени и username пользователя
+// показывается псевдоним, вычисленный по telegram ID с ключом Secret. Настоящие данные
+// хранятся отдельно и доступны суперадминам через /reveal. Смена Secret меняет все псевдонимы
+type AnonymityConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Secret  string `yaml:"secret" env:"ANONYMITY_SECRET"`
+}
+
+// TriageConfig правила срочности входящих сообщений. Keywords дополняют встроенный список
+// основ слов, Patterns - регулярные выражения. Сработавшее правило отмечает обращение срочным,
+// закрепляет карточку, если Pin, и упоминает дежурных редакторов OnCall
+type TriageConfig struct {
+	Keywords []string `yaml:"keywords"`
+	Patterns []string `yaml:"patterns"`
+	OnCall   []int64  `yaml:"on_call"`
+	Pin      bool     `yaml:"pin" env-default:"true"`
+}
+
+// SpamConfig фильтр спама перед пересылкой редакции. Сообщение уходит в карантин,
+// если сумма очков правил достигла Threshold. Phrases дополняют встроенный список фраз,
+// NewUserBurst сообщений за NewUserWindow от нового пользователя считаются подозрительными
+type SpamConfig struct {
+	Enabled       bool          `yaml:"enabled" env-default:"true"`
+	Threshold     float64       `yaml:"threshold" env-default:"1"`
+	MaxLinks      int           `yaml:"max_links" env-default:"2"`
+	Phrases       []string      `yaml:"phrases"`
+	NewUserWindow time.Duration `yaml:"new_user_window" env-default:"1h"`
+	NewUserBurst  int           `yaml:"new_user_burst" env-default:"5"`
+}
+
+// RateLimitConfig ограничение частоты сообщений от одного пользователя.
+// Rate - сколько сообщений в секунду восстанавливается, Burst - сколько можно отправить подряд,
+// после превышения пользователь заглушается на MuteDuration, а после WarnAfterTrips превышений
+// за TripWindow админы получают предупреждение. Rate = 0 выключает ограничение
+type RateLimitConfig struct {
+	Rate           float64       `yaml:"rate" env-default:"0.2"`
+	Burst          int           `yaml:"burst" env-default:"10"`
+	MuteDuration   time.Duration `yaml:"mute_duration" env-default:"10m"`
+	WarnAfterTrips int           `yaml:"warn_after_trips" env-default:"3"`
+	TripWindow     time.Duration `yaml:"trip_window" env-default:"24h"`
+}
+
+// AlertsConfig оповещения об ошибках: уровни info, warning, error, critical.
+// В чат оповещений уходит все начиная с min_severity, получателям в личку - начиная с direct_severity,
+// если чат не задан - все начиная с min_severity. Без получателей оповещения получают суперадмины
+type AlertsConfig struct {
+	Recipients     []int64       `yaml:"recipients"`
+	ChatID         int64         `yaml:"chat_id"`
+	MinSeverity    string        `yaml:"min_severity" env-default:"warning"`
+	DirectSeverity string        `yaml:"direct_severity" env-default:"critical"`
+	DedupWindow    time.Duration `yaml:"dedup_window" env-default:"10m"`
+}
+
+// RolesConfig роли участников чата админов: superadmin, editor, viewer.
+// Роли редакторов и наблюдателей можно менять командой /role, суперадмины задаются только здесь
+type RolesConfig struct {
+	SuperAdmins []int64 `yaml:"super_admins"`
+	Editors     []int64 `yaml:"editors"`
+	Viewers     []int64 `yaml:"viewers"`
+	Default     string  `yaml:"default" env-default:"viewer"`
 }
 
 type UpdatesConfig struct {
@@ -0,0 +1,214 @@
+package bot_controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"medrussia_news_bot/internal/infrastructure/repo"
+	"medrussia_news_bot/internal/service/access"
+	"medrussia_news_bot/internal/service/audit"
+	"slices"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// adminCommandPermissions права, необходимые для команд в чате админов
+var adminCommandPermissions = map[string]access.Permission{
+	"recall":     access.PermissionReply,
+	"assign":     access.PermissionManage,
+	"assigned":   access.PermissionRead,
+	"workload":   access.PermissionRead,
+	"audit":      access.PermissionAdmin,
+	"role":       access.PermissionAdmin,
+	"ban":        access.PermissionManage,
+	"unban":      access.PermissionManage,
+	"quarantine": access.PermissionRead,
+	"categories": access.PermissionRead,
+	"appeals":    access.PermissionRead,
+	"regions":    access.PermissionRead,
+	"region":     access.PermissionRead,
+	"reveal":     access.PermissionAdmin,
+	"evidence":   access.PermissionRead,
+	"files":      access.PermissionRead,
+	"file":       access.PermissionRead,
+	"original":   access.PermissionAdmin,
+}
+
+// ForkAdminCommands обработка команд в чате админов
+func (t TelegramWebhookController) ForkAdminCommands(ctx context.Context, update tgbotapi.Update) {
+	permission, ok := adminCommandPermissions[update.Message.Command()]
+	if !ok {
+		return
+	}
+	if !t.access.Can(ctx, update.Message.From.ID, permission) {
+		t.replyToAdmin(update, permissionDeniedMessage)
+		return
+	}
+
+	switch update.Message.Command() {
+	case "recall":
+		t.processRecallCommand(ctx, update)
+	case "assign":
+		t.processAssignCommand(ctx, update)
+	case "assigned":
+		t.processAssignedCommand(ctx, update)
+	case "workload":
+		t.processWorkloadCommand(ctx, update)
+	case "audit":
+		t.processAuditCommand(ctx, update)
+	case "role":
+		t.processRoleCommand(ctx, update)
+	case "ban":
+		t.processBanCommand(ctx, update)
+	case "unban":
+		t.processUnbanCommand(ctx, update)
+	case "quarantine":
+		t.processQuarantineCommand(ctx, update)
+	case "categories":
+		t.processCategoriesCommand(ctx, update)
+	case "appeals":
+		t.processCategoryAppealsCommand(ctx, update)
+	case "regions":
+		t.processRegionsCommand(ctx, update)
+	case "region":
+		t.processRegionAppealsCommand(ctx, update)
+	case "reveal":
+		t.processRevealCommand(ctx, update)
+	case "evidence":
+		t.processEvidenceCommand(ctx, update)
+	case "files":
+		t.processFilesCommand(ctx, update)
+	case "file":
+		t.processFileCommand(ctx, update)
+	case "original":
+		t.processOriginalCommand(ctx, update)
+	}
+}
+
+// replyToAdmin ответ на команду в чате админов
+func (t TelegramWebhookController) replyToAdmin(update tgbotapi.Update, text string) {
+	_, err := t.bot.ReplyInAdminChat(int64(update.Message.MessageID), text)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+	}
+}
+
+// processRecallCommand /recall ответом на свой ответ пользователю удаляет доставленную копию
+func (t TelegramWebhookController) processRecallCommand(ctx context.Context, update tgbotapi.Update) {
+	replyTo := update.Message.ReplyToMessage
+	if replyTo == nil {
+		t.replyToAdmin(update, "Отправьте /recall ответом на сообщение, которое нужно удалить у пользователя")
+		return
+	}
+
+	link, err := t.repo.GetMessageLink(ctx, int64(replyTo.MessageID))
+	if err != nil && !errors.Is(err, repo.ErrMessageLinkNotFound) {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return
+	}
+	if link == nil || link.Direction != repo.DirectionAdminToUser {
+		t.replyToAdmin(update, "Это сообщение не отправлялось пользователю")
+		return
+	}
+	if link.RecalledAt.Valid {
+		t.replyToAdmin(update, "Сообщение уже удалено у пользователя")
+		return
+	}
+
+	err = t.bot.RecallAdminReply(link.UserID, link.UserMessageID)
+	if err != nil {
+		t.replyToAdmin(update, "Не удалось удалить сообщение у пользователя: "+err.Error())
+		return
+	}
+
+	err = t.repo.MarkMessageLinkRecalled(ctx, link.AdminChatMessageID)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+	}
+	t.audit.Record(ctx, update.Message.From.ID, audit.ActionRecall, link.AppealID.Int64, audit.Payload{
+		"user_id":          link.UserID,
+		"admin_message_id": link.AdminChatMessageID,
+		"user_message_id":  link.UserMessageID,
+	})
+
+	t.replyToAdmin(update, "Сообщение удалено у пользователя")
+}
+
+// processAuditCommand /audit <номер обращения> выводит журнал действий редакторов по обращению
+func (t TelegramWebhookController) processAuditCommand(ctx context.Context, update tgbotapi.Update) {
+	appealID, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimSpace(update.Message.CommandArguments()), "#"), 10, 64)
+	if err != nil {
+		t.replyToAdmin(update, "Укажите номер обращения: /audit 42")
+		return
+	}
+
+	records, err := t.audit.Trail(ctx, appealID)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		t.replyToAdmin(update, "Ошибка при получении журнала")
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Журнал действий по обращению #%d:\n", appealID)
+	if len(records) == 0 {
+		b.WriteString("\nдействий нет")
+	}
+	for _, record := range records {
+		fmt.Fprintf(&b, "\n%s — %s — %s", record.CreatedAt.Format("02.01.2006 15:04:05"), t.editorName(ctx, record.ActorID), record.Action)
+		if payload := t.auditPayloadText(record.Payload); payload != "" {
+			fmt.Fprintf(&b, " (%s)", payload)
+		}
+	}
+
+	t.replyToAdmin(update, b.String())
+}
+
+// auditPayloadText данные записи аудита для чата: ID пользователя заменяется на sourceName,
+// чтобы /audit не раскрывал источник при включенной анонимности
+func (t TelegramWebhookController) auditPayloadText(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var payload audit.Payload
+	if err := decoder.Decode(&payload); err != nil {
+		t.logger.Error(fmt.Sprintf("Ошибка разбора данных аудита: %s", err))
+		return ""
+	}
+
+	parts := make([]string, 0, len(payload))
+	for _, key := range slices.Sorted(maps.Keys(payload)) {
+		value := fmt.Sprint(payload[key])
+		if key == "user_id" {
+			userID, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			key, value = "источник", t.sourceName(userID)
+		}
+		parts = append(parts, key+": "+value)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// editorName имя редактора для отчетов, если он неизвестен - его telegram ID
+func (t TelegramWebhookController) editorName(ctx context.Context, editorID int64) string {
+	editor, err := t.repo.GetEditor(ctx, editorID)
+	if err != nil {
+		if !errors.Is(err, repo.ErrEditorNotFound) {
+			t.logger.Error(fmt.Sprintf("%s", err))
+		}
+		return strconv.FormatInt(editorID, 10)
+	}
+
+	return editor.DisplayName()
+}
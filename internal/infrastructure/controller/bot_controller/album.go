@@ -0,0 +1,222 @@
+package bot_controller
+
+import (
+	"context"
+	"fmt"
+	"medrussia_news_bot/internal/infrastructure/repo"
+	"medrussia_news_bot/internal/pkg/telegram"
+	"medrussia_news_bot/internal/service/evidence"
+	"runtime/debug"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// albumWindow сколько ждем остальные части альбома после последней пришедшей
+const albumWindow = 2 * time.Second
+
+// album части одного альбома, пришедшие отдельными вебхуками
+type album struct {
+	updates []tgbotapi.Update
+	timer   *time.Timer
+}
+
+// albumBuffer копит сообщения с одинаковым media_group_id, вебхуки приходят конкурентно
+type albumBuffer struct {
+	mu     sync.Mutex
+	albums map[string]*album
+	window time.Duration
+}
+
+func newAlbumBuffer(window time.Duration) *albumBuffer {
+	return &albumBuffer{
+		albums: make(map[string]*album),
+		window: window,
+	}
+}
+
+// add добавляет часть альбома, flush вызывается один раз, когда части перестали приходить.
+// Если таймер альбома уже сработал, часть начинает новый альбом: сработавший таймер
+// не перезапускается, иначе он сбросил бы раньше времени или слил бы два альбома
+func (b *albumBuffer) add(update tgbotapi.Update, flush func(updates []tgbotapi.Update)) {
+	key := fmt.Sprintf("%d_%s", update.Message.Chat.ID, update.Message.MediaGroupID)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if a, ok := b.albums[key]; ok && a.timer.Stop() {
+		a.updates = append(a.updates, update)
+		a.timer.Reset(b.window)
+		return
+	}
+
+	a := &album{updates: []tgbotapi.Update{update}}
+	a.timer = time.AfterFunc(b.window, func() {
+		b.mu.Lock()
+		if b.albums[key] == a {
+			delete(b.albums, key)
+		}
+		updates := a.updates
+		b.mu.Unlock()
+
+		// вебхуки могут прийти не по порядку
+		sort.Slice(updates, func(i, j int) bool {
+			return updates[i].Message.MessageID < updates[j].Message.MessageID
+		})
+		flush(updates)
+	})
+	b.albums[key] = a
+}
+
+// ForkAlbum обработка альбома пользователя, собранного из нескольких сообщений.
+// Вызывается таймером буфера уже после ответа на вебхук, поэтому паника перехватывается здесь
+func (t TelegramWebhookController) ForkAlbum(ctx context.Context, updates []tgbotapi.Update) {
+	defer t.recoverAlbum(updates)
+
+	if !t.acceptUserMessage(ctx, updates[0]) {
+		return
+	}
+	// альбом проверяется фильтром так же, как одиночное сообщение
+	if t.quarantineAlbum(ctx, updates) {
+		return
+	}
+
+	user, appeal, ok := t.openDialog(ctx, updates[0])
+	if !ok {
+		return
+	}
+
+	t.forwardAlbumToAdmin(ctx, user, appeal, updates)
+}
+
+// recoverAlbum альбом обрабатывается вне обработчика вебхука: без recover паника остановила бы бота,
+// а Telegram альбом повторно не пришлет, поэтому редакции уходит оповещение
+func (t TelegramWebhookController) recoverAlbum(updates []tgbotapi.Update) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	t.logger.Error(fmt.Sprintf("panic while processing album: %v\n%s", r, debug.Stack()))
+	t.alerts.Critical("album", fmt.Sprintf("Альбом от %s (%d вложений) не обработан из-за внутренней ошибки: %v",
+		t.sourceName(updates[0].Message.From.ID), len(updates), r))
+}
+
+// forwardAlbumToAdmin пересылаем альбом админам одним sendMediaGroup и одной карточкой с клавиатурой
+func (t TelegramWebhookController) forwardAlbumToAdmin(
+	ctx context.Context,
+	user *repo.UserDialog,
+	appeal *repo.Appeal,
+	updates []tgbotapi.Update,
+) {
+	items := make([]telegram.MediaGroupItem, 0, len(updates))
+	received := make([]receivedFile, 0, len(updates))
+	hashes := make([]string, 0, len(updates))
+	messages := make([]repo.Message, 0, len(updates))
+	captions := make([]string, 0, len(updates))
+	// задержанные документы в альбом не попадают, пометка о них идет в карточку альбома
+	heldFiles := make([]receivedFile, 0)
+	heldMessages := make([]repo.Message, 0)
+	for _, update := range updates {
+		captions = append(captions, update.Message.Caption)
+		tgMessage := t.getMessageFromWebhook(update)
+		media, ok := tgMessage.Media()
+		if !ok {
+			continue
+		}
+
+		file := t.receiveFile(ctx, appeal.ID, user.UserID, tgMessage, media)
+		if hash := evidence.ShortHash(file.evidence); hash != "" {
+			hashes = append(hashes, hash)
+		}
+
+		message := newLogMessage(repo.DirectionUserToAdmin, appeal.ID, user.UserID, update.Message.From.ID, tgMessage)
+		message.UserChatMessageID = nullInt64(int64(update.Message.MessageID))
+		message.StorageKey = nullString(file.storageKey)
+		message.FileName = nullString(file.fileName)
+		message.CardNote = nullString(strings.Join(file.notes, "\n"))
+		if file.held() {
+			heldFiles = append(heldFiles, file)
+			heldMessages = append(heldMessages, message)
+			continue
+		}
+		messages = append(messages, message)
+
+		item := telegram.MediaGroupItem{
+			Kind:    media.Kind,
+			FileID:  media.FileID,
+			Caption: tgMessage.Caption,
+		}
+		if file.cleaned != nil {
+			item.Data, item.FileName = file.cleaned.Data, file.fileName
+		}
+		// хеш показывается в карточке альбома, остальные пометки - в подписи вложения
+		item.Caption = withNote(strings.Join(file.notes, "\n"), item.Caption)
+		items = append(items, item)
+		received = append(received, file)
+	}
+
+	// если задержаны все вложения, карточка уходит без альбома
+	replyTo := user.LastAdminMessageID.Int64
+	var albumMessageIDs []int64
+	if len(items) > 0 {
+		var err error
+		albumMessageIDs, err = t.bot.SendMediaGroupToAdminChat(replyTo, items)
+		if err != nil {
+			t.alerts.Critical("forward", fmt.Sprintf("Не удалось переслать обращение #%d в чат админов: %s", appeal.ID, err))
+			return
+		}
+		if len(albumMessageIDs) == 0 {
+			return
+		}
+		replyTo = albumMessageIDs[0]
+	}
+	for i, file := range received {
+		if i < len(albumMessageIDs) {
+			t.sanitizer.LinkMessage(ctx, file.cleaned, albumMessageIDs[i])
+		}
+	}
+
+	marker, escalated := t.triageAppeal(ctx, appeal, strings.Join(captions, "\n"))
+	header := cardHeading(marker, t.cardHeader(ctx, updates[0].Message.Chat), "")
+
+	text := fmt.Sprintf("%s\n\nАльбом: %d вложений", header, len(updates))
+	if len(hashes) > 0 {
+		text += "\n🔐 SHA-256: " + strings.Join(hashes, ", ")
+	}
+	for _, file := range heldFiles {
+		text += "\n" + strings.Join(file.notes, "\n")
+	}
+	forwardMessageID, err := t.bot.ForwardMessageToAdminChat(replyTo, t.cardState(ctx, appeal), text)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return
+	}
+
+	t.saveAdminCard(ctx, user, forwardMessageID)
+	if escalated {
+		t.escalateCard(ctx, appeal, forwardMessageID)
+	}
+	// карточку альбома связываем с первым сообщением альбома
+	if all := slices.Concat(messages, heldMessages); len(all) > 0 {
+		t.saveMessageLinks(ctx, []int64{forwardMessageID}, user.UserID, all[0].UserChatMessageID.Int64, appeal.ID)
+	}
+
+	for i, message := range messages {
+		if i < len(albumMessageIDs) {
+			message.AdminChatMessageID = nullInt64(albumMessageIDs[i])
+			t.saveMessageLinks(ctx, albumMessageIDs[i:i+1], user.UserID, message.UserChatMessageID.Int64, appeal.ID)
+		}
+		t.saveLogMessage(ctx, message)
+	}
+	// оригиналы задержанных документов выдаются по ответу на карточку альбома
+	for i, message := range heldMessages {
+		t.sanitizer.LinkMessage(ctx, heldFiles[i].cleaned, forwardMessageID)
+		message.AdminChatMessageID = nullInt64(forwardMessageID)
+		t.saveLogMessage(ctx, message)
+	}
+}
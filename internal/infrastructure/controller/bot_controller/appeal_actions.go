@@ -0,0 +1,275 @@
+package bot_controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"medrussia_news_bot/internal/infrastructure/repo"
+	"medrussia_news_bot/internal/pkg/callback"
+	"medrussia_news_bot/internal/service/audit"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// appealStatusNames названия статусов обращения для чата админов
+var appealStatusNames = map[string]string{
+	repo.AppealStatusOpen:       "открыто",
+	repo.AppealStatusInProgress: "в работе",
+	repo.AppealStatusClosed:     "закрыто",
+	repo.AppealStatusSpam:       "спам",
+}
+
+// callbackAppeal обращение, к которому относится кнопка.
+// В кнопках старого формата есть только ID пользователя, для них берем незакрытое обращение
+func (t TelegramWebhookController) callbackAppeal(ctx context.Context, data callback.Data) (*repo.Appeal, error) {
+	if appealID := data.Arg(0); appealID != 0 {
+		return t.repo.GetAppeal(ctx, appealID)
+	}
+	if data.LegacyUserID == 0 {
+		return nil, repo.ErrAppealNotFound
+	}
+
+	return t.repo.GetOpenAppeal(ctx, data.LegacyUserID)
+}
+
+// recordAppealEvent запись действия редактора над обращением. Журнал аудита - единственный источник
+// истории обращения: его показывают и кнопка «История», и /audit
+func (t TelegramWebhookController) recordAppealEvent(
+	ctx context.Context,
+	appealID int64,
+	action string,
+	actorID int64,
+	payload audit.Payload,
+) {
+	t.audit.Record(ctx, actorID, action, appealID, payload)
+}
+
+// renderCard перерисовка клавиатуры карточки под текущее состояние обращения
+func (t TelegramWebhookController) renderCard(ctx context.Context, messageID, appealID int64) {
+	appeal, err := t.repo.GetAppeal(ctx, appealID)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return
+	}
+
+	updatedMessageID, err := t.bot.SetCardKeyboard(messageID, t.cardState(ctx, appeal))
+	if err != nil {
+		if strings.Contains(err.Error(), "message to edit not found") || strings.Contains(err.Error(), "message is not modified") {
+			t.logger.Warn(err.Error())
+			return
+		}
+		t.alerts.Error(
+			"card",
+			fmt.Sprintf(
+				"Ошибка при изменении карточки обращения (изменение сообщения messageID: %d) %v",
+				updatedMessageID,
+				err.Error(),
+			),
+		)
+	}
+}
+
+// appealActionFailed ответ на кнопку, если не удалось получить или изменить обращение
+func (t TelegramWebhookController) appealActionFailed(err error) callbackAnswer {
+	if errors.Is(err, repo.ErrAppealNotFound) {
+		return callbackAnswer{Text: "Обращение не найдено", Alert: true}
+	}
+
+	t.logger.Error(fmt.Sprintf("%s", err))
+	return callbackAnswer{Text: "Ошибка при изменении обращения", Alert: true}
+}
+
+// processCloseCallback обработка сallback закрытия обращения пользователя со стороны админа
+func (t TelegramWebhookController) processCloseCallback(ctx context.Context, update tgbotapi.Update, data callback.Data) callbackAnswer {
+	appeal, err := t.callbackAppeal(ctx, data)
+	if err != nil {
+		return t.appealActionFailed(err)
+	}
+	if appeal.IsClosed() {
+		return callbackAnswer{Text: "Обращение уже закрыто"}
+	}
+
+	editorID := update.CallbackQuery.From.ID
+	err = t.repo.CloseAppeal(ctx, appeal.UserID, editorID)
+	if err != nil {
+		t.logger.Error("Ошибка при закрытии обращения пользователя: " + err.Error())
+		return callbackAnswer{Text: "Ошибка при закрытии обращения", Alert: true}
+	}
+
+	t.recordAppealEvent(ctx, appeal.ID, audit.ActionClose, editorID, nil)
+	t.renderCard(ctx, int64(update.CallbackQuery.Message.MessageID), appeal.ID)
+
+	return callbackAnswer{Text: "Обращение закрыто"}
+}
+
+// processTakeCallback редактор берет обращение в работу и становится ответственным
+func (t TelegramWebhookController) processTakeCallback(ctx context.Context, update tgbotapi.Update, data callback.Data) callbackAnswer {
+	appeal, err := t.callbackAppeal(ctx, data)
+	if err != nil {
+		return t.appealActionFailed(err)
+	}
+	if appeal.IsClosed() {
+		return callbackAnswer{Text: "Обращение закрыто, сначала переоткройте его", Alert: true}
+	}
+	if appeal.Status == repo.AppealStatusInProgress {
+		return callbackAnswer{Text: "Обращение уже в работе"}
+	}
+
+	editorID := update.CallbackQuery.From.ID
+	err = t.assignAppeal(ctx, appeal, editorID, editorID, audit.ActionTake)
+	if err != nil {
+		return t.appealActionFailed(err)
+	}
+	t.renderCard(ctx, int64(update.CallbackQuery.Message.MessageID), appeal.ID)
+
+	return callbackAnswer{Text: "Обращение взято в работу"}
+}
+
+// processUrgentCallback отметка срочности обращения, повторное нажатие снимает отметку
+func (t TelegramWebhookController) processUrgentCallback(ctx context.Context, update tgbotapi.Update, data callback.Data) callbackAnswer {
+	appeal, err := t.callbackAppeal(ctx, data)
+	if err != nil {
+		return t.appealActionFailed(err)
+	}
+
+	urgent := !appeal.Urgent
+	if err = t.repo.SetAppealUrgent(ctx, appeal.ID, urgent); err != nil {
+		return t.appealActionFailed(err)
+	}
+
+	action, answer := audit.ActionUrgent, "Обращение отмечено срочным"
+	if !urgent {
+		action, answer = audit.ActionNotUrgent, "Отметка срочности снята"
+	}
+
+	t.recordAppealEvent(ctx, appeal.ID, action, update.CallbackQuery.From.ID, nil)
+	t.renderCard(ctx, int64(update.CallbackQuery.Message.MessageID), appeal.ID)
+
+	return callbackAnswer{Text: answer}
+}
+
+// processSpamCallback закрытие обращения как спам
+func (t TelegramWebhookController) processSpamCallback(ctx context.Context, update tgbotapi.Update, data callback.Data) callbackAnswer {
+	appeal, err := t.callbackAppeal(ctx, data)
+	if err != nil {
+		return t.appealActionFailed(err)
+	}
+	if appeal.IsClosed() {
+		return callbackAnswer{Text: "Обращение уже закрыто"}
+	}
+
+	editorID := update.CallbackQuery.From.ID
+	if err = t.repo.MarkAppealSpam(ctx, appeal.UserID, editorID); err != nil {
+		return t.appealActionFailed(err)
+	}
+
+	t.recordAppealEvent(ctx, appeal.ID, audit.ActionSpam, editorID, nil)
+	t.renderCard(ctx, int64(update.CallbackQuery.Message.MessageID), appeal.ID)
+
+	return callbackAnswer{Text: "Обращение закрыто как спам"}
+}
+
+// processBanCallback блокировка пользователя, его обращение закрывается
+func (t TelegramWebhookController) processBanCallback(ctx context.Context, update tgbotapi.Update, data callback.Data) callbackAnswer {
+	appeal, err := t.callbackAppeal(ctx, data)
+	if err != nil {
+		return t.appealActionFailed(err)
+	}
+
+	messageID := int64(update.CallbackQuery.Message.MessageID)
+	err = t.banUser(ctx, appeal.UserID, update.CallbackQuery.From.ID, banButtonReason, time.Time{}, messageID)
+	if err != nil {
+		return t.appealActionFailed(err)
+	}
+
+	t.renderCard(ctx, messageID, appeal.ID)
+
+	return callbackAnswer{Text: "Пользователь заблокирован"}
+}
+
+// processReopenCallback переоткрытие закрытого обращения, карточка снова становится активной
+func (t TelegramWebhookController) processReopenCallback(ctx context.Context, update tgbotapi.Update, data callback.Data) callbackAnswer {
+	appeal, err := t.callbackAppeal(ctx, data)
+	if err != nil {
+		return t.appealActionFailed(err)
+	}
+	if !appeal.IsClosed() {
+		return callbackAnswer{Text: "Обращение не закрыто"}
+	}
+
+	messageID := int64(update.CallbackQuery.Message.MessageID)
+	err = t.repo.ReopenAppeal(ctx, appeal.ID, messageID)
+	if errors.Is(err, repo.ErrAppealAlreadyOpen) {
+		return callbackAnswer{Text: "У пользователя уже есть открытое обращение", Alert: true}
+	}
+	if err != nil {
+		return t.appealActionFailed(err)
+	}
+
+	t.recordAppealEvent(ctx, appeal.ID, audit.ActionReopen, update.CallbackQuery.From.ID, nil)
+	t.renderCard(ctx, messageID, appeal.ID)
+
+	return callbackAnswer{Text: "Обращение переоткрыто"}
+}
+
+// processHistoryCallback история обращений пользователя и действий по текущему обращению
+func (t TelegramWebhookController) processHistoryCallback(ctx context.Context, update tgbotapi.Update, data callback.Data) callbackAnswer {
+	appeal, err := t.callbackAppeal(ctx, data)
+	if err != nil {
+		return t.appealActionFailed(err)
+	}
+
+	appeals, err := t.repo.ListAppeals(ctx, appeal.UserID)
+	if err != nil {
+		return t.appealActionFailed(err)
+	}
+	records, err := t.audit.Trail(ctx, appeal.ID)
+	if err != nil {
+		return t.appealActionFailed(err)
+	}
+
+	t.audit.Record(ctx, update.CallbackQuery.From.ID, audit.ActionExport, appeal.ID, audit.Payload{"kind": "history"})
+
+	text := t.appealHistoryText(ctx, appeal, appeals, records)
+	_, err = t.bot.ReplyInAdminChat(int64(update.CallbackQuery.Message.MessageID), text)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return callbackAnswer{Text: "Не удалось показать историю", Alert: true}
+	}
+
+	return callbackAnswer{}
+}
+
+// appealHistoryText текст истории обращений пользователя и действий по текущему обращению
+func (t TelegramWebhookController) appealHistoryText(
+	ctx context.Context,
+	current *repo.Appeal,
+	appeals []repo.Appeal,
+	records []repo.AuditRecord,
+) string {
+	const dateLayout = "02.01.2006 15:04"
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Обращения — %s (всего %d):\n", t.sourceName(current.UserID), len(appeals))
+	for _, appeal := range appeals {
+		fmt.Fprintf(&b, "\n#%d — %s, открыто %s", appeal.ID, appealStatusNames[appeal.Status], appeal.OpenedAt.Format(dateLayout))
+		if appeal.ClosedAt.Valid {
+			fmt.Fprintf(&b, ", закрыто %s", appeal.ClosedAt.Time.Format(dateLayout))
+		}
+		if appeal.Urgent {
+			b.WriteString(" 🔥")
+		}
+	}
+
+	fmt.Fprintf(&b, "\n\nДействия по обращению #%d:\n", current.ID)
+	if len(records) == 0 {
+		b.WriteString("\nнет")
+	}
+	for _, record := range records {
+		fmt.Fprintf(&b, "\n%s %s — %s", record.CreatedAt.Format(dateLayout), record.Action, t.editorName(ctx, record.ActorID))
+	}
+
+	return b.String()
+}
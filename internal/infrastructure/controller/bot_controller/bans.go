@@ -0,0 +1,239 @@
+package bot_controller
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"medrussia_news_bot/internal/infrastructure/repo"
+	"medrussia_news_bot/internal/service/audit"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// bannedCardsLimit сколько последних карточек пользователя получают отметку о блокировке
+	bannedCardsLimit = 20
+	bannedCardMark   = "🚫 Пользователь заблокирован"
+	banButtonReason  = "кнопка в карточке"
+)
+
+// banUser блокирует пользователя до expiresAt (нулевое время - бессрочно), закрывает его обращение
+// и отмечает прошлые карточки. Карточку cardMessageID перерисовывает вызывающий
+func (t TelegramWebhookController) banUser(
+	ctx context.Context,
+	userID, editorID int64,
+	reason string,
+	expiresAt time.Time,
+	cardMessageID int64,
+) error {
+	err := t.repo.BanUser(ctx, repo.Ban{
+		UserID:    userID,
+		BannedBy:  editorID,
+		Reason:    nullString(reason),
+		ExpiresAt: sql.NullTime{Time: expiresAt, Valid: !expiresAt.IsZero()},
+	})
+	if err != nil {
+		return err
+	}
+
+	payload := audit.Payload{"user_id": userID, "reason": reason}
+	if !expiresAt.IsZero() {
+		payload["expires_at"] = expiresAt
+	}
+
+	appeal, err := t.repo.GetOpenAppeal(ctx, userID)
+	switch {
+	case err == nil:
+		if err = t.repo.CloseAppeal(ctx, userID, editorID); err != nil {
+			return err
+		}
+		t.recordAppealEvent(ctx, appeal.ID, audit.ActionBan, editorID, payload)
+	case errors.Is(err, repo.ErrAppealNotFound):
+		t.audit.Record(ctx, editorID, audit.ActionBan, 0, payload)
+	default:
+		return err
+	}
+
+	t.markBannedCards(ctx, userID, true, cardMessageID)
+
+	return nil
+}
+
+// unbanUser снимает блокировку пользователя и отметки с его карточек
+func (t TelegramWebhookController) unbanUser(ctx context.Context, userID, editorID int64) error {
+	err := t.repo.UnbanUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	t.audit.Record(ctx, editorID, audit.ActionUnban, 0, audit.Payload{"user_id": userID})
+	t.markBannedCards(ctx, userID, false, 0)
+
+	return nil
+}
+
+// markBannedCards ставит или снимает отметку о блокировке на последних карточках пользователя.
+// Последняя карточка сохраняет клавиатуру действий и перерисовывается целиком
+func (t TelegramWebhookController) markBannedCards(ctx context.Context, userID int64, banned bool, skipMessageID int64) {
+	user, err := t.repo.GetUser(ctx, userID)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return
+	}
+
+	cardIDs, err := t.repo.ListUserCardMessageIDs(ctx, userID, bannedCardsLimit)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return
+	}
+
+	activeCardID := user.LastUserMessageID.Int64
+	for _, cardID := range cardIDs {
+		if cardID == activeCardID || cardID == skipMessageID {
+			continue
+		}
+
+		if banned {
+			_, err = t.bot.MarkCardInAdminChat(cardID, bannedCardMark)
+		} else {
+			_, err = t.bot.CleanMessageButtonsInAdminChat(cardID)
+		}
+		if err != nil {
+			// старые карточки могли быть удалены из чата
+			t.logger.Warn(fmt.Sprintf("Не удалось изменить отметку блокировки на карточке %d: %s", cardID, err))
+		}
+	}
+
+	if activeCardID == 0 || activeCardID == skipMessageID {
+		return
+	}
+
+	appeals, err := t.repo.ListAppeals(ctx, userID)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return
+	}
+	if len(appeals) > 0 {
+		t.renderCard(ctx, activeCardID, appeals[0].ID)
+	}
+}
+
+// refuseBannedUser вежливый отказ заблокированному пользователю, отправляется один раз за блокировку
+func (t TelegramWebhookController) refuseBannedUser(ctx context.Context, chatID int64, ban *repo.Ban) {
+	if ban.NotifiedAt.Valid {
+		return
+	}
+
+	text := "К сожалению, редакция больше не принимает от вас сообщения."
+	if ban.ExpiresAt.Valid {
+		text = fmt.Sprintf(
+			"К сожалению, редакция не принимает от вас сообщения до %s. Пожалуйста, напишите нам после этого срока.",
+			ban.ExpiresAt.Time.Format("02.01.2006 15:04"),
+		)
+	}
+
+	t.bot.SendMessage(tgbotapi.NewMessage(chatID, text))
+
+	err := t.repo.MarkBanNotified(ctx, ban.UserID)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+	}
+}
+
+// banCommandTarget пользователь из команды: ответ на карточку или telegram ID первым аргументом
+func (t TelegramWebhookController) banCommandTarget(ctx context.Context, update tgbotapi.Update, args []string) (int64, []string, error) {
+	if replyTo := update.Message.ReplyToMessage; replyTo != nil {
+		link := t.resolveReplyLink(ctx, replyTo)
+		if link == nil {
+			return 0, nil, repo.ErrAppealNotFound
+		}
+		return link.UserID, args, nil
+	}
+
+	if len(args) == 0 {
+		return 0, nil, repo.ErrAppealNotFound
+	}
+	userID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return 0, nil, repo.ErrAppealNotFound
+	}
+
+	return userID, args[1:], nil
+}
+
+// parseBanDuration срок блокировки: 30m, 12h, 7d
+func parseBanDuration(arg string) (time.Duration, bool) {
+	if days, ok := strings.CutSuffix(arg, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, false
+		}
+		return time.Duration(n) * 24 * time.Hour, true
+	}
+
+	duration, err := time.ParseDuration(arg)
+	if err != nil || duration <= 0 {
+		return 0, false
+	}
+
+	return duration, true
+}
+
+// processBanCommand /ban [срок] [причина] ответом на карточку или /ban <telegram ID> [срок] [причина]
+func (t TelegramWebhookController) processBanCommand(ctx context.Context, update tgbotapi.Update) {
+	userID, args, err := t.banCommandTarget(ctx, update, strings.Fields(update.Message.CommandArguments()))
+	if err != nil {
+		t.replyToAdmin(update, "Отправьте /ban [срок] [причина] ответом на карточку или /ban <telegram ID> [срок] [причина], срок: 30m, 12h, 7d")
+		return
+	}
+
+	var expiresAt time.Time
+	if len(args) > 0 {
+		if duration, ok := parseBanDuration(args[0]); ok {
+			expiresAt = time.Now().Add(duration)
+			args = args[1:]
+		}
+	}
+	reason := strings.Join(args, " ")
+
+	if err = t.banUser(ctx, userID, update.Message.From.ID, reason, expiresAt, 0); err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		t.replyToAdmin(update, "Ошибка при блокировке пользователя")
+		return
+	}
+
+	text := fmt.Sprintf("%s заблокирован бессрочно", upperFirst(t.sourceName(userID)))
+	if !expiresAt.IsZero() {
+		text = fmt.Sprintf("%s заблокирован до %s", upperFirst(t.sourceName(userID)), expiresAt.Format("02.01.2006 15:04"))
+	}
+	if reason != "" {
+		text += "\nПричина: " + reason
+	}
+	t.replyToAdmin(update, text)
+}
+
+// processUnbanCommand /unban ответом на карточку или /unban <telegram ID>
+func (t TelegramWebhookController) processUnbanCommand(ctx context.Context, update tgbotapi.Update) {
+	userID, _, err := t.banCommandTarget(ctx, update, strings.Fields(update.Message.CommandArguments()))
+	if err != nil {
+		t.replyToAdmin(update, "Отправьте /unban ответом на карточку или /unban <telegram ID>")
+		return
+	}
+
+	err = t.unbanUser(ctx, userID, update.Message.From.ID)
+	if err != nil {
+		if errors.Is(err, repo.ErrBanNotFound) {
+			t.replyToAdmin(update, "Пользователь не заблокирован")
+			return
+		}
+		t.logger.Error(fmt.Sprintf("%s", err))
+		t.replyToAdmin(update, "Ошибка при снятии блокировки")
+		return
+	}
+
+	t.replyToAdmin(update, fmt.Sprintf("Блокировка снята: %s", t.sourceName(userID)))
+}
@@ -3,16 +3,28 @@ package bot_controller
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"medrussia_news_bot/internal/config"
 	"medrussia_news_bot/internal/infrastructure/controller/bot_controller/dto"
 	"medrussia_news_bot/internal/infrastructure/repo"
+	"medrussia_news_bot/internal/pkg/callback"
+	"medrussia_news_bot/internal/pkg/filestore"
+	"medrussia_news_bot/internal/pkg/ratelimit"
 	"medrussia_news_bot/internal/pkg/telegram"
+	"medrussia_news_bot/internal/service/access"
+	"medrussia_news_bot/internal/service/alert"
+	"medrussia_news_bot/internal/service/anonymity"
+	"medrussia_news_bot/internal/service/audit"
+	"medrussia_news_bot/internal/service/evidence"
+	"medrussia_news_bot/internal/service/sanitize"
+	"medrussia_news_bot/internal/service/spam"
+	"medrussia_news_bot/internal/service/triage"
 	"net/http"
 	"strconv"
-	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -24,10 +36,62 @@ type Repo interface {
 	UpdateLastAdminMessage(ctx context.Context, userID, messageID int64) error
 	UpdateLastUserMessage(ctx context.Context, userID, messageID int64) error
 	TurnOnAvailable(ctx context.Context, userID int64) error
-	CloseAppeal(ctx context.Context, userID int64) error
+	CloseAppeal(ctx context.Context, userID, closedBy int64) error
+	OpenAppeal(ctx context.Context, userID int64) (*repo.Appeal, error)
+	GetOpenAppeal(ctx context.Context, userID int64) (*repo.Appeal, error)
+	ListAppeals(ctx context.Context, userID int64) ([]repo.Appeal, error)
+	GetAppeal(ctx context.Context, appealID int64) (*repo.Appeal, error)
+	AssignAppeal(ctx context.Context, appealID, editorID int64) error
+	SetAppealUrgent(ctx context.Context, appealID int64, urgent bool) error
+	MarkAppealSpam(ctx context.Context, userID, closedBy int64) error
+	ReopenAppeal(ctx context.Context, appealID, cardMessageID int64) error
+	BanUser(ctx context.Context, ban repo.Ban) error
+	UnbanUser(ctx context.Context, userID int64) error
+	GetActiveBan(ctx context.Context, userID int64) (*repo.Ban, error)
+	MarkBanNotified(ctx context.Context, userID int64) error
+	IsUserBanned(ctx context.Context, userID int64) (bool, error)
+	SaveEditor(ctx context.Context, editor repo.Editor) error
+	GetEditor(ctx context.Context, tgID int64) (*repo.Editor, error)
+	GetEditorByUsername(ctx context.Context, username string) (*repo.Editor, error)
+	ListEditorAppeals(ctx context.Context, editorID int64) ([]repo.Appeal, error)
+	ListEditorsWorkload(ctx context.Context) ([]repo.EditorWorkload, error)
+	SaveMessage(ctx context.Context, message repo.Message) (int64, error)
+	ListUserCardMessageIDs(ctx context.Context, userID int64, limit int) ([]int64, error)
+	SaveMessageLink(ctx context.Context, link repo.MessageLink) error
+	GetMessageLink(ctx context.Context, adminChatMessageID int64) (*repo.MessageLink, error)
+	GetMessageLinkByUserMessage(ctx context.Context, userID, userMessageID int64, direction string) (*repo.MessageLink, error)
+	GetMessageByUserChatMessage(ctx context.Context, userID, userChatMessageID int64, direction string) (*repo.Message, error)
+	SaveMessageEdit(ctx context.Context, edit repo.MessageEdit) error
+	GetMessageByAdminChatMessage(ctx context.Context, adminChatMessageID int64, direction string) (*repo.Message, error)
+	MarkMessageLinkRecalled(ctx context.Context, adminChatMessageID int64) error
+	AddQuarantineItem(ctx context.Context, item repo.QuarantineItem) (int64, error)
+	GetQuarantineItem(ctx context.Context, id int64) (*repo.QuarantineItem, error)
+	ListPendingQuarantine(ctx context.Context, limit int) ([]repo.QuarantineItem, error)
+	CountPendingQuarantine(ctx context.Context) (int64, error)
+	ReviewQuarantineItem(ctx context.Context, id, reviewedBy int64, released bool) error
+	ListCategories(ctx context.Context) ([]repo.Category, error)
+	GetCategory(ctx context.Context, categoryID int64) (*repo.Category, error)
+	GetCategoryByCode(ctx context.Context, code string) (*repo.Category, error)
+	SetAppealCategory(ctx context.Context, appealID, categoryID int64) error
+	SetPendingCategory(ctx context.Context, userID, categoryID int64) error
+	ApplyPendingCategory(ctx context.Context, userID, appealID int64) (bool, error)
+	ListCategoryAppeals(ctx context.Context, categoryID int64, onlyOpen bool, limit int) ([]repo.Appeal, error)
+	CategoryStats(ctx context.Context, since time.Time) ([]repo.CategoryStat, error)
+	SetAppealRegion(ctx context.Context, appealID, regionCode int64) error
+	SetPendingRegion(ctx context.Context, userID, regionCode int64) error
+	ApplyPendingRegion(ctx context.Context, userID, appealID int64) (bool, error)
+	ListRegionAppeals(ctx context.Context, regionCode int64, onlyOpen bool, limit int) ([]repo.Appeal, error)
+	RegionStats(ctx context.Context, since time.Time) ([]repo.RegionStat, error)
+	GetMessage(ctx context.Context, id int64) (*repo.Message, error)
+	ListAppealFiles(ctx context.Context, appealID int64) ([]repo.Message, error)
+	GetMediaOriginal(ctx context.Context, id int64) (*repo.MediaOriginal, error)
+	GetMediaOriginalByMessage(ctx context.Context, adminChatMessageID int64) (*repo.MediaOriginal, error)
+	ClaimUpdate(ctx context.Context, updateID int64) (bool, error)
+	ReleaseUpdate(ctx context.Context, updateID int64) error
 }
 
 const (
+	waitMessage  = "Пожалуйста ожидайте, вам скоро ответят"
 	startMessage = `
 Здравствуйте!
 
@@ -51,10 +115,22 @@ const (
 
 // TelegramWebhookController контроллер бота
 type TelegramWebhookController struct {
-	cfg    *config.Config
-	logger *slog.Logger
-	bot    *telegram.Bot
-	repo   Repo
+	cfg       *config.Config
+	logger    *slog.Logger
+	bot       *telegram.Bot
+	repo      Repo
+	albums    *albumBuffer
+	callbacks *callbackRouter
+	audit     *audit.Service
+	access    *access.Service
+	alerts    *alert.Service
+	limiter   *ratelimit.Limiter
+	spam      *spam.Filter
+	triage    *triage.Matcher
+	anonymity *anonymity.Service
+	sanitizer *sanitize.Service
+	evidence  *evidence.Service
+	files     filestore.FileStore
 }
 
 // NewTelegramWebhookController конструктор
@@ -63,13 +139,37 @@ func NewTelegramWebhookController(
 	logger *slog.Logger,
 	bot *telegram.Bot,
 	repo Repo,
+	auditService *audit.Service,
+	accessService *access.Service,
+	alertService *alert.Service,
+	spamFilter *spam.Filter,
+	triageMatcher *triage.Matcher,
+	anonymityService *anonymity.Service,
+	sanitizer *sanitize.Service,
+	evidenceService *evidence.Service,
+	files filestore.FileStore,
 ) TelegramWebhookController {
-	return TelegramWebhookController{
-		cfg:    cfg,
-		logger: logger,
-		bot:    bot,
-		repo:   repo,
-	}
+	t := TelegramWebhookController{
+		cfg:       cfg,
+		logger:    logger,
+		bot:       bot,
+		repo:      repo,
+		albums:    newAlbumBuffer(albumWindow),
+		callbacks: newCallbackRouter(),
+		audit:     auditService,
+		access:    accessService,
+		alerts:    alertService,
+		limiter:   ratelimit.NewLimiter(cfg.Bot.RateLimit),
+		spam:      spamFilter,
+		triage:    triageMatcher,
+		anonymity: anonymityService,
+		sanitizer: sanitizer,
+		evidence:  evidenceService,
+		files:     files,
+	}
+	t.registerCallbacks()
+
+	return t
 }
 
 // BotWebhookHandler хендлер реагирующий на все вебхуки бота
@@ -83,35 +183,60 @@ func (t TelegramWebhookController) BotWebhookHandler(c *gin.Context) {
 
 	var update tgbotapi.Update
 	if err := c.ShouldBindJSON(&update); err != nil {
-		t.logger.Error("Error binding JSON", err)
+		t.logger.Error(fmt.Sprintf("Error binding JSON: %s", err))
 		c.AbortWithStatus(http.StatusInternalServerError)
 		return
 	}
 
+	// файл скачивается, очищается и сохраняется в архив до ответа, и Telegram может не дождаться
+	// ответа и прислать тот же update снова. Повтор подтверждается без обработки, чтобы
+	// редакция не получила обращение дважды
+	if !t.claimUpdate(update.UpdateID) {
+		c.JSON(http.StatusOK, gin.H{"status": "duplicate"})
+		return
+	}
+	defer t.releaseFailedUpdate(c, update.UpdateID)
+
 	tgUser := t.getUserFromWebhook(update)
 	tgMessage := t.getMessageFromWebhook(update)
 
 	// Сначала проверяем на команду, потом на текстовое сообщение, потом callback
 	if update.Message != nil {
 		ctx := context.WithValue(context.Background(), "userID", update.Message.From.ID)
+		// Если чат админский
+		if t.isAdminChat(update.Message.Chat.ID) {
+			t.rememberEditor(ctx, update.Message.From)
+			if update.Message.IsCommand() {
+				t.ForkAdminCommands(ctx, update)
+			} else {
+				t.ForkAdminMessage(ctx, update)
+			}
+			return
+		}
+
 		if update.Message.IsCommand() {
 			t.ForkCommands(ctx, update, tgUser, tgMessage)
 		} else {
-			// Если чат админский
-			if strconv.FormatInt(update.Message.Chat.ID, 10) == t.cfg.Bot.AdminChatID {
-				t.ForkAdminMessage(ctx, update)
-				return
+			// части альбома приходят отдельными вебхуками, собираем их в одно обращение
+			if update.Message.MediaGroupID != "" {
+				t.albums.add(update, func(updates []tgbotapi.Update) {
+					t.ForkAlbum(ctx, updates)
+				})
+			} else {
+				t.ForkMessages(ctx, update, tgUser, tgMessage)
 			}
-			t.ForkMessages(ctx, update, tgUser, tgMessage)
 		}
 	} else if update.CallbackQuery != nil {
 		ctx := context.WithValue(context.Background(), "userID", update.CallbackQuery.From.ID)
+		if update.CallbackQuery.Message != nil && t.isAdminChat(update.CallbackQuery.Message.Chat.ID) {
+			t.rememberEditor(ctx, update.CallbackQuery.From)
+		}
 		t.ForkCallbacks(ctx, update)
 	} else if update.EditedMessage != nil {
 		ctx := context.WithValue(context.Background(), "userID", update.EditedMessage.From.ID)
 		t.ForkEditMessage(ctx, update)
 	} else {
-		t.logger.Warn(fmt.Sprintf("Unhandled update type: %+v", update))
+		t.logger.Warn(fmt.Sprintf("Unhandled update type: update_id=%d", update.UpdateID))
 		c.AbortWithStatus(http.StatusInternalServerError)
 		return
 	}
@@ -120,6 +245,42 @@ func (t TelegramWebhookController) BotWebhookHandler(c *gin.Context) {
 	return
 }
 
+// claimUpdate update пришел впервые. Если базу проверить не удалось, update обрабатывается:
+// лучше повтор, чем потерянное обращение
+func (t TelegramWebhookController) claimUpdate(updateID int) bool {
+	first, err := t.repo.ClaimUpdate(context.Background(), int64(updateID))
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return true
+	}
+	if !first {
+		t.logger.Warn(fmt.Sprintf("Повторный вебхук update_id=%d пропущен", updateID))
+	}
+
+	return first
+}
+
+// releaseFailedUpdate снимает update, если обработка упала с паникой или ответом 5xx:
+// Telegram повторит его, и повтор не должен быть пропущен как дубликат
+func (t TelegramWebhookController) releaseFailedUpdate(c *gin.Context, updateID int) {
+	r := recover()
+	if r == nil && c.Writer.Status() < http.StatusInternalServerError {
+		return
+	}
+
+	if err := t.repo.ReleaseUpdate(context.Background(), int64(updateID)); err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+	}
+	if r != nil {
+		panic(r)
+	}
+}
+
+// isAdminChat сообщение пришло из чата админов
+func (t TelegramWebhookController) isAdminChat(chatID int64) bool {
+	return strconv.FormatInt(chatID, 10) == t.cfg.Bot.AdminChatID
+}
+
 // ForkCommands обработка всех сообщений типа Command
 func (t TelegramWebhookController) ForkCommands(ctx context.Context, update tgbotapi.Update, tgUser dto.TgUserDTO, tgMessage dto.MessageDTO) {
 
@@ -130,28 +291,87 @@ func (t TelegramWebhookController) ForkCommands(ctx context.Context, update tgbo
 			t.logger.Error(fmt.Sprintf("%s", err))
 			return
 		}
-		t.bot.SendMessage(tgbotapi.NewMessage(update.Message.Chat.ID, startMessage))
+		t.offerCategories(ctx, update.Message.Chat.ID, startMessage)
 	}
 }
 
 // ForkMessages обработка всех сообщений типа MESSAGE
 func (t TelegramWebhookController) ForkMessages(ctx context.Context, update tgbotapi.Update, tgUser dto.TgUserDTO, tgMessage dto.MessageDTO) {
+	if !t.acceptUserMessage(ctx, update) {
+		return
+	}
+	// подозрительное сообщение ждет решения редактора в карантине
+	if t.quarantineSpam(ctx, update) {
+		return
+	}
+
+	user, appeal, ok := t.openDialog(ctx, update)
+	if !ok {
+		return
+	}
+	// по геопозиции предлагаем пользователю регион, сама геопозиция тоже уходит редакции
+	t.processUserLocation(update, appeal)
+	// шлем админам
+	t.forwardToAdmin(ctx, user, appeal, update, tgMessage)
+}
+
+// acceptUserMessage сообщение не превышает лимит и пользователь не заблокирован
+func (t TelegramWebhookController) acceptUserMessage(ctx context.Context, update tgbotapi.Update) bool {
+	// флуд отсекаем до любых запросов в базу
+	if !t.allowUserMessage(ctx, update) {
+		return false
+	}
+
+	ban, err := t.repo.GetActiveBan(ctx, update.Message.From.ID)
+	if err != nil && !errors.Is(err, repo.ErrBanNotFound) {
+		t.logger.Error(fmt.Sprintf("%s", err))
+	}
+	if ban != nil {
+		t.refuseBannedUser(ctx, update.Message.Chat.ID, ban)
+		return false
+	}
+
+	return true
+}
+
+// openDialog получение пользователя и его обращения, если предыдущее обращение закрыто - открывается новое
+func (t TelegramWebhookController) openDialog(ctx context.Context, update tgbotapi.Update) (*repo.UserDialog, *repo.Appeal, bool) {
 	user, err := t.repo.GetUser(ctx, update.Message.From.ID)
 	if err != nil {
 		t.logger.Error(fmt.Sprintf("%s", err))
 
 		_ = t.repo.CreateUser(ctx, update.Message.From.ID)
 		user, err = t.repo.GetUser(ctx, update.Message.From.ID)
+		if err != nil {
+			t.logger.Error(fmt.Sprintf("%s", err))
+			return nil, nil, false
+		}
 	}
 
+	appeal, err := t.repo.OpenAppeal(ctx, user.UserID)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return nil, nil, false
+	}
+
+	appeal = t.applyPendingCategory(ctx, appeal)
+	appeal = t.applyPendingRegion(ctx, appeal)
+
 	if !user.Available {
-		// отвечаем пользователю
-		t.bot.SendMessage(tgbotapi.NewMessage(update.Message.Chat.ID, "Пожалуйста ожидайте, вам скоро ответят"))
+		// отвечаем пользователю, если тема или регион еще не выбраны - предлагаем их выбрать
+		switch {
+		case !appeal.CategoryID.Valid:
+			t.offerCategories(ctx, update.Message.Chat.ID, waitMessage+"\n\n"+chooseCategoryMessage)
+		case !appeal.RegionCode.Valid && update.Message.Location == nil:
+			t.offerRegions(update.Message.Chat.ID, waitMessage+"\n\n"+chooseRegionMessage)
+		default:
+			t.bot.SendMessage(tgbotapi.NewMessage(update.Message.Chat.ID, waitMessage))
+		}
 	}
 	// ставим флаг активности диалога
 	t.setActiveUserFlag(ctx, user)
-	// шлем админам
-	t.forwardToAdmin(ctx, user, update)
+
+	return user, appeal, true
 }
 
 // ForkAdminMessage пересылка пользователю сообщение админа
@@ -160,25 +380,76 @@ func (t TelegramWebhookController) ForkAdminMessage(ctx context.Context, update
 	if replyTo == nil {
 		return
 	}
-	markup := replyTo.ReplyMarkup
-	if markup == nil {
+
+	link := t.resolveReplyLink(ctx, replyTo)
+	// пользователю уходят только ответы на его сообщения. Ответ на реплику коллеги,
+	// уже отправленную пользователю, - это обсуждение внутри редакции
+	if link == nil || link.Direction != repo.DirectionUserToAdmin {
 		return
 	}
 
-	userID := t.parseReplyCloseKeyboard(update.Message.ReplyToMessage)
-	if userID == 0 {
+	if !t.access.Can(ctx, update.Message.From.ID, access.PermissionReply) {
+		_, _ = t.bot.ReplyInAdminChat(int64(update.Message.MessageID), permissionDeniedMessage+", сообщение не отправлено пользователю")
 		return
 	}
 
-	user, err := t.repo.GetUser(ctx, userID)
+	user, err := t.repo.GetUser(ctx, link.UserID)
 	if err != nil {
 		t.logger.Error(fmt.Sprintf("%s", err))
 		return
 	}
 
-	forwardMessageID := t.bot.ForwardAdminMessageToUser(user.UserID, update.Message)
+	sentMessageID, err := t.bot.ForwardAdminMessageToUser(user.UserID, link.UserMessageID, update.Message)
+	if err != nil {
+		t.reportAdminReplyError(update.Message, err)
+		return
+	}
+
+	t.saveAdminMessageIDToUser(ctx, user, int64(update.Message.MessageID))
+
+	appealID := link.AppealID.Int64
+	appeal, err := t.repo.GetOpenAppeal(ctx, user.UserID)
+	if err == nil {
+		appealID = appeal.ID
+	} else if !errors.Is(err, repo.ErrAppealNotFound) {
+		t.logger.Error(fmt.Sprintf("%s", err))
+	}
 
-	t.saveAdminMessageIDToUser(ctx, user, forwardMessageID)
+	t.saveMessageLink(ctx, repo.MessageLink{
+		AdminChatMessageID: int64(update.Message.MessageID),
+		UserID:             user.UserID,
+		UserMessageID:      sentMessageID,
+		AppealID:           nullInt64(appealID),
+		Direction:          repo.DirectionAdminToUser,
+	})
+	t.audit.Record(ctx, update.Message.From.ID, audit.ActionReply, appealID, audit.Payload{
+		"user_id":          user.UserID,
+		"admin_message_id": update.Message.MessageID,
+		"user_message_id":  sentMessageID,
+		"kind":             telegram.MessageKindName(update.Message),
+	})
+
+	message := newLogMessage(repo.DirectionAdminToUser, appealID, user.UserID, update.Message.From.ID, t.getMessageFromWebhook(update))
+	message.UserChatMessageID = nullInt64(sentMessageID)
+	message.AdminChatMessageID = nullInt64(int64(update.Message.MessageID))
+	t.saveLogMessage(ctx, message)
+}
+
+// reportAdminReplyError сообщение в чат админов о том, что ответ не дошел до пользователя
+func (t TelegramWebhookController) reportAdminReplyError(msg *tgbotapi.Message, err error) {
+	text := "Ошибка при отправке сообщения"
+	if errors.Is(err, telegram.ErrUnsupportedMessageKind) {
+		text = fmt.Sprintf(
+			"Ответ не отправлен: пользователю нельзя переслать %s. "+
+				"Поддерживаются текст, фото, видео, GIF, документы, аудио и голосовые сообщения",
+			telegram.MessageKindName(msg),
+		)
+	}
+
+	_, replyErr := t.bot.ReplyInAdminChat(int64(msg.MessageID), text)
+	if replyErr != nil {
+		t.logger.Error(fmt.Sprintf("%s", replyErr))
+	}
 }
 
 // saveAdminMessageIDToUser сохранение последнего сообщения админа пользователю
@@ -199,8 +470,8 @@ func (t TelegramWebhookController) saveUserMessageID(ctx context.Context, user *
 	}
 }
 
-// parseReplyCloseKeyboard парсинг клавиатуры при ответе админа пользователю
-func (t TelegramWebhookController) parseReplyCloseKeyboard(replyToMessage *tgbotapi.Message) (userID int64) {
+// parseReplyCloseKeyboard данные кнопки закрытия обращения из клавиатуры карточки
+func (t TelegramWebhookController) parseReplyCloseKeyboard(replyToMessage *tgbotapi.Message) (callback.Data, bool) {
 	for _, line := range replyToMessage.ReplyMarkup.InlineKeyboard {
 		for _, button := range line {
 			var callbackData string
@@ -209,18 +480,14 @@ func (t TelegramWebhookController) parseReplyCloseKeyboard(replyToMessage *tgbot
 				callbackData = *button.CallbackData
 			}
 
-			if strings.Contains(callbackData, "close") {
-				return t.parseCloseCallback(callbackData)
+			data, err := callback.Decode(callbackData)
+			if err == nil && data.Action == callback.ActionClose {
+				return data, true
 			}
 		}
 	}
 
-	return userID
-}
-
-// ForkCallbacks Обработка колбека сообщения
-func (t TelegramWebhookController) ForkCallbacks(ctx context.Context, update tgbotapi.Update) {
-	t.processCloseCallback(ctx, update)
+	return callback.Data{}, false
 }
 
 // getUserFromWebhook получение пользователя из вебхука
@@ -236,7 +503,7 @@ func (t TelegramWebhookController) getUserFromWebhook(update tgbotapi.Update) dt
 	} else if update.EditedMessage != nil {
 		userJSON, err = json.Marshal(update.EditedMessage.From)
 	} else {
-		t.logger.Error("Cannot get user from webhook - no valid user data found", update)
+		t.logger.Error(fmt.Sprintf("Cannot get user from webhook - no valid user data found: update_id=%d", update.UpdateID))
 		return dto.TgUserDTO{}
 	}
 
@@ -267,17 +534,59 @@ func (t TelegramWebhookController) setActiveUserFlag(ctx context.Context, user *
 }
 
 // forwardToAdmin пересылаем админам и ставим пользователю id сообщения в админ чате
-func (t TelegramWebhookController) forwardToAdmin(ctx context.Context, user *repo.UserDialog, update tgbotapi.Update) {
-	text := fmt.Sprintf(
-		"Пользователь: @%s\nИмя: %s %s\n\nТекст сообщения: %s",
-		update.Message.Chat.UserName, update.Message.Chat.LastName, update.Message.Chat.FirstName, update.Message.Text,
-	)
-	forwardMessageID, err := t.bot.ForwardMessageToAdminChat(user.LastAdminMessageID.Int64, update.Message.Chat.ID, text)
-	if err != nil {
-		t.logger.Error(fmt.Sprintf("%s", err))
-		return
+func (t TelegramWebhookController) forwardToAdmin(
+	ctx context.Context,
+	user *repo.UserDialog,
+	appeal *repo.Appeal,
+	update tgbotapi.Update,
+	tgMessage dto.MessageDTO,
+) {
+	header := t.cardHeader(ctx, update.Message.Chat)
+	marker, escalated := t.triageAppeal(ctx, appeal, update.Message.Text+update.Message.Caption)
+	card := t.cardState(ctx, appeal)
+
+	var adminMessageIDs []int64
+	var received receivedFile
+	if media, ok := tgMessage.Media(); ok {
+		var err error
+		received = t.receiveFile(ctx, appeal.ID, user.UserID, tgMessage, media)
+		heading := cardHeading(marker, header, received.note())
+		adminMessageIDs, err = t.forwardMediaToAdmin(ctx, user, card, heading, tgMessage, media, received)
+		// шапка могла уйти в чат, даже если само вложение не отправилось
+		t.saveMessageLinks(ctx, adminMessageIDs, user.UserID, int64(update.Message.MessageID), appeal.ID)
+		if err != nil {
+			t.alerts.Critical("forward", fmt.Sprintf("Не удалось переслать обращение #%d в чат админов: %s", appeal.ID, err))
+			return
+		}
+	} else {
+		text := fmt.Sprintf("%s\n\nТекст сообщения: %s", cardHeading(marker, header, ""), update.Message.Text)
+		forwardMessageID, err := t.bot.ForwardMessageToAdminChat(user.LastAdminMessageID.Int64, card, text)
+		if err != nil {
+			t.alerts.Critical("forward", fmt.Sprintf("Не удалось переслать обращение #%d в чат админов: %s", appeal.ID, err))
+			return
+		}
+		adminMessageIDs = []int64{forwardMessageID}
+		t.saveMessageLinks(ctx, adminMessageIDs, user.UserID, int64(update.Message.MessageID), appeal.ID)
 	}
-	err = t.repo.UpdateLastUserMessage(ctx, user.UserID, forwardMessageID)
+
+	forwardMessageID := adminMessageIDs[len(adminMessageIDs)-1]
+	t.saveAdminCard(ctx, user, forwardMessageID)
+	if escalated {
+		t.escalateCard(ctx, appeal, forwardMessageID)
+	}
+
+	message := newLogMessage(repo.DirectionUserToAdmin, appeal.ID, user.UserID, update.Message.From.ID, tgMessage)
+	message.UserChatMessageID = nullInt64(int64(update.Message.MessageID))
+	message.AdminChatMessageID = nullInt64(forwardMessageID)
+	message.StorageKey = nullString(received.storageKey)
+	message.FileName = nullString(received.fileName)
+	message.CardNote = nullString(received.note())
+	t.saveLogMessage(ctx, message)
+}
+
+// saveAdminCard запоминаем новую карточку обращения и убираем кнопки с предыдущей
+func (t TelegramWebhookController) saveAdminCard(ctx context.Context, user *repo.UserDialog, forwardMessageID int64) {
+	err := t.repo.UpdateLastUserMessage(ctx, user.UserID, forwardMessageID)
 	if err != nil {
 		t.logger.Error(fmt.Sprintf("%s", err))
 		return
@@ -300,9 +609,9 @@ func (t TelegramWebhookController) getMessageFromWebhook(update tgbotapi.Update)
 	} else if update.Message != nil {
 		userJSON, err = json.Marshal(update.Message)
 	} else if update.EditedMessage != nil {
-		userJSON, err = json.Marshal(update.EditedMessage.From)
+		userJSON, err = json.Marshal(update.EditedMessage)
 	} else {
-		t.logger.Error("Cannot get user from webhook - no valid user data found", update)
+		t.logger.Error(fmt.Sprintf("Cannot get user from webhook - no valid user data found: update_id=%d", update.UpdateID))
 		return dto.MessageDTO{}
 	}
 
@@ -318,91 +627,3 @@ func (t TelegramWebhookController) getMessageFromWebhook(update tgbotapi.Update)
 
 	return tgMessage
 }
-
-// parseCloseCallback парсинг колбека о закрытии обращения в админке
-func (t TelegramWebhookController) parseCloseCallback(callbackData string) int64 {
-	data := strings.Split(callbackData, "_")
-	if len(data) < 2 {
-		return 0
-	}
-
-	userIDFromCallbackData, err := strconv.Atoi(data[1])
-	userID := int64(userIDFromCallbackData)
-
-	if err != nil {
-		t.logger.Error("Ошибка при парсинге callback, не найден UserID" + err.Error())
-	}
-
-	return userID
-}
-
-// processCloseCallback обработка сallback закрытия обращения пользователя со стороны админа
-func (t TelegramWebhookController) processCloseCallback(ctx context.Context, update tgbotapi.Update) {
-	userID := t.parseCloseCallback(update.CallbackData())
-	if userID == 0 {
-		return
-	}
-
-	messageID := update.CallbackQuery.Message.MessageID
-
-	updatedMessageID, err := t.bot.SetCloseButtonInAdminChat(int64(messageID))
-	if err != nil {
-		if err.Error() == "message to edit not found" {
-			t.logger.Warn(err.Error())
-			return
-		}
-		t.notifyAdmin(
-			fmt.Sprintf(
-				"Ошибка при закрытии обращения (изменение сообщения messageID: %d) %v",
-				updatedMessageID,
-				err.Error(),
-			),
-		)
-
-		return
-	}
-
-	err = t.repo.CloseAppeal(ctx, userID)
-	if err != nil {
-		t.logger.Error("Ошибка при закрытии обращения пользователя: " + err.Error())
-	}
-}
-
-func (t TelegramWebhookController) ForkEditMessage(ctx context.Context, update tgbotapi.Update) {
-	userID := update.EditedMessage.From.ID
-	if userID == 0 {
-		return
-	}
-
-	messageID := update.EditedMessage.MessageID
-
-	updatedMessageID, err := t.bot.SetCloseButtonInAdminChat(int64(messageID))
-	if err != nil {
-		if err.Error() == "message to edit not found" {
-			t.logger.Warn(err.Error())
-			return
-		}
-		t.notifyAdmin(
-			fmt.Sprintf(
-				"Ошибка при закрытии обращения (изменение сообщения messageID: %d) %v",
-				updatedMessageID,
-				err.Error(),
-			),
-		)
-
-		return
-	}
-
-	err = t.repo.CloseAppeal(ctx, userID)
-	if err != nil {
-		t.logger.Error("Ошибка при закрытии обращения пользователя: " + err.Error())
-	}
-}
-
-// notifyAdmin оповещение админа о чем-то
-func (t TelegramWebhookController) notifyAdmin(message string) {
-	_, err := t.bot.SendMessageToSuperAdmin(243807051, message)
-	if err != nil {
-		t.logger.Error(err.Error())
-	}
-}
@@ -0,0 +1,123 @@
+package bot_controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"medrussia_news_bot/internal/pkg/callback"
+	"medrussia_news_bot/internal/service/access"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// callbackAnswer всплывающий ответ на нажатие кнопки
+type callbackAnswer struct {
+	Text  string
+	Alert bool
+}
+
+// callbackHandler обработчик действия inline кнопки
+type callbackHandler func(ctx context.Context, update tgbotapi.Update, data callback.Data) callbackAnswer
+
+// callbackRoute обработчик действия и право, необходимое для его выполнения.
+// Публичные действия доступны пользователям в личном чате и проверяются обработчиком
+type callbackRoute struct {
+	permission access.Permission
+	public     bool
+	handler    callbackHandler
+}
+
+// callbackRouter реестр действий inline кнопок
+type callbackRouter struct {
+	routes map[string]callbackRoute
+}
+
+func newCallbackRouter() *callbackRouter {
+	return &callbackRouter{routes: make(map[string]callbackRoute)}
+}
+
+// register регистрирует обработчик действия
+func (r *callbackRouter) register(action string, permission access.Permission, handler callbackHandler) {
+	if _, ok := r.routes[action]; ok {
+		panic(fmt.Sprintf("callback action %q already registered", action))
+	}
+	r.routes[action] = callbackRoute{permission: permission, handler: handler}
+}
+
+// registerPublic регистрирует обработчик действия пользователя
+func (r *callbackRouter) registerPublic(action string, handler callbackHandler) {
+	r.register(action, access.PermissionRead, handler)
+	route := r.routes[action]
+	route.public = true
+	r.routes[action] = route
+}
+
+// route обработчик действия, ok = false если действие неизвестно
+func (r *callbackRouter) route(action string) (callbackRoute, bool) {
+	route, ok := r.routes[action]
+	return route, ok
+}
+
+// registerCallbacks регистрация всех действий inline кнопок
+func (t TelegramWebhookController) registerCallbacks() {
+	t.callbacks.register(callback.ActionIgnore, access.PermissionRead, t.processIgnoreCallback)
+	t.callbacks.register(callback.ActionClose, access.PermissionManage, t.processCloseCallback)
+	t.callbacks.register(callback.ActionTake, access.PermissionManage, t.processTakeCallback)
+	t.callbacks.register(callback.ActionUrgent, access.PermissionManage, t.processUrgentCallback)
+	t.callbacks.register(callback.ActionSpam, access.PermissionManage, t.processSpamCallback)
+	t.callbacks.register(callback.ActionBan, access.PermissionManage, t.processBanCallback)
+	t.callbacks.register(callback.ActionReopen, access.PermissionManage, t.processReopenCallback)
+	t.callbacks.register(callback.ActionHistory, access.PermissionRead, t.processHistoryCallback)
+	t.callbacks.register(callback.ActionRelease, access.PermissionManage, t.processReleaseCallback)
+	t.callbacks.register(callback.ActionDiscard, access.PermissionManage, t.processDiscardCallback)
+	t.callbacks.register(callback.ActionCategoryMenu, access.PermissionManage, t.processCategoryMenuCallback)
+	t.callbacks.register(callback.ActionSetCategory, access.PermissionManage, t.processSetCategoryCallback)
+	t.callbacks.registerPublic(callback.ActionUserCategory, t.processUserCategoryCallback)
+	t.callbacks.registerPublic(callback.ActionRegionLetter, t.processRegionLetterCallback)
+	t.callbacks.registerPublic(callback.ActionRegionBack, t.processRegionBackCallback)
+	t.callbacks.registerPublic(callback.ActionUserRegion, t.processUserRegionCallback)
+}
+
+// ForkCallbacks Обработка колбека сообщения
+func (t TelegramWebhookController) ForkCallbacks(ctx context.Context, update tgbotapi.Update) {
+	answer := t.routeCallback(ctx, update)
+
+	err := t.bot.AnswerCallback(update.CallbackQuery.ID, answer.Text, answer.Alert)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+	}
+}
+
+// routeCallback разбор данных кнопки и вызов обработчика действия
+func (t TelegramWebhookController) routeCallback(ctx context.Context, update tgbotapi.Update) callbackAnswer {
+	data, err := callback.Decode(update.CallbackData())
+	if err != nil {
+		t.logger.Warn(fmt.Sprintf("Ошибка при парсинге callback %q: %s", update.CallbackData(), err))
+		if errors.Is(err, callback.ErrUnsupportedVersion) {
+			return callbackAnswer{Text: "Кнопка устарела, действие недоступно", Alert: true}
+		}
+		return callbackAnswer{Text: "Не удалось обработать кнопку", Alert: true}
+	}
+
+	route, ok := t.callbacks.route(data.Action)
+	if !ok {
+		t.logger.Warn(fmt.Sprintf("Неизвестное действие callback: %q", data.Action))
+		return callbackAnswer{Text: "Неизвестное действие", Alert: true}
+	}
+
+	if route.public {
+		return route.handler(ctx, update, data)
+	}
+
+	message := update.CallbackQuery.Message
+	if message == nil || !t.isAdminChat(message.Chat.ID) || !t.access.Can(ctx, update.CallbackQuery.From.ID, route.permission) {
+		return callbackAnswer{Text: permissionDeniedMessage, Alert: true}
+	}
+
+	return route.handler(ctx, update, data)
+}
+
+// processIgnoreCallback нажатие на кнопку-отметку без действия
+func (t TelegramWebhookController) processIgnoreCallback(_ context.Context, _ tgbotapi.Update, _ callback.Data) callbackAnswer {
+	return callbackAnswer{}
+}
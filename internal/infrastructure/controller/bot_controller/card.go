@@ -0,0 +1,102 @@
+package bot_controller
+
+import (
+	"context"
+	"fmt"
+	"medrussia_news_bot/internal/infrastructure/repo"
+	"medrussia_news_bot/internal/pkg/telegram"
+	"medrussia_news_bot/internal/service/anonymity"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// cardHeader шапка карточки обращения в чате админов. В режиме защиты источников вместо
+// username и имени показывается псевдоним, а настоящие данные сохраняются отдельно
+func (t TelegramWebhookController) cardHeader(ctx context.Context, chat *tgbotapi.Chat) string {
+	if t.anonymity.Enabled() {
+		t.anonymity.Remember(ctx, anonymity.Identity{
+			UserID:    chat.ID,
+			UserName:  chat.UserName,
+			FirstName: chat.FirstName,
+			LastName:  chat.LastName,
+		})
+		return "Пользователь: " + t.anonymity.Pseudonym(chat.ID)
+	}
+
+	return fmt.Sprintf("Пользователь: @%s\nИмя: %s %s", chat.UserName, chat.LastName, chat.FirstName)
+}
+
+// cardHeading шапка карточки: отметка срочности, отправитель и пометки бота о файле.
+// По ней же карточка собирается заново после правки сообщения пользователем
+func cardHeading(marker, header, note string) string {
+	if marker != "" {
+		header = marker + "\n" + header
+	}
+	if note != "" {
+		header += "\n" + note
+	}
+
+	return header
+}
+
+// withNote пометки бота над подписью вложения альбома, у которого нет шапки
+func withNote(note, caption string) string {
+	return strings.TrimSpace(note + "\n\n" + caption)
+}
+
+// sourceName пользователь в сообщениях чата админов: telegram ID или псевдоним в режиме защиты источников
+func (t TelegramWebhookController) sourceName(userID int64) string {
+	if t.anonymity.Enabled() {
+		return t.anonymity.Pseudonym(userID)
+	}
+
+	return fmt.Sprintf("пользователь %d", userID)
+}
+
+// upperFirst текст с заглавной буквы, для sourceName в начале предложения
+func upperFirst(text string) string {
+	r, size := utf8.DecodeRuneInString(text)
+	if r == utf8.RuneError {
+		return text
+	}
+
+	return string(unicode.ToUpper(r)) + text[size:]
+}
+
+// editedCardBody текст отредактированного сообщения вместе с исходной версией
+func editedCardBody(label, current, original string) string {
+	return fmt.Sprintf("%s (изменено ✏️): %s\n\nИсходная версия: %s", label, current, original)
+}
+
+// cardState состояние обращения для клавиатуры карточки
+func (t TelegramWebhookController) cardState(ctx context.Context, appeal *repo.Appeal) telegram.CardState {
+	banned, err := t.repo.IsUserBanned(ctx, appeal.UserID)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+	}
+
+	var assignee string
+	if appeal.AssignedTo.Valid {
+		editor, err := t.repo.GetEditor(ctx, appeal.AssignedTo.Int64)
+		if err != nil {
+			t.logger.Error(fmt.Sprintf("%s", err))
+		} else {
+			assignee = editor.DisplayName()
+		}
+	}
+
+	return telegram.CardState{
+		AppealID:   appeal.ID,
+		InProgress: appeal.Status == repo.AppealStatusInProgress,
+		Assignee:   assignee,
+		Urgent:     appeal.Urgent,
+		Closed:     appeal.IsClosed(),
+		Spam:       appeal.Status == repo.AppealStatusSpam,
+		Banned:     banned,
+		Category:   t.categoryTitle(ctx, appeal),
+		Region:     regionName(appeal),
+	}
+}
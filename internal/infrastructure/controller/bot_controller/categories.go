@@ -0,0 +1,262 @@
+package bot_controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"medrussia_news_bot/internal/infrastructure/repo"
+	"medrussia_news_bot/internal/pkg/callback"
+	"medrussia_news_bot/internal/pkg/telegram"
+	"medrussia_news_bot/internal/service/audit"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	chooseCategoryMessage = "Выберите, пожалуйста, тему обращения:"
+	// categoryAppealsLimit сколько обращений показывает /appeals
+	categoryAppealsLimit = 20
+	// categoryStatsDays период /categories по умолчанию
+	categoryStatsDays = 30
+)
+
+// categoryOptions действующие темы для клавиатур
+func (t TelegramWebhookController) categoryOptions(ctx context.Context) []telegram.CategoryOption {
+	categories, err := t.repo.ListCategories(ctx)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return nil
+	}
+
+	options := make([]telegram.CategoryOption, 0, len(categories))
+	for _, category := range categories {
+		options = append(options, telegram.CategoryOption{ID: category.ID, Title: category.Title})
+	}
+
+	return options
+}
+
+// categoryTitle название темы обращения, пустое если тема не выбрана
+func (t TelegramWebhookController) categoryTitle(ctx context.Context, appeal *repo.Appeal) string {
+	if !appeal.CategoryID.Valid {
+		return ""
+	}
+
+	category, err := t.repo.GetCategory(ctx, appeal.CategoryID.Int64)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return ""
+	}
+
+	return category.Title
+}
+
+// offerCategories сообщение пользователю с клавиатурой выбора темы
+func (t TelegramWebhookController) offerCategories(ctx context.Context, chatID int64, text string) {
+	_, err := t.bot.SendCategoryChoice(chatID, text, t.categoryOptions(ctx))
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+	}
+}
+
+// applyPendingCategory переносит тему, выбранную после /start, на только что открытое обращение
+func (t TelegramWebhookController) applyPendingCategory(ctx context.Context, appeal *repo.Appeal) *repo.Appeal {
+	if appeal.CategoryID.Valid {
+		return appeal
+	}
+
+	applied, err := t.repo.ApplyPendingCategory(ctx, appeal.UserID, appeal.ID)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return appeal
+	}
+	if !applied {
+		return appeal
+	}
+
+	updated, err := t.repo.GetAppeal(ctx, appeal.ID)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return appeal
+	}
+
+	return updated
+}
+
+// processUserCategoryCallback выбор темы пользователем: тема ставится на незакрытое обращение,
+// а если его еще нет - на следующее
+func (t TelegramWebhookController) processUserCategoryCallback(ctx context.Context, update tgbotapi.Update, data callback.Data) callbackAnswer {
+	query := update.CallbackQuery
+	if query.Message == nil || query.Message.Chat == nil || !query.Message.Chat.IsPrivate() {
+		return callbackAnswer{}
+	}
+
+	category, err := t.repo.GetCategory(ctx, data.Arg(0))
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return callbackAnswer{Text: "Не удалось выбрать тему", Alert: true}
+	}
+
+	// регион спрашиваем следующим, если он еще неизвестен
+	askRegion := true
+	appeal, err := t.repo.GetOpenAppeal(ctx, query.From.ID)
+	switch {
+	case err == nil:
+		askRegion = !appeal.RegionCode.Valid
+		if err = t.repo.SetAppealCategory(ctx, appeal.ID, category.ID); err == nil {
+			appeal.CategoryID.Int64, appeal.CategoryID.Valid = category.ID, true
+			t.renderActiveCard(ctx, appeal)
+		}
+	case errors.Is(err, repo.ErrAppealNotFound):
+		err = t.repo.SetPendingCategory(ctx, query.From.ID, category.ID)
+	}
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return callbackAnswer{Text: "Не удалось выбрать тему", Alert: true}
+	}
+
+	text := fmt.Sprintf("%s\n\n🏷 Тема обращения: %s", strings.TrimSuffix(query.Message.Text, chooseCategoryMessage), category.Title)
+	err = t.bot.ConfirmUserChoice(query.Message.Chat.ID, int64(query.Message.MessageID), strings.TrimSpace(text))
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+	}
+	if askRegion {
+		t.offerRegions(query.Message.Chat.ID, chooseRegionMessage)
+	}
+
+	return callbackAnswer{Text: "Спасибо!"}
+}
+
+// processCategoryMenuCallback показ меню выбора темы вместо клавиатуры карточки
+func (t TelegramWebhookController) processCategoryMenuCallback(ctx context.Context, update tgbotapi.Update, data callback.Data) callbackAnswer {
+	appeal, err := t.callbackAppeal(ctx, data)
+	if err != nil {
+		return t.appealActionFailed(err)
+	}
+
+	err = t.bot.SetCategoryMenu(int64(update.CallbackQuery.Message.MessageID), t.cardState(ctx, appeal), t.categoryOptions(ctx))
+	if err != nil {
+		return callbackAnswer{Text: "Не удалось показать темы", Alert: true}
+	}
+
+	return callbackAnswer{}
+}
+
+// processSetCategoryCallback смена темы редактором, тема 0 - возврат к карточке без изменений
+func (t TelegramWebhookController) processSetCategoryCallback(ctx context.Context, update tgbotapi.Update, data callback.Data) callbackAnswer {
+	appeal, err := t.callbackAppeal(ctx, data)
+	if err != nil {
+		return t.appealActionFailed(err)
+	}
+
+	messageID := int64(update.CallbackQuery.Message.MessageID)
+	categoryID := data.Arg(1)
+	if categoryID == 0 {
+		t.renderCard(ctx, messageID, appeal.ID)
+		return callbackAnswer{}
+	}
+
+	category, err := t.repo.GetCategory(ctx, categoryID)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return callbackAnswer{Text: "Тема не найдена", Alert: true}
+	}
+	if err = t.repo.SetAppealCategory(ctx, appeal.ID, category.ID); err != nil {
+		return t.appealActionFailed(err)
+	}
+
+	t.recordAppealEvent(ctx, appeal.ID, audit.ActionCategory, update.CallbackQuery.From.ID, audit.Payload{"category": category.Code})
+	t.renderCard(ctx, messageID, appeal.ID)
+
+	return callbackAnswer{Text: "Тема: " + category.Title}
+}
+
+// processCategoriesCommand /categories [дней] статистика обращений по темам
+func (t TelegramWebhookController) processCategoriesCommand(ctx context.Context, update tgbotapi.Update) {
+	days := categoryStatsDays
+	if arg := strings.TrimSpace(update.Message.CommandArguments()); arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n <= 0 {
+			t.replyToAdmin(update, "Укажите период в днях: /categories 30")
+			return
+		}
+		days = n
+	}
+
+	stats, err := t.repo.CategoryStats(ctx, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		t.replyToAdmin(update, "Ошибка при получении статистики")
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Обращения по темам за %d дн. (всего / не закрыто):\n", days)
+	if len(stats) == 0 {
+		b.WriteString("\nобращений нет")
+	}
+	for _, stat := range stats {
+		title := "Без темы"
+		if stat.CategoryTitle.Valid {
+			title = stat.CategoryTitle.String
+		}
+		fmt.Fprintf(&b, "\n%s: %d / %d", title, stat.Total, stat.Open)
+	}
+
+	categories, err := t.repo.ListCategories(ctx)
+	if err == nil {
+		codes := make([]string, 0, len(categories))
+		for _, category := range categories {
+			codes = append(codes, category.Code)
+		}
+		fmt.Fprintf(&b, "\n\nКоды тем для /appeals: %s", strings.Join(codes, ", "))
+	}
+
+	t.replyToAdmin(update, b.String())
+}
+
+// processCategoryAppealsCommand /appeals <код темы> [open] последние обращения по теме
+func (t TelegramWebhookController) processCategoryAppealsCommand(ctx context.Context, update tgbotapi.Update) {
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 || len(args) > 2 || (len(args) == 2 && args[1] != "open") {
+		t.replyToAdmin(update, "Использование: /appeals <код темы> [open], коды тем - в /categories")
+		return
+	}
+
+	category, err := t.repo.GetCategoryByCode(ctx, args[0])
+	if err != nil {
+		if errors.Is(err, repo.ErrCategoryNotFound) {
+			t.replyToAdmin(update, "Тема не найдена, коды тем - в /categories")
+			return
+		}
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return
+	}
+
+	onlyOpen := len(args) == 2
+	appeals, err := t.repo.ListCategoryAppeals(ctx, category.ID, onlyOpen, categoryAppealsLimit)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		t.replyToAdmin(update, "Ошибка при получении обращений")
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Обращения по теме «%s»", category.Title)
+	if onlyOpen {
+		b.WriteString(", только незакрытые")
+	}
+	fmt.Fprintf(&b, ": %d\n", len(appeals))
+	for _, appeal := range appeals {
+		fmt.Fprintf(&b, "\n#%d — %s, %s, открыто %s",
+			appeal.ID, appealStatusNames[appeal.Status], t.sourceName(appeal.UserID), appeal.OpenedAt.Format("02.01.2006 15:04"))
+		if appeal.Urgent {
+			b.WriteString(" 🔥")
+		}
+	}
+
+	t.replyToAdmin(update, b.String())
+}
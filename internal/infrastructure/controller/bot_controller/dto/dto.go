@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type Chat struct {
 	ID int64 `json:"id"`
 }
@@ -16,6 +18,7 @@ type Animation struct {
 	FileID       string `json:"file_id"`
 	FileUniqueID string `json:"file_unique_id"`
 	FileName     string `json:"file_name,omitempty"`
+	MimeType     string `json:"mime_type,omitempty"`
 	FileSize     int    `json:"file_size,omitempty"`
 }
 
@@ -23,6 +26,7 @@ type Audio struct {
 	FileID       string `json:"file_id"`
 	FileUniqueID string `json:"file_unique_id"`
 	FileName     string `json:"file_name,omitempty"`
+	MimeType     string `json:"mime_type,omitempty"`
 	FileSize     int    `json:"file_size,omitempty"`
 }
 
@@ -30,6 +34,7 @@ type Document struct {
 	FileID       string `json:"file_id"`
 	FileUniqueID string `json:"file_unique_id"`
 	FileName     string `json:"file_name,omitempty"`
+	MimeType     string `json:"mime_type,omitempty"`
 	FileSize     int    `json:"file_size,omitempty"`
 }
 
@@ -37,6 +42,7 @@ type Video struct {
 	FileID       string `json:"file_id"`
 	FileUniqueID string `json:"file_unique_id"`
 	FileName     string `json:"file_name,omitempty"`
+	MimeType     string `json:"mime_type,omitempty"`
 	FileSize     int    `json:"file_size,omitempty"`
 }
 
@@ -49,11 +55,14 @@ type VideoNote struct {
 type Voice struct {
 	FileID       string `json:"file_id"`
 	FileUniqueID string `json:"file_unique_id"`
+	MimeType     string `json:"mime_type,omitempty"`
 	FileSize     int    `json:"file_size,omitempty"`
 }
 
 type Contact struct {
 	PhoneNumber string `json:"phone_number"`
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name,omitempty"`
 	UserID      int64  `json:"user_id,omitempty"`
 }
 
@@ -84,6 +93,8 @@ type MessageDTO struct {
 	Chat              *Chat                 `json:"chat"`
 	ForwardDate       int                   `json:"forward_date,omitempty"`
 	Text              string                `json:"text,omitempty"`
+	Caption           string                `json:"caption,omitempty"`
+	MediaGroupID      string                `json:"media_group_id,omitempty"`
 	Animation         *Animation            `json:"animation,omitempty"`
 	Audio             *Audio                `json:"audio,omitempty"`
 	Document          *Document             `json:"document,omitempty"`
@@ -96,6 +107,8 @@ type MessageDTO struct {
 	MigrateToChatID   int64                 `json:"migrate_to_chat_id,omitempty"`
 	MigrateFromChatID int64                 `json:"migrate_from_chat_id,omitempty"`
 	ReplyMarkup       *InlineKeyboardMarkup `json:"reply_markup,omitempty"`
+	// ReceivedAt время получения ботом, если сообщение обрабатывается позже, например после карантина
+	ReceivedAt time.Time `json:"-"`
 }
 
 type TgUserDTO struct {
@@ -107,3 +120,55 @@ type TgUserDTO struct {
 	LanguageCode          string `json:"language_code,omitempty"`
 	SupportsInlineQueries bool   `json:"supports_inline_queries,omitempty"`
 }
+
+// Типы вложений сообщения
+const (
+	MediaKindPhoto     = "photo"
+	MediaKindVideo     = "video"
+	MediaKindVideoNote = "video_note"
+	MediaKindVoice     = "voice"
+	MediaKindAudio     = "audio"
+	MediaKindDocument  = "document"
+	MediaKindAnimation = "animation"
+	MediaKindSticker   = "sticker"
+	MediaKindContact   = "contact"
+)
+
+// Media вложение сообщения
+type Media struct {
+	Kind         string
+	FileID       string
+	FileUniqueID string
+	FileName     string
+	MimeType     string
+	FileSize     int
+}
+
+// Media возвращает вложение сообщения, ok = false если сообщение текстовое
+func (m MessageDTO) Media() (media Media, ok bool) {
+	switch {
+	case len(m.Photo) > 0:
+		// последний размер в списке самый большой
+		photo := m.Photo[len(m.Photo)-1]
+		return Media{Kind: MediaKindPhoto, FileID: photo.FileID, FileUniqueID: photo.FileUniqueID, MimeType: "image/jpeg", FileSize: photo.FileSize}, true
+	case m.Animation != nil:
+		// анимация приходит вместе с document, поэтому проверяем ее раньше
+		return Media{Kind: MediaKindAnimation, FileID: m.Animation.FileID, FileUniqueID: m.Animation.FileUniqueID, FileName: m.Animation.FileName, MimeType: m.Animation.MimeType, FileSize: m.Animation.FileSize}, true
+	case m.Video != nil:
+		return Media{Kind: MediaKindVideo, FileID: m.Video.FileID, FileUniqueID: m.Video.FileUniqueID, FileName: m.Video.FileName, MimeType: m.Video.MimeType, FileSize: m.Video.FileSize}, true
+	case m.VideoNote != nil:
+		return Media{Kind: MediaKindVideoNote, FileID: m.VideoNote.FileID, FileUniqueID: m.VideoNote.FileUniqueID, FileSize: m.VideoNote.FileSize}, true
+	case m.Voice != nil:
+		return Media{Kind: MediaKindVoice, FileID: m.Voice.FileID, FileUniqueID: m.Voice.FileUniqueID, MimeType: m.Voice.MimeType, FileSize: m.Voice.FileSize}, true
+	case m.Audio != nil:
+		return Media{Kind: MediaKindAudio, FileID: m.Audio.FileID, FileUniqueID: m.Audio.FileUniqueID, FileName: m.Audio.FileName, MimeType: m.Audio.MimeType, FileSize: m.Audio.FileSize}, true
+	case m.Document != nil:
+		return Media{Kind: MediaKindDocument, FileID: m.Document.FileID, FileUniqueID: m.Document.FileUniqueID, FileName: m.Document.FileName, MimeType: m.Document.MimeType, FileSize: m.Document.FileSize}, true
+	case m.Sticker != nil:
+		return Media{Kind: MediaKindSticker, FileID: m.Sticker.FileID, FileUniqueID: m.Sticker.FileUniqueID, FileSize: m.Sticker.FileSize}, true
+	case m.Contact != nil:
+		return Media{Kind: MediaKindContact}, true
+	}
+
+	return Media{}, false
+}
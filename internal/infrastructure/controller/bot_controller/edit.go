@@ -0,0 +1,167 @@
+package bot_controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"medrussia_news_bot/internal/infrastructure/repo"
+	"medrussia_news_bot/internal/pkg/telegram"
+	"medrussia_news_bot/internal/service/access"
+	"medrussia_news_bot/internal/service/audit"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ForkEditMessage обработка отредактированных сообщений
+func (t TelegramWebhookController) ForkEditMessage(ctx context.Context, update tgbotapi.Update) {
+	if t.isAdminChat(update.EditedMessage.Chat.ID) {
+		t.processAdminEdit(ctx, update)
+		return
+	}
+
+	t.processUserEdit(ctx, update)
+}
+
+// processAdminEdit переносим правку ответа редактора на копию, доставленную пользователю
+func (t TelegramWebhookController) processAdminEdit(ctx context.Context, update tgbotapi.Update) {
+	edited := update.EditedMessage
+
+	link, err := t.repo.GetMessageLink(ctx, int64(edited.MessageID))
+	if err != nil {
+		if !errors.Is(err, repo.ErrMessageLinkNotFound) {
+			t.logger.Error(fmt.Sprintf("%s", err))
+		}
+		// правили не ответ пользователю
+		return
+	}
+	if link.Direction != repo.DirectionAdminToUser || link.RecalledAt.Valid {
+		return
+	}
+	if !t.access.Can(ctx, edited.From.ID, access.PermissionReply) {
+		_, _ = t.bot.ReplyInAdminChat(int64(edited.MessageID), permissionDeniedMessage)
+		return
+	}
+
+	err = t.bot.EditAdminReply(link.UserID, link.UserMessageID, edited)
+	if err != nil {
+		_, _ = t.bot.ReplyInAdminChat(int64(edited.MessageID), "Не удалось изменить ответ у пользователя: "+err.Error())
+		return
+	}
+	t.audit.Record(ctx, edited.From.ID, audit.ActionEditReply, link.AppealID.Int64, audit.Payload{
+		"user_id":          link.UserID,
+		"admin_message_id": edited.MessageID,
+		"user_message_id":  link.UserMessageID,
+	})
+
+	original, err := t.repo.GetMessageByAdminChatMessage(ctx, int64(edited.MessageID), repo.DirectionAdminToUser)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return
+	}
+
+	tgMessage := t.getMessageFromWebhook(update)
+	err = t.repo.SaveMessageEdit(ctx, repo.MessageEdit{
+		MessageID: original.ID,
+		EditorID:  edited.From.ID,
+		Text:      nullString(tgMessage.Text),
+		Caption:   nullString(tgMessage.Caption),
+	})
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+	}
+}
+
+// processUserEdit переносим правку пользователя на его карточку в чате админов, состояние обращения не меняется
+func (t TelegramWebhookController) processUserEdit(ctx context.Context, update tgbotapi.Update) {
+	edited := update.EditedMessage
+	userID := edited.From.ID
+	userMessageID := int64(edited.MessageID)
+
+	link, err := t.repo.GetMessageLinkByUserMessage(ctx, userID, userMessageID, repo.DirectionUserToAdmin)
+	if err != nil {
+		if errors.Is(err, repo.ErrMessageLinkNotFound) {
+			t.logger.Warn(fmt.Sprintf("Не найдена карточка для измененного сообщения %d, %s", userMessageID, t.sourceName(userID)))
+			return
+		}
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return
+	}
+
+	original, err := t.repo.GetMessageByUserChatMessage(ctx, userID, userMessageID, repo.DirectionUserToAdmin)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return
+	}
+
+	tgMessage := t.getMessageFromWebhook(update)
+	err = t.repo.SaveMessageEdit(ctx, repo.MessageEdit{
+		MessageID: original.ID,
+		EditorID:  userID,
+		Text:      nullString(tgMessage.Text),
+		Caption:   nullString(tgMessage.Caption),
+	})
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+	}
+
+	// карточка собирается так же, как в forwardToAdmin: отметка срочности по исходному тексту
+	// и пометки о файле, сохраненные при пересылке
+	marker := triageMarker(t.triage.Match(original.Text.String + original.Caption.String))
+	header := cardHeading(marker, t.cardHeader(ctx, edited.Chat), original.CardNote.String)
+	switch {
+	case original.MediaGroupID.Valid:
+		// у элементов альбома нет шапки и кнопок, пометки стоят над подписью
+		body := editedCardBody("Подпись", tgMessage.Caption, original.Caption.String)
+		err = t.bot.EditAdminCardCaption(link.AdminChatMessageID, withNote(original.CardNote.String, body), nil)
+	case original.MediaKind.Valid && t.heldCard(ctx, link.AdminChatMessageID):
+		// вместо задержанного документа редакции ушла текстовая карточка
+		body := editedCardBody("Подпись", tgMessage.Caption, original.Caption.String)
+		card := t.editedCardKeyboard(ctx, userID, link.AdminChatMessageID)
+		err = t.bot.EditAdminCardText(link.AdminChatMessageID, header+"\n\n"+body, card)
+	case original.MediaKind.Valid:
+		body := editedCardBody("Подпись", tgMessage.Caption, original.Caption.String)
+		card := t.editedCardKeyboard(ctx, userID, link.AdminChatMessageID)
+		err = t.bot.EditAdminCardCaption(link.AdminChatMessageID, header+"\n\n"+body, card)
+	default:
+		body := editedCardBody("Текст сообщения", tgMessage.Text, original.Text.String)
+		card := t.editedCardKeyboard(ctx, userID, link.AdminChatMessageID)
+		err = t.bot.EditAdminCardText(link.AdminChatMessageID, header+"\n\n"+body, card)
+	}
+	if err != nil {
+		t.logger.Warn(fmt.Sprintf("Не удалось изменить карточку %d: %s", link.AdminChatMessageID, err))
+	}
+}
+
+// heldCard карточка задержанного документа: текст без вложения
+func (t TelegramWebhookController) heldCard(ctx context.Context, adminMessageID int64) bool {
+	original, err := t.repo.GetMediaOriginalByMessage(ctx, adminMessageID)
+	if err != nil {
+		if !errors.Is(err, repo.ErrMediaOriginalNotFound) {
+			t.logger.Error(fmt.Sprintf("%s", err))
+		}
+		return false
+	}
+
+	return original.HeldReason.Valid
+}
+
+// editedCardKeyboard клавиатура остается только на последней карточке открытого обращения
+func (t TelegramWebhookController) editedCardKeyboard(ctx context.Context, userID, adminMessageID int64) *telegram.CardState {
+	user, err := t.repo.GetUser(ctx, userID)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return nil
+	}
+	if !user.Available || user.LastUserMessageID.Int64 != adminMessageID {
+		return nil
+	}
+
+	appeal, err := t.repo.GetOpenAppeal(ctx, userID)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return nil
+	}
+
+	card := t.cardState(ctx, appeal)
+	return &card
+}
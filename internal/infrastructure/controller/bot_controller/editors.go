@@ -0,0 +1,190 @@
+package bot_controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"medrussia_news_bot/internal/infrastructure/repo"
+	"medrussia_news_bot/internal/service/access"
+	"medrussia_news_bot/internal/service/audit"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// appealInProgressMessage уведомление пользователю, что его обращением занимаются
+const appealInProgressMessage = "Ваше обращение взято в работу редакцией. Мы свяжемся с вами, если понадобятся подробности."
+
+// rememberEditor сохраняем участника чата админов, чтобы находить его по username
+func (t TelegramWebhookController) rememberEditor(ctx context.Context, from *tgbotapi.User) {
+	if from == nil || from.IsBot {
+		return
+	}
+
+	err := t.repo.SaveEditor(ctx, repo.Editor{
+		TgID:      from.ID,
+		UserName:  nullString(from.UserName),
+		FirstName: nullString(from.FirstName),
+		LastName:  nullString(from.LastName),
+	})
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+	}
+}
+
+// assignAppeal назначение ответственного редактора editorID редактором actorID,
+// при первом назначении пользователь получает уведомление
+func (t TelegramWebhookController) assignAppeal(
+	ctx context.Context,
+	appeal *repo.Appeal,
+	actorID, editorID int64,
+	action string,
+) error {
+	err := t.repo.AssignAppeal(ctx, appeal.ID, editorID)
+	if err != nil {
+		return err
+	}
+
+	t.recordAppealEvent(ctx, appeal.ID, action, actorID, audit.Payload{"assignee": editorID})
+
+	if appeal.Status != repo.AppealStatusInProgress {
+		t.bot.SendMessage(tgbotapi.NewMessage(appeal.UserID, appealInProgressMessage))
+	}
+
+	return nil
+}
+
+// renderActiveCard перерисовка последней карточки незакрытого обращения пользователя
+func (t TelegramWebhookController) renderActiveCard(ctx context.Context, appeal *repo.Appeal) {
+	if appeal.IsClosed() {
+		return
+	}
+
+	user, err := t.repo.GetUser(ctx, appeal.UserID)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return
+	}
+	if user.LastUserMessageID.Int64 == 0 {
+		return
+	}
+
+	t.renderCard(ctx, user.LastUserMessageID.Int64, appeal.ID)
+}
+
+// replyAppeal обращение карточки, на которую ответили командой
+func (t TelegramWebhookController) replyAppeal(ctx context.Context, replyTo *tgbotapi.Message) (*repo.Appeal, error) {
+	link := t.resolveReplyLink(ctx, replyTo)
+	if link == nil {
+		return nil, repo.ErrAppealNotFound
+	}
+	if link.AppealID.Valid {
+		return t.repo.GetAppeal(ctx, link.AppealID.Int64)
+	}
+
+	return t.repo.GetOpenAppeal(ctx, link.UserID)
+}
+
+// commandEditor редактор из аргумента команды, без аргумента - автор команды
+func (t TelegramWebhookController) commandEditor(ctx context.Context, update tgbotapi.Update) (*repo.Editor, error) {
+	username := strings.TrimSpace(update.Message.CommandArguments())
+	if username == "" {
+		return t.repo.GetEditor(ctx, update.Message.From.ID)
+	}
+
+	return t.repo.GetEditorByUsername(ctx, username)
+}
+
+// processAssignCommand /assign @editor ответом на карточку назначает ответственного за обращение
+func (t TelegramWebhookController) processAssignCommand(ctx context.Context, update tgbotapi.Update) {
+	replyTo := update.Message.ReplyToMessage
+	if replyTo == nil {
+		t.replyToAdmin(update, "Отправьте /assign @username ответом на карточку обращения")
+		return
+	}
+
+	appeal, err := t.replyAppeal(ctx, replyTo)
+	if err != nil {
+		if errors.Is(err, repo.ErrAppealNotFound) {
+			t.replyToAdmin(update, "Не найдено обращение для этого сообщения")
+			return
+		}
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return
+	}
+	if appeal.IsClosed() {
+		t.replyToAdmin(update, "Обращение закрыто, сначала переоткройте его")
+		return
+	}
+
+	editor, err := t.commandEditor(ctx, update)
+	if err != nil {
+		if errors.Is(err, repo.ErrEditorNotFound) {
+			t.replyToAdmin(update, "Редактор не найден: он должен хотя бы раз написать в этот чат")
+			return
+		}
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return
+	}
+
+	if !t.access.Can(ctx, editor.TgID, access.PermissionReply) {
+		t.replyToAdmin(update, "Наблюдателю нельзя назначить обращение")
+		return
+	}
+
+	if err = t.assignAppeal(ctx, appeal, update.Message.From.ID, editor.TgID, audit.ActionAssign); err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		t.replyToAdmin(update, "Ошибка при назначении ответственного")
+		return
+	}
+	t.renderActiveCard(ctx, appeal)
+
+	t.replyToAdmin(update, fmt.Sprintf("Обращение #%d назначено: %s", appeal.ID, editor.DisplayName()))
+}
+
+// processWorkloadCommand /workload количество незакрытых обращений у каждого редактора
+func (t TelegramWebhookController) processWorkloadCommand(ctx context.Context, update tgbotapi.Update) {
+	workload, err := t.repo.ListEditorsWorkload(ctx)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("Незакрытые обращения по редакторам:\n")
+	for _, w := range workload {
+		fmt.Fprintf(&b, "\n%s — %d", w.Editor.DisplayName(), w.OpenAppeals)
+	}
+
+	t.replyToAdmin(update, b.String())
+}
+
+// processAssignedCommand /assigned [@editor] список незакрытых обращений редактора
+func (t TelegramWebhookController) processAssignedCommand(ctx context.Context, update tgbotapi.Update) {
+	editor, err := t.commandEditor(ctx, update)
+	if err != nil {
+		if errors.Is(err, repo.ErrEditorNotFound) {
+			t.replyToAdmin(update, "Редактор не найден")
+			return
+		}
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return
+	}
+
+	appeals, err := t.repo.ListEditorAppeals(ctx, editor.TgID)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Обращения в работе у %s: %d\n", editor.DisplayName(), len(appeals))
+	for _, appeal := range appeals {
+		fmt.Fprintf(&b, "\n#%d — %s, открыто %s", appeal.ID, t.sourceName(appeal.UserID), appeal.OpenedAt.Format("02.01.2006 15:04"))
+		if appeal.Urgent {
+			b.WriteString(" 🔥")
+		}
+	}
+
+	t.replyToAdmin(update, b.String())
+}
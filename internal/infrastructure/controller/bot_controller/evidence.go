@@ -0,0 +1,112 @@
+package bot_controller
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"medrussia_news_bot/internal/infrastructure/controller/bot_controller/dto"
+	"medrussia_news_bot/internal/infrastructure/repo"
+	"medrussia_news_bot/internal/service/audit"
+	"medrussia_news_bot/internal/service/evidence"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// recordEvidence учет полученного файла: хеш и время получения. Возвращает запись
+// и содержимое файла для очистки метаданных, у контактов и стикеров учета нет
+func (t TelegramWebhookController) recordEvidence(
+	ctx context.Context,
+	appealID, userID int64,
+	tgMessage dto.MessageDTO,
+	media dto.Media,
+) (*repo.EvidenceItem, []byte) {
+	if !t.evidence.Enabled() || media.FileID == "" || media.Kind == dto.MediaKindSticker {
+		return nil, nil
+	}
+
+	return t.evidence.Record(ctx, evidence.File{
+		AppealID:      appealID,
+		UserID:        userID,
+		UserMessageID: tgMessage.MessageID,
+		Kind:          media.Kind,
+		FileID:        media.FileID,
+		FileUniqueID:  media.FileUniqueID,
+		MimeType:      media.MimeType,
+		SentAt:        time.Unix(int64(tgMessage.Date), 0),
+		ReceivedAt:    tgMessage.ReceivedAt,
+	})
+}
+
+// evidenceNote строка с хешем файла для карточки
+func evidenceNote(item *repo.EvidenceItem) string {
+	if item == nil {
+		return ""
+	}
+	if hash := evidence.ShortHash(item); hash != "" {
+		return "🔐 SHA-256: " + hash
+	}
+
+	return "🔐 SHA-256 не вычислен: файл не удалось скачать"
+}
+
+// processEvidenceCommand /evidence <номер обращения> или ответом на карточку: подписанная опись
+// всех файлов обращения с хешами и временем получения
+func (t TelegramWebhookController) processEvidenceCommand(ctx context.Context, update tgbotapi.Update) {
+	var appealID int64
+	if arg := strings.TrimPrefix(strings.TrimSpace(update.Message.CommandArguments()), "#"); arg != "" {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			t.replyToAdmin(update, "Укажите номер обращения: /evidence 42")
+			return
+		}
+		appealID = id
+	} else if replyTo := update.Message.ReplyToMessage; replyTo != nil {
+		if link := t.resolveReplyLink(ctx, replyTo); link != nil {
+			appealID = link.AppealID.Int64
+		}
+	}
+	if appealID == 0 {
+		t.replyToAdmin(update, "Отправьте /evidence <номер обращения> или /evidence ответом на карточку")
+		return
+	}
+
+	manifest, err := t.evidence.Manifest(ctx, appealID)
+	if err != nil {
+		if errors.Is(err, evidence.ErrNoEvidence) {
+			t.replyToAdmin(update, fmt.Sprintf("В обращении #%d нет полученных файлов", appealID))
+			return
+		}
+		t.logger.Error(fmt.Sprintf("%s", err))
+		t.replyToAdmin(update, "Ошибка при составлении описи")
+		return
+	}
+
+	caption := fmt.Sprintf("Опись материалов обращения #%d: %d файлов", appealID, manifest.Items) +
+		"\nsha256 — хеш файла от источника, cleaned_sha256 — хеш копии без метаданных из /file." +
+		" Оригинал по original_id выдается суперадмину: /original <№>"
+	if manifest.Signature != nil {
+		caption += fmt.Sprintf(
+			"\n\nПодпись Ed25519 файла описи (base64):\n%s\n\nОткрытый ключ (base64):\n%s",
+			base64.StdEncoding.EncodeToString(manifest.Signature),
+			base64.StdEncoding.EncodeToString(manifest.PublicKey),
+		)
+	} else {
+		caption += "\n\n⚠️ Опись не подписана: ключ подписи не задан"
+	}
+
+	fileName := fmt.Sprintf("appeal-%d-evidence.json", appealID)
+	_, err = t.bot.ReplyWithFileInAdminChat(int64(update.Message.MessageID), fileName, manifest.Data, caption)
+	if err != nil {
+		t.replyToAdmin(update, "Не удалось отправить опись")
+		return
+	}
+
+	t.audit.Record(ctx, update.Message.From.ID, audit.ActionExport, appealID, audit.Payload{
+		"kind":  "evidence",
+		"items": manifest.Items,
+	})
+}
@@ -0,0 +1,332 @@
+package bot_controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"medrussia_news_bot/internal/infrastructure/controller/bot_controller/dto"
+	"medrussia_news_bot/internal/infrastructure/repo"
+	"medrussia_news_bot/internal/pkg/filestore"
+	"medrussia_news_bot/internal/service/audit"
+	"medrussia_news_bot/internal/service/evidence"
+	"medrussia_news_bot/internal/service/sanitize"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// kindExtensions расширение файла в архиве, если у вложения нет имени
+var kindExtensions = map[string]string{
+	dto.MediaKindPhoto:     ".jpg",
+	dto.MediaKindVideo:     ".mp4",
+	dto.MediaKindVideoNote: ".mp4",
+	dto.MediaKindAnimation: ".mp4",
+	dto.MediaKindVoice:     ".ogg",
+}
+
+// extensionPattern расширения из имен файлов пользователей, которые можно взять в ключ хранилища
+var extensionPattern = regexp.MustCompile(`^\.[a-z0-9]{1,8}$`)
+
+// receivedFile полученный файл: запись учета, очищенная копия, ключ в архиве
+// и пометки об очистке и архиве для подписи
+type receivedFile struct {
+	evidence   *repo.EvidenceItem
+	cleaned    *sanitize.Cleaned
+	storageKey string
+	fileName   string
+	notes      []string
+}
+
+// held документ задержан: редакции уходит заглушка вместо файла
+func (r receivedFile) held() bool {
+	return r.cleaned != nil && r.cleaned.Held != nil
+}
+
+// note хеш и пометки для подписи в чате админов
+func (r receivedFile) note() string {
+	notes := r.notes
+	if note := evidenceNote(r.evidence); note != "" {
+		notes = append([]string{note}, notes...)
+	}
+
+	return strings.Join(notes, "\n")
+}
+
+// receiveFile обработка файла пользователя перед пересылкой: хеш, очистка от метаданных и архив.
+// Файл скачивается один раз, в архив попадает то же, что видит редакция. Задержанный документ
+// в архив не попадает: его оригинал хранится под originals/ и выдается только суперадминам
+func (t TelegramWebhookController) receiveFile(
+	ctx context.Context,
+	appealID, userID int64,
+	tgMessage dto.MessageDTO,
+	media dto.Media,
+) receivedFile {
+	received := receivedFile{fileName: media.FileName}
+
+	item, data := t.recordEvidence(ctx, appealID, userID, tgMessage, media)
+	received.evidence = item
+
+	archive := t.cfg.Bot.Files.Archive && archivable(media)
+	if data == nil && archive {
+		var err error
+		data, err = t.bot.DownloadFile(media.FileID, t.cfg.Bot.Files.MaxFileSize)
+		if err != nil {
+			t.logger.Error(fmt.Sprintf("%s", err))
+		}
+	}
+
+	cleaned, note := t.sanitizeDocument(ctx, appealID, userID, media, data)
+	received.cleaned = cleaned
+	if note != "" {
+		received.notes = append(received.notes, note)
+	}
+	if cleaned != nil {
+		t.evidence.RecordDelivery(ctx, item, evidence.Delivery{
+			OriginalID: cleaned.OriginalID,
+			Data:       cleaned.Data,
+			Held:       cleaned.Held != nil,
+		})
+	}
+	if received.held() {
+		return received
+	}
+	if cleaned != nil {
+		data, received.fileName = cleaned.Data, t.cleanFileName(appealID, media, cleaned)
+	}
+
+	if archive {
+		received.storageKey = t.archiveFile(ctx, appealID, media, received.fileName, data)
+		if received.storageKey == "" {
+			received.notes = append(received.notes, "⚠️ Файл не сохранен в архив")
+		}
+	}
+
+	return received
+}
+
+// archivable у стикеров и контактов нечего хранить
+func archivable(media dto.Media) bool {
+	return media.FileID != "" && media.Kind != dto.MediaKindSticker
+}
+
+// archiveFile сохраняет файл в хранилище и возвращает ключ, пустой если сохранить не удалось.
+// Имя файла от пользователя в ключе не используется, только расширение
+func (t TelegramWebhookController) archiveFile(ctx context.Context, appealID int64, media dto.Media, fileName string, data []byte) string {
+	if data == nil {
+		return ""
+	}
+
+	key := fmt.Sprintf("appeals/%d/%s%s", appealID, path.Base(media.FileUniqueID), archiveExtension(media, fileName))
+	contentType := media.MimeType
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	if err := t.files.Put(ctx, key, data, contentType); err != nil {
+		t.alerts.Warning("archive", fmt.Sprintf("Не удалось сохранить файл обращения #%d в архив: %s", appealID, err))
+		return ""
+	}
+
+	return key
+}
+
+func archiveExtension(media dto.Media, fileName string) string {
+	if ext := strings.ToLower(filepath.Ext(fileName)); extensionPattern.MatchString(ext) {
+		return ext
+	}
+
+	return kindExtensions[media.Kind]
+}
+
+// archivedFileName имя файла при выдаче из архива. В режиме защиты источников исходное имя не показывается
+func (t TelegramWebhookController) archivedFileName(message *repo.Message) string {
+	if message.FileName.Valid && !t.anonymity.Enabled() {
+		return message.FileName.String
+	}
+
+	return fmt.Sprintf("appeal-%d-%d%s", message.AppealID.Int64, message.ID, path.Ext(message.StorageKey.String))
+}
+
+// loadArchivedFile файл сообщения из архива
+func (t TelegramWebhookController) loadArchivedFile(ctx context.Context, messageID int64) (*repo.Message, []byte, error) {
+	message, err := t.repo.GetMessage(ctx, messageID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !message.StorageKey.Valid {
+		return nil, nil, filestore.ErrNotFound
+	}
+
+	data, err := t.files.Get(ctx, message.StorageKey.String)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return message, data, nil
+}
+
+// processFilesCommand /files <номер обращения> или ответом на карточку: список файлов обращения в архиве
+func (t TelegramWebhookController) processFilesCommand(ctx context.Context, update tgbotapi.Update) {
+	var appealID int64
+	if arg := strings.TrimPrefix(strings.TrimSpace(update.Message.CommandArguments()), "#"); arg != "" {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			t.replyToAdmin(update, "Укажите номер обращения: /files 42")
+			return
+		}
+		appealID = id
+	} else if replyTo := update.Message.ReplyToMessage; replyTo != nil {
+		if link := t.resolveReplyLink(ctx, replyTo); link != nil {
+			appealID = link.AppealID.Int64
+		}
+	}
+	if appealID == 0 {
+		t.replyToAdmin(update, "Отправьте /files <номер обращения> или /files ответом на карточку")
+		return
+	}
+
+	messages, err := t.repo.ListAppealFiles(ctx, appealID)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		t.replyToAdmin(update, "Ошибка при получении файлов")
+		return
+	}
+	if len(messages) == 0 {
+		t.replyToAdmin(update, fmt.Sprintf("В архиве нет файлов обращения #%d", appealID))
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Файлы обращения #%d в архиве:\n", appealID)
+	for _, message := range messages {
+		fmt.Fprintf(&b, "\n№%d — %s, %s, %s",
+			message.ID, message.MediaKind.String, t.archivedFileName(&message), message.CreatedAt.Format("02.01.2006 15:04"))
+	}
+	b.WriteString("\n\nСкачать: /file <№> или /file ответом на файл в чате")
+
+	t.replyToAdmin(update, b.String())
+}
+
+// processFileCommand /file <№ из /files> или ответом на файл в чате админов: выдача файла из архива
+func (t TelegramWebhookController) processFileCommand(ctx context.Context, update tgbotapi.Update) {
+	var messageID int64
+	if arg := strings.TrimPrefix(strings.TrimSpace(update.Message.CommandArguments()), "№"); arg != "" {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			t.replyToAdmin(update, "Укажите номер файла из /files: /file 42")
+			return
+		}
+		messageID = id
+	} else if replyTo := update.Message.ReplyToMessage; replyTo != nil {
+		message, err := t.repo.GetMessageByAdminChatMessage(ctx, int64(replyTo.MessageID), repo.DirectionUserToAdmin)
+		if err != nil && !errors.Is(err, repo.ErrMessageNotFound) {
+			t.logger.Error(fmt.Sprintf("%s", err))
+		}
+		if message != nil {
+			messageID = message.ID
+		}
+	}
+	if messageID == 0 {
+		t.replyToAdmin(update, "Отправьте /file <№ из /files> или /file ответом на файл пользователя")
+		return
+	}
+
+	message, data, err := t.loadArchivedFile(ctx, messageID)
+	if err != nil {
+		if errors.Is(err, repo.ErrMessageNotFound) || errors.Is(err, filestore.ErrNotFound) {
+			t.replyToAdmin(update, "Файла нет в архиве")
+			return
+		}
+		t.logger.Error(fmt.Sprintf("%s", err))
+		t.replyToAdmin(update, "Не удалось получить файл из архива")
+		return
+	}
+
+	caption := fmt.Sprintf("Файл №%d из обращения #%d", message.ID, message.AppealID.Int64)
+	_, err = t.bot.ReplyWithFileInAdminChat(int64(update.Message.MessageID), t.archivedFileName(message), data, caption)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		t.replyToAdmin(update, "Не удалось отправить файл")
+		return
+	}
+
+	t.audit.Record(ctx, update.Message.From.ID, audit.ActionExport, message.AppealID.Int64, audit.Payload{
+		"kind":       "file",
+		"message_id": message.ID,
+	})
+}
+
+// archivedFileResponse файл обращения в ответе API
+type archivedFileResponse struct {
+	ID        int64     `json:"id"`
+	Kind      string    `json:"kind"`
+	FileName  string    `json:"file_name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AppealFilesHandler GET /api/appeals/:id/files список файлов обращения в архиве
+func (t TelegramWebhookController) AppealFilesHandler(c *gin.Context) {
+	appealID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid appeal id"})
+		return
+	}
+
+	messages, err := t.repo.ListAppealFiles(c.Request.Context(), appealID)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list files"})
+		return
+	}
+
+	files := make([]archivedFileResponse, 0, len(messages))
+	for _, message := range messages {
+		files = append(files, archivedFileResponse{
+			ID:        message.ID,
+			Kind:      message.MediaKind.String,
+			FileName:  t.archivedFileName(&message),
+			CreatedAt: message.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, files)
+}
+
+// FileDownloadHandler GET /api/files/:id скачивание файла из архива, выдача пишется в журнал действий
+func (t TelegramWebhookController) FileDownloadHandler(c *gin.Context) {
+	messageID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid file id"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	message, data, err := t.loadArchivedFile(ctx, messageID)
+	if err != nil {
+		if errors.Is(err, repo.ErrMessageNotFound) || errors.Is(err, filestore.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "file not found"})
+			return
+		}
+		t.logger.Error(fmt.Sprintf("%s", err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load file"})
+		return
+	}
+
+	t.audit.Record(ctx, 0, audit.ActionExport, message.AppealID.Int64, audit.Payload{
+		"kind":       "file",
+		"message_id": message.ID,
+		"via":        "api",
+		"user":       c.GetString(gin.AuthUserKey),
+	})
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": t.archivedFileName(message)}))
+	c.Data(http.StatusOK, http.DetectContentType(data), data)
+}
@@ -0,0 +1,48 @@
+package bot_controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// allowUserMessage проверка лимита сообщений пользователя. При превышении пользователь
+// получает уведомление, а при частых превышениях админы получают предупреждение
+func (t TelegramWebhookController) allowUserMessage(ctx context.Context, update tgbotapi.Update) bool {
+	decision := t.limiter.Allow(update.Message.From.ID)
+	if decision.Allowed {
+		return true
+	}
+	if !decision.JustMuted {
+		return false
+	}
+
+	minutes := int(math.Ceil(time.Until(decision.MutedUntil).Minutes()))
+	t.bot.SendMessage(tgbotapi.NewMessage(
+		update.Message.Chat.ID,
+		fmt.Sprintf("Вы отправляете слишком много сообщений. Пожалуйста, подождите %d мин., сообщения за это время не будут доставлены редакции.", minutes),
+	))
+
+	if decision.Trips >= t.cfg.Bot.RateLimit.WarnAfterTrips {
+		source := fmt.Sprintf("%s (ID %d)", t.cardHeader(ctx, update.Message.Chat), update.Message.From.ID)
+		if t.anonymity.Enabled() {
+			// telegram ID раскрывает источник так же, как имя
+			source = t.cardHeader(ctx, update.Message.Chat)
+		}
+		_, err := t.bot.SendMessageToAdmin(fmt.Sprintf(
+			"⚠️ Флуд: пользователь %s превысил лимит сообщений %d раз за %s и заглушен до %s",
+			source,
+			decision.Trips,
+			t.cfg.Bot.RateLimit.TripWindow,
+			decision.MutedUntil.Format("15:04"),
+		))
+		if err != nil {
+			t.logger.Error(fmt.Sprintf("%s", err))
+		}
+	}
+
+	return false
+}
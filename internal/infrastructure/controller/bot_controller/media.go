@@ -0,0 +1,98 @@
+package bot_controller
+
+import (
+	"context"
+	"fmt"
+	"medrussia_news_bot/internal/infrastructure/controller/bot_controller/dto"
+	"medrussia_news_bot/internal/infrastructure/repo"
+	"medrussia_news_bot/internal/pkg/telegram"
+)
+
+// forwardMediaToAdmin пересылка вложения пользователя в чат админов, header - шапка вместе
+// с пометками о файле из cardHeading. Возвращает ID всех отправленных сообщений, последнее из них - карточка с клавиатурой
+func (t TelegramWebhookController) forwardMediaToAdmin(
+	ctx context.Context,
+	user *repo.UserDialog,
+	card telegram.CardState,
+	header string,
+	tgMessage dto.MessageDTO,
+	media dto.Media,
+	received receivedFile,
+) (messageIDs []int64, err error) {
+	replyTo := user.LastAdminMessageID.Int64
+	caption := header
+	if tgMessage.Caption != "" {
+		caption = fmt.Sprintf("%s\n\nПодпись: %s", header, tgMessage.Caption)
+	}
+
+	var messageID int64
+	switch media.Kind {
+	case dto.MediaKindPhoto:
+		messageID, err = t.bot.SendPhotoToAdminChat(replyTo, card, media.FileID, caption)
+	case dto.MediaKindVideo:
+		messageID, err = t.bot.SendVideoToAdminChat(replyTo, card, media.FileID, caption)
+	case dto.MediaKindVoice:
+		messageID, err = t.bot.SendVoiceToAdminChat(replyTo, card, media.FileID, caption)
+	case dto.MediaKindAudio:
+		messageID, err = t.bot.SendAudioToAdminChat(replyTo, card, media.FileID, caption)
+	case dto.MediaKindDocument:
+		if received.held() {
+			// вместо документа - карточка с пометкой, оригинал привязывается к ней для /original
+			messageID, err = t.bot.ForwardMessageToAdminChat(replyTo, card, caption)
+			if err == nil {
+				t.sanitizer.LinkMessage(ctx, received.cleaned, messageID)
+			}
+			break
+		}
+		if received.cleaned == nil {
+			messageID, err = t.bot.SendDocumentToAdminChat(replyTo, card, media.FileID, caption)
+			break
+		}
+		messageID, err = t.bot.SendDocumentBytesToAdminChat(replyTo, card, received.fileName, received.cleaned.Data, caption)
+		if err == nil {
+			t.sanitizer.LinkMessage(ctx, received.cleaned, messageID)
+		}
+	case dto.MediaKindAnimation:
+		messageID, err = t.bot.SendAnimationToAdminChat(replyTo, card, media.FileID, caption)
+	default:
+		return t.forwardUncaptionedMediaToAdmin(card, header, tgMessage, media)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return []int64{messageID}, nil
+}
+
+// forwardUncaptionedMediaToAdmin у стикеров, кружков и контактов нет подписи,
+// поэтому сначала отправляем шапку, а вложение с клавиатурой - ответом на нее
+func (t TelegramWebhookController) forwardUncaptionedMediaToAdmin(
+	card telegram.CardState,
+	header string,
+	tgMessage dto.MessageDTO,
+	media dto.Media,
+) (messageIDs []int64, err error) {
+	headerMessageID, err := t.bot.SendMessageToAdmin(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var messageID int64
+	switch media.Kind {
+	case dto.MediaKindVideoNote:
+		messageID, err = t.bot.SendVideoNoteToAdminChat(headerMessageID, card, media.FileID)
+	case dto.MediaKindSticker:
+		messageID, err = t.bot.SendStickerToAdminChat(headerMessageID, card, media.FileID)
+	case dto.MediaKindContact:
+		messageID, err = t.bot.SendContactToAdminChat(
+			headerMessageID, card, tgMessage.Contact.PhoneNumber, tgMessage.Contact.FirstName, tgMessage.Contact.LastName,
+		)
+	default:
+		err = fmt.Errorf("unsupported media kind: %s", media.Kind)
+	}
+	if err != nil {
+		return []int64{headerMessageID}, err
+	}
+
+	return []int64{headerMessageID, messageID}, nil
+}
@@ -0,0 +1,72 @@
+package bot_controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"medrussia_news_bot/internal/infrastructure/repo"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// saveMessageLinks связываем сообщения в чате админов с сообщением пользователя
+func (t TelegramWebhookController) saveMessageLinks(
+	ctx context.Context,
+	adminMessageIDs []int64,
+	userID, userMessageID, appealID int64,
+) {
+	for _, adminMessageID := range adminMessageIDs {
+		t.saveMessageLink(ctx, repo.MessageLink{
+			AdminChatMessageID: adminMessageID,
+			UserID:             userID,
+			UserMessageID:      userMessageID,
+			AppealID:           nullInt64(appealID),
+			Direction:          repo.DirectionUserToAdmin,
+		})
+	}
+}
+
+// saveMessageLink сохранение связи сообщения в чате админов с сообщением пользователя
+func (t TelegramWebhookController) saveMessageLink(ctx context.Context, link repo.MessageLink) {
+	err := t.repo.SaveMessageLink(ctx, link)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("Ошибка сохранения связи сообщений: %s", err))
+	}
+}
+
+// resolveReplyLink определяем, к какому пользователю и обращению относится сообщение в чате админов.
+// Связи admin_to_user тоже возвращаются: по ним команды находят обращение, но пересылать
+// по ним нельзя. Для карточек, отправленных до появления связей, разбираем клавиатуру
+func (t TelegramWebhookController) resolveReplyLink(ctx context.Context, replyTo *tgbotapi.Message) *repo.MessageLink {
+	link, err := t.repo.GetMessageLink(ctx, int64(replyTo.MessageID))
+	if err == nil {
+		return link
+	}
+	if !errors.Is(err, repo.ErrMessageLinkNotFound) {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return nil
+	}
+
+	if replyTo.ReplyMarkup == nil {
+		return nil
+	}
+
+	data, ok := t.parseReplyCloseKeyboard(replyTo)
+	if !ok {
+		return nil
+	}
+	appeal, err := t.callbackAppeal(ctx, data)
+	if err != nil {
+		if !errors.Is(err, repo.ErrAppealNotFound) {
+			t.logger.Error(fmt.Sprintf("%s", err))
+		}
+		return nil
+	}
+
+	return &repo.MessageLink{
+		AdminChatMessageID: int64(replyTo.MessageID),
+		UserID:             appeal.UserID,
+		AppealID:           nullInt64(appeal.ID),
+		Direction:          repo.DirectionUserToAdmin,
+	}
+}
@@ -0,0 +1,49 @@
+package bot_controller
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"medrussia_news_bot/internal/infrastructure/controller/bot_controller/dto"
+	"medrussia_news_bot/internal/infrastructure/repo"
+)
+
+// newLogMessage запись журнала переписки по сообщению из телеграма
+func newLogMessage(direction string, appealID, userID, authorID int64, tgMessage dto.MessageDTO) repo.Message {
+	message := repo.Message{
+		AppealID:     nullInt64(appealID),
+		UserID:       userID,
+		Direction:    direction,
+		AuthorID:     authorID,
+		Text:         nullString(tgMessage.Text),
+		Caption:      nullString(tgMessage.Caption),
+		MediaGroupID: nullString(tgMessage.MediaGroupID),
+	}
+
+	if media, ok := tgMessage.Media(); ok {
+		message.MediaKind = nullString(media.Kind)
+		message.FileID = nullString(media.FileID)
+		message.FileUniqueID = nullString(media.FileUniqueID)
+	}
+	if tgMessage.Contact != nil {
+		message.Text = nullString(tgMessage.Contact.PhoneNumber)
+	}
+
+	return message
+}
+
+// saveLogMessage сохранение сообщения в журнал переписки
+func (t TelegramWebhookController) saveLogMessage(ctx context.Context, message repo.Message) {
+	_, err := t.repo.SaveMessage(ctx, message)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("Ошибка сохранения сообщения в журнал: %s", err))
+	}
+}
+
+func nullInt64(value int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: value, Valid: value != 0}
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
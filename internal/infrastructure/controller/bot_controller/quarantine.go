@@ -0,0 +1,283 @@
+package bot_controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"medrussia_news_bot/internal/infrastructure/repo"
+	"medrussia_news_bot/internal/pkg/callback"
+	"medrussia_news_bot/internal/pkg/telegram"
+	"medrussia_news_bot/internal/service/audit"
+	"medrussia_news_bot/internal/service/spam"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// quarantineListLimit сколько сообщений из карантина показывает /quarantine
+	quarantineListLimit = 10
+	// quarantinePreviewLen длина текста сообщения в карточке карантина
+	quarantinePreviewLen = 500
+)
+
+// quarantineSpam проверка сообщения фильтром спама, подозрительное сообщение уходит в карантин.
+// Если карантин недоступен, сообщение пересылается редакции как обычно
+func (t TelegramWebhookController) quarantineSpam(ctx context.Context, update tgbotapi.Update) bool {
+	return t.quarantineUpdates(ctx, []tgbotapi.Update{update}, spamMessage(update.Message))
+}
+
+// quarantineAlbum проверка альбома целиком по объединенным подписям: при спаме в карантин уходят все вложения
+func (t TelegramWebhookController) quarantineAlbum(ctx context.Context, updates []tgbotapi.Update) bool {
+	message := spamMessage(updates[0].Message)
+	texts := []string{message.Text}
+	for _, update := range updates[1:] {
+		part := spamMessage(update.Message)
+		if part.Text != "" {
+			texts = append(texts, part.Text)
+		}
+		message.Links += part.Links
+		message.ForwardedFromChannel = message.ForwardedFromChannel || part.ForwardedFromChannel
+	}
+	message.Text = strings.TrimSpace(strings.Join(texts, "\n"))
+
+	return t.quarantineUpdates(ctx, updates, message)
+}
+
+// quarantineUpdates помещает сообщения в карантин, если фильтр признал их спамом, и сообщает об этом редакции
+func (t TelegramWebhookController) quarantineUpdates(ctx context.Context, updates []tgbotapi.Update, message spam.Message) bool {
+	verdict := t.spam.Check(ctx, message)
+	if !verdict.Spam {
+		return false
+	}
+
+	ids := make([]int64, 0, len(updates))
+	for _, update := range updates {
+		rawUpdate, err := json.Marshal(update)
+		if err != nil {
+			t.logger.Error(fmt.Sprintf("%s", err))
+			continue
+		}
+
+		id, err := t.repo.AddQuarantineItem(ctx, repo.QuarantineItem{
+			UserID:        update.Message.From.ID,
+			UserMessageID: int64(update.Message.MessageID),
+			RawUpdate:     rawUpdate,
+			Preview:       quarantinePreview(update.Message),
+			Score:         verdict.Score,
+			Reasons:       verdict.Reason(),
+		})
+		if err != nil {
+			t.logger.Error(fmt.Sprintf("%s", err))
+			continue
+		}
+		ids = append(ids, id)
+	}
+	// ничего не сохранили - лучше переслать редакции, чем потерять обращение
+	if len(ids) == 0 {
+		return false
+	}
+	if len(ids) < len(updates) {
+		t.alerts.Error("quarantine", fmt.Sprintf("В карантин помещено только %d из %d вложений альбома от %s",
+			len(ids), len(updates), t.sourceName(message.UserID)))
+	}
+
+	t.logger.Info(fmt.Sprintf("Сообщение от %s помещено в карантин %v: %s", t.sourceName(message.UserID), ids, verdict.Reason()))
+	t.notifyQuarantine(ctx, message.UserID, ids, verdict)
+
+	return true
+}
+
+// notifyQuarantine короткое уведомление в чат редакции: ложное срабатывание фильтра не должно оставаться незамеченным
+func (t TelegramWebhookController) notifyQuarantine(ctx context.Context, userID int64, ids []int64, verdict spam.Verdict) {
+	pending, err := t.repo.CountPendingQuarantine(ctx)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+	}
+
+	text := fmt.Sprintf("🛡 В карантин #%d от %s: %s (очки %.1f)", ids[0], t.sourceName(userID), verdict.Reason(), verdict.Score)
+	if len(ids) > 1 {
+		text = fmt.Sprintf("🛡 В карантин #%d–#%d (альбом, %d вложений) от %s: %s (очки %.1f)",
+			ids[0], ids[len(ids)-1], len(ids), t.sourceName(userID), verdict.Reason(), verdict.Score)
+	}
+	if pending > 0 {
+		text += fmt.Sprintf("\nОжидают решения: %d, посмотреть: /quarantine", pending)
+	}
+
+	if _, err = t.bot.SendMessageToAdmin(text); err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+	}
+}
+
+// spamMessage данные сообщения для фильтра спама
+func spamMessage(msg *tgbotapi.Message) spam.Message {
+	text, entities := msg.Text, msg.Entities
+	if text == "" {
+		text, entities = msg.Caption, msg.CaptionEntities
+	}
+
+	links := 0
+	for _, entity := range entities {
+		if entity.Type == "url" || entity.Type == "text_link" {
+			links++
+		}
+	}
+
+	return spam.Message{
+		UserID:               msg.From.ID,
+		Text:                 text,
+		Links:                links,
+		ForwardedFromChannel: msg.ForwardFromChat != nil && msg.ForwardFromChat.IsChannel(),
+		ReceivedAt:           msg.Time(),
+	}
+}
+
+// quarantinePreview краткое содержание сообщения для карточки карантина
+func quarantinePreview(msg *tgbotapi.Message) string {
+	text := msg.Text
+	if text == "" {
+		text = msg.Caption
+	}
+
+	runes := []rune(text)
+	if len(runes) > quarantinePreviewLen {
+		text = string(runes[:quarantinePreviewLen]) + "…"
+	}
+
+	return fmt.Sprintf("[%s] %s", telegram.MessageKindName(msg), text)
+}
+
+// processQuarantineCommand /quarantine показывает нерассмотренные сообщения из карантина
+func (t TelegramWebhookController) processQuarantineCommand(ctx context.Context, update tgbotapi.Update) {
+	count, err := t.repo.CountPendingQuarantine(ctx)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		t.replyToAdmin(update, "Ошибка при получении карантина")
+		return
+	}
+	if count == 0 {
+		t.replyToAdmin(update, "Карантин пуст")
+		return
+	}
+
+	items, err := t.repo.ListPendingQuarantine(ctx, quarantineListLimit)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		t.replyToAdmin(update, "Ошибка при получении карантина")
+		return
+	}
+
+	t.replyToAdmin(update, fmt.Sprintf("В карантине %d сообщений, показаны первые %d", count, len(items)))
+	for _, item := range items {
+		_, err = t.bot.SendQuarantineCard(item.ID, t.quarantineCardText(ctx, item))
+		if err != nil {
+			t.logger.Error(fmt.Sprintf("%s", err))
+		}
+	}
+}
+
+// quarantineCardText текст карточки сообщения из карантина
+func (t TelegramWebhookController) quarantineCardText(ctx context.Context, item repo.QuarantineItem) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🛡 Карантин #%d\n", item.ID)
+
+	var update tgbotapi.Update
+	if err := json.Unmarshal(item.RawUpdate, &update); err == nil && update.Message != nil {
+		b.WriteString(t.cardHeader(ctx, update.Message.Chat))
+	} else {
+		b.WriteString(upperFirst(t.sourceName(item.UserID)))
+	}
+
+	fmt.Fprintf(&b, "\nПолучено: %s\nОчки: %.1f (%s)\n\n%s",
+		item.CreatedAt.Format("02.01.2006 15:04"), item.Score, item.Reasons, item.Preview)
+
+	return b.String()
+}
+
+// reviewQuarantine решение редактора по сообщению из карантина
+func (t TelegramWebhookController) reviewQuarantine(
+	ctx context.Context,
+	update tgbotapi.Update,
+	data callback.Data,
+	released bool,
+) (*repo.QuarantineItem, *callbackAnswer) {
+	id := data.Arg(0)
+	err := t.repo.ReviewQuarantineItem(ctx, id, update.CallbackQuery.From.ID, released)
+	switch {
+	case errors.Is(err, repo.ErrQuarantineNotFound):
+		return nil, &callbackAnswer{Text: "Сообщение не найдено в карантине", Alert: true}
+	case errors.Is(err, repo.ErrQuarantineReviewed):
+		return nil, &callbackAnswer{Text: "По этому сообщению уже принято решение", Alert: true}
+	case err != nil:
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return nil, &callbackAnswer{Text: "Ошибка при изменении карантина", Alert: true}
+	}
+
+	item, err := t.repo.GetQuarantineItem(ctx, id)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return nil, &callbackAnswer{Text: "Ошибка при изменении карантина", Alert: true}
+	}
+
+	return item, nil
+}
+
+// processReleaseCallback кнопка «Не спам»: сообщение пересылается редакции как обычное обращение
+func (t TelegramWebhookController) processReleaseCallback(ctx context.Context, update tgbotapi.Update, data callback.Data) callbackAnswer {
+	item, answer := t.reviewQuarantine(ctx, update, data, true)
+	if answer != nil {
+		return *answer
+	}
+
+	var userUpdate tgbotapi.Update
+	if err := json.Unmarshal(item.RawUpdate, &userUpdate); err != nil || userUpdate.Message == nil {
+		t.logger.Error(fmt.Sprintf("Не удалось разобрать сообщение из карантина #%d: %v", item.ID, err))
+		return callbackAnswer{Text: "Не удалось восстановить сообщение", Alert: true}
+	}
+
+	user, appeal, ok := t.openDialog(ctx, userUpdate)
+	if !ok {
+		return callbackAnswer{Text: "Ошибка при открытии обращения", Alert: true}
+	}
+	// в описи материалов время получения - когда сообщение пришло, а не когда его выпустили из карантина
+	tgMessage := t.getMessageFromWebhook(userUpdate)
+	tgMessage.ReceivedAt = item.CreatedAt
+	t.forwardToAdmin(ctx, user, appeal, userUpdate, tgMessage)
+
+	t.audit.Record(ctx, update.CallbackQuery.From.ID, audit.ActionRelease, appeal.ID, audit.Payload{
+		"quarantine_id": item.ID,
+		"user_id":       item.UserID,
+	})
+	t.markQuarantineCard(update, "✅ Не спам, передано редакции")
+
+	return callbackAnswer{Text: "Сообщение передано редакции"}
+}
+
+// processDiscardCallback кнопка «Удалить»: сообщение остается в карантине как спам
+func (t TelegramWebhookController) processDiscardCallback(ctx context.Context, update tgbotapi.Update, data callback.Data) callbackAnswer {
+	item, answer := t.reviewQuarantine(ctx, update, data, false)
+	if answer != nil {
+		return *answer
+	}
+
+	t.audit.Record(ctx, update.CallbackQuery.From.ID, audit.ActionDiscard, 0, audit.Payload{
+		"quarantine_id": item.ID,
+		"user_id":       item.UserID,
+	})
+	t.markQuarantineCard(update, "🗑 Спам, удалено")
+
+	return callbackAnswer{Text: "Сообщение удалено"}
+}
+
+// markQuarantineCard заменяет кнопки карточки карантина отметкой о решении
+func (t TelegramWebhookController) markQuarantineCard(update tgbotapi.Update, text string) {
+	if update.CallbackQuery.Message == nil {
+		return
+	}
+
+	_, err := t.bot.MarkCardInAdminChat(int64(update.CallbackQuery.Message.MessageID), text)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+	}
+}
@@ -0,0 +1,297 @@
+package bot_controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"medrussia_news_bot/internal/infrastructure/repo"
+	"medrussia_news_bot/internal/pkg/callback"
+	"medrussia_news_bot/internal/pkg/regions"
+	"medrussia_news_bot/internal/pkg/telegram"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	chooseRegionMessage = "Укажите, пожалуйста, ваш регион: выберите первую букву названия или отправьте геопозицию 📎"
+	// locationRegionPrefix начало сообщений о регионе по геопозиции, после выбора региона заменяется итогом
+	locationRegionPrefix = "📍 По геопозиции"
+	// regionAppealsLimit сколько обращений показывает /region
+	regionAppealsLimit = 20
+	// regionStatsDays период /regions по умолчанию
+	regionStatsDays = 30
+)
+
+// regionName название региона обращения, пустое если регион не указан
+func regionName(appeal *repo.Appeal) string {
+	if !appeal.RegionCode.Valid {
+		return ""
+	}
+
+	region, ok := regions.ByCode(appeal.RegionCode.Int64)
+	if !ok {
+		return strconv.FormatInt(appeal.RegionCode.Int64, 10)
+	}
+
+	return region.Name
+}
+
+// offerRegions сообщение пользователю с клавиатурой выбора региона
+func (t TelegramWebhookController) offerRegions(chatID int64, text string) {
+	_, err := t.bot.SendRegionChoice(chatID, text, regions.Letters())
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+	}
+}
+
+// applyPendingRegion переносит регион, выбранный до первого сообщения, на только что открытое обращение
+func (t TelegramWebhookController) applyPendingRegion(ctx context.Context, appeal *repo.Appeal) *repo.Appeal {
+	if appeal.RegionCode.Valid {
+		return appeal
+	}
+
+	applied, err := t.repo.ApplyPendingRegion(ctx, appeal.UserID, appeal.ID)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return appeal
+	}
+	if !applied {
+		return appeal
+	}
+
+	updated, err := t.repo.GetAppeal(ctx, appeal.ID)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return appeal
+	}
+
+	return updated
+}
+
+// setUserRegion регион ставится на незакрытое обращение пользователя, а если его еще нет - на следующее
+func (t TelegramWebhookController) setUserRegion(ctx context.Context, userID, regionCode int64) error {
+	appeal, err := t.repo.GetOpenAppeal(ctx, userID)
+	if errors.Is(err, repo.ErrAppealNotFound) {
+		return t.repo.SetPendingRegion(ctx, userID, regionCode)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = t.repo.SetAppealRegion(ctx, appeal.ID, regionCode); err != nil {
+		return err
+	}
+	appeal.RegionCode.Int64, appeal.RegionCode.Valid = regionCode, true
+	t.renderActiveCard(ctx, appeal)
+
+	return nil
+}
+
+// userChoiceMessage сообщение с клавиатурой выбора из личного чата пользователя
+func userChoiceMessage(update tgbotapi.Update) (*tgbotapi.Message, bool) {
+	message := update.CallbackQuery.Message
+	if message == nil || message.Chat == nil || !message.Chat.IsPrivate() {
+		return nil, false
+	}
+
+	return message, true
+}
+
+// processRegionLetterCallback список регионов на выбранную букву
+func (t TelegramWebhookController) processRegionLetterCallback(_ context.Context, update tgbotapi.Update, data callback.Data) callbackAnswer {
+	message, ok := userChoiceMessage(update)
+	if !ok {
+		return callbackAnswer{}
+	}
+
+	letters := regions.Letters()
+	index := data.Arg(0)
+	if index < 0 || index >= int64(len(letters)) {
+		return callbackAnswer{Text: "Кнопка устарела, выберите букву еще раз", Alert: true}
+	}
+
+	found := regions.ByLetter(letters[index])
+	options := make([]telegram.RegionOption, 0, len(found))
+	for _, region := range found {
+		options = append(options, telegram.RegionOption{Code: region.Code, Name: region.Name})
+	}
+
+	if err := t.bot.ShowRegionOptions(message.Chat.ID, int64(message.MessageID), options); err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+	}
+
+	return callbackAnswer{}
+}
+
+// processRegionBackCallback возврат к выбору первой буквы региона
+func (t TelegramWebhookController) processRegionBackCallback(_ context.Context, update tgbotapi.Update, _ callback.Data) callbackAnswer {
+	message, ok := userChoiceMessage(update)
+	if !ok {
+		return callbackAnswer{}
+	}
+
+	if err := t.bot.ShowRegionLetters(message.Chat.ID, int64(message.MessageID), regions.Letters()); err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+	}
+
+	return callbackAnswer{}
+}
+
+// processUserRegionCallback выбор региона пользователем
+func (t TelegramWebhookController) processUserRegionCallback(ctx context.Context, update tgbotapi.Update, data callback.Data) callbackAnswer {
+	message, ok := userChoiceMessage(update)
+	if !ok {
+		return callbackAnswer{}
+	}
+
+	region, ok := regions.ByCode(data.Arg(0))
+	if !ok {
+		return callbackAnswer{Text: "Регион не найден", Alert: true}
+	}
+
+	if err := t.setUserRegion(ctx, update.CallbackQuery.From.ID, region.Code); err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return callbackAnswer{Text: "Не удалось выбрать регион", Alert: true}
+	}
+
+	prompt := strings.TrimSuffix(message.Text, chooseRegionMessage)
+	if strings.HasPrefix(prompt, locationRegionPrefix) {
+		prompt = ""
+	}
+	text := fmt.Sprintf("%s\n\n📍 Регион: %s", prompt, region.Name)
+	if err := t.bot.ConfirmUserChoice(message.Chat.ID, int64(message.MessageID), strings.TrimSpace(text)); err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+	}
+
+	return callbackAnswer{Text: "Спасибо!"}
+}
+
+// processUserLocation для обращения без региона предлагает регион по присланной геопозиции.
+// Ближайший административный центр не всегда означает регион, поэтому регион ставится только после подтверждения
+func (t TelegramWebhookController) processUserLocation(update tgbotapi.Update, appeal *repo.Appeal) {
+	location := update.Message.Location
+	if location == nil || appeal.RegionCode.Valid {
+		return
+	}
+
+	chatID := update.Message.Chat.ID
+	region, ok := regions.Nearest(location.Latitude, location.Longitude)
+	if !ok {
+		t.offerRegions(chatID, locationRegionPrefix+" регион определить не удалось. "+chooseRegionMessage)
+		return
+	}
+
+	text := fmt.Sprintf("%s похоже на регион «%s». Подтвердите или выберите другой", locationRegionPrefix, region.Name)
+	_, err := t.bot.SendRegionConfirm(chatID, text, telegram.RegionOption{Code: region.Code, Name: region.Name})
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+	}
+}
+
+// processRegionsCommand /regions [дней] количество обращений по регионам
+func (t TelegramWebhookController) processRegionsCommand(ctx context.Context, update tgbotapi.Update) {
+	days := regionStatsDays
+	if arg := strings.TrimSpace(update.Message.CommandArguments()); arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n <= 0 {
+			t.replyToAdmin(update, "Укажите период в днях: /regions 30")
+			return
+		}
+		days = n
+	}
+
+	stats, err := t.repo.RegionStats(ctx, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		t.replyToAdmin(update, "Ошибка при получении статистики")
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Обращения по регионам за %d дн. (всего / не закрыто):\n", days)
+	if len(stats) == 0 {
+		b.WriteString("\nобращений нет")
+	}
+	for _, stat := range stats {
+		name := "Регион не указан"
+		if region, ok := regions.ByCode(stat.RegionCode); ok {
+			name = region.Name
+		}
+		fmt.Fprintf(&b, "\n%s: %d / %d", name, stat.Total, stat.Open)
+	}
+
+	t.replyToAdmin(update, b.String())
+}
+
+// processRegionAppealsCommand /region <код или часть названия> [open] последние обращения из региона
+func (t TelegramWebhookController) processRegionAppealsCommand(ctx context.Context, update tgbotapi.Update) {
+	args := strings.Fields(update.Message.CommandArguments())
+	onlyOpen := len(args) > 1 && args[len(args)-1] == "open"
+	if onlyOpen {
+		args = args[:len(args)-1]
+	}
+	if len(args) == 0 {
+		t.replyToAdmin(update, "Использование: /region <код или часть названия> [open]")
+		return
+	}
+
+	region, err := findRegion(strings.Join(args, " "))
+	if err != nil {
+		t.replyToAdmin(update, err.Error())
+		return
+	}
+
+	appeals, err := t.repo.ListRegionAppeals(ctx, region.Code, onlyOpen, regionAppealsLimit)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		t.replyToAdmin(update, "Ошибка при получении обращений")
+		return
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Обращения из региона «%s»", region.Name)
+	if onlyOpen {
+		b.WriteString(", только незакрытые")
+	}
+	fmt.Fprintf(&b, ": %d\n", len(appeals))
+	for _, appeal := range appeals {
+		fmt.Fprintf(&b, "\n#%d — %s, %s, открыто %s",
+			appeal.ID, appealStatusNames[appeal.Status], t.sourceName(appeal.UserID), appeal.OpenedAt.Format("02.01.2006 15:04"))
+		if appeal.Urgent {
+			b.WriteString(" 🔥")
+		}
+	}
+
+	t.replyToAdmin(update, b.String())
+}
+
+// findRegion регион по коду или однозначной части названия, ошибка содержит текст для чата
+func findRegion(query string) (regions.Region, error) {
+	if code, err := strconv.ParseInt(query, 10, 64); err == nil {
+		region, ok := regions.ByCode(code)
+		if !ok {
+			return regions.Region{}, fmt.Errorf("Регион с кодом %d не найден", code)
+		}
+		return region, nil
+	}
+
+	found := regions.Search(query)
+	switch {
+	case len(found) == 0:
+		return regions.Region{}, fmt.Errorf("Регион «%s» не найден", query)
+	case len(found) > 1:
+		names := make([]string, 0, len(found))
+		for _, region := range found {
+			if strings.EqualFold(region.Name, query) {
+				return region, nil
+			}
+			names = append(names, fmt.Sprintf("%s (%d)", region.Name, region.Code))
+		}
+		return regions.Region{}, fmt.Errorf("Уточните регион: %s", strings.Join(names, ", "))
+	}
+
+	return found[0], nil
+}
@@ -0,0 +1,77 @@
+package bot_controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"medrussia_news_bot/internal/infrastructure/repo"
+	"medrussia_news_bot/internal/service/access"
+	"medrussia_news_bot/internal/service/audit"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const permissionDeniedMessage = "Недостаточно прав для этого действия"
+
+// processRoleCommand /role @username [editor|viewer] показывает или меняет роль участника чата админов
+func (t TelegramWebhookController) processRoleCommand(ctx context.Context, update tgbotapi.Update) {
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 || len(args) > 2 {
+		t.replyToAdmin(update, "Использование: /role @username [editor|viewer]")
+		return
+	}
+
+	tgID, name, err := t.roleTarget(ctx, args[0])
+	if err != nil {
+		if errors.Is(err, repo.ErrEditorNotFound) {
+			t.replyToAdmin(update, "Редактор не найден: он должен хотя бы раз написать в этот чат")
+			return
+		}
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return
+	}
+
+	if len(args) == 1 {
+		t.replyToAdmin(update, fmt.Sprintf("%s: %s", name, t.access.Role(ctx, tgID).Title()))
+		return
+	}
+
+	role, err := access.ParseRole(args[1])
+	if err != nil {
+		t.replyToAdmin(update, "Неизвестная роль, доступны: editor, viewer")
+		return
+	}
+
+	err = t.access.SetRole(ctx, tgID, role, update.Message.From.ID)
+	if err != nil {
+		if errors.Is(err, access.ErrConfigRole) {
+			t.replyToAdmin(update, "Суперадмины задаются только в конфиге")
+			return
+		}
+		t.logger.Error(fmt.Sprintf("%s", err))
+		t.replyToAdmin(update, "Ошибка при смене роли")
+		return
+	}
+
+	t.audit.Record(ctx, update.Message.From.ID, audit.ActionRole, 0, audit.Payload{
+		"tg_id": tgID,
+		"role":  role,
+	})
+	t.replyToAdmin(update, fmt.Sprintf("%s теперь %s", name, role.Title()))
+}
+
+// roleTarget участник чата админов из аргумента команды: @username или telegram ID
+func (t TelegramWebhookController) roleTarget(ctx context.Context, arg string) (int64, string, error) {
+	if tgID, err := strconv.ParseInt(arg, 10, 64); err == nil {
+		return tgID, t.editorName(ctx, tgID), nil
+	}
+
+	editor, err := t.repo.GetEditorByUsername(ctx, arg)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return editor.TgID, editor.DisplayName(), nil
+}
@@ -0,0 +1,189 @@
+package bot_controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"medrussia_news_bot/internal/infrastructure/controller/bot_controller/dto"
+	"medrussia_news_bot/internal/infrastructure/repo"
+	"medrussia_news_bot/internal/pkg/metadata"
+	"medrussia_news_bot/internal/pkg/telegram"
+	"medrussia_news_bot/internal/service/audit"
+	"medrussia_news_bot/internal/service/sanitize"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// formatExtensions расширение очищенной копии, если у документа нет имени
+var formatExtensions = map[metadata.Format]string{
+	metadata.FormatJPEG:  ".jpg",
+	metadata.FormatPNG:   ".png",
+	metadata.FormatHEIC:  ".heic",
+	metadata.FormatPDF:   ".pdf",
+	metadata.FormatOOXML: ".docx",
+}
+
+// sanitizeDocument очистка документа от метаданных перед пересылкой редакции. Фото, отправленные
+// не файлом, Telegram пережимает сам, поэтому проверяются только документы.
+// Возвращает очищенную копию или задержанный документ и пометку для подписи. Документ,
+// который не удалось проверить, пересылается как есть с пометкой, что метаданные не удалены
+func (t TelegramWebhookController) sanitizeDocument(
+	ctx context.Context,
+	appealID, userID int64,
+	media dto.Media,
+	data []byte,
+) (*sanitize.Cleaned, string) {
+	if media.Kind != dto.MediaKindDocument || !t.sanitizer.Enabled() {
+		return nil, ""
+	}
+
+	cleaned, err := t.sanitizer.Clean(ctx, sanitize.File{
+		AppealID:     appealID,
+		UserID:       userID,
+		FileID:       media.FileID,
+		FileUniqueID: media.FileUniqueID,
+		FileName:     media.FileName,
+		Data:         data,
+	})
+	if errors.Is(err, sanitize.ErrNothingRemoved) {
+		return nil, ""
+	}
+	if errors.Is(err, sanitize.ErrNotChecked) {
+		if !expectedHold(err) {
+			t.alerts.Warning("sanitize", fmt.Sprintf("Не удалось проверить документ в обращении #%d: %s", appealID, err))
+		}
+		return nil, "⚠️ Метаданные не удалены: " + holdReason(err, nil)
+	}
+	if err != nil {
+		t.alerts.Warning("sanitize", fmt.Sprintf("Не удалось сохранить оригинал документа в обращении #%d: %s", appealID, err))
+	}
+
+	if cleaned.Held != nil {
+		if !expectedHold(cleaned.Held) {
+			t.alerts.Warning("sanitize", fmt.Sprintf("Не удалось проверить документ в обращении #%d: %s", appealID, cleaned.Held))
+		}
+		return cleaned, heldNote(cleaned)
+	}
+
+	note := "🧹 Удалены метаданные: " + strings.Join(cleaned.Removed, ", ")
+	if cleaned.OriginalID != 0 {
+		note += fmt.Sprintf("\nОригинал №%d доступен суперадмину: /original %d", cleaned.OriginalID, cleaned.OriginalID)
+	}
+
+	return cleaned, note
+}
+
+// expectedHold причины задержки или пропуска проверки, о которых не нужно оповещать: формат или размер файла
+func expectedHold(reason error) bool {
+	return errors.Is(reason, metadata.ErrUnsupportedFormat) ||
+		errors.Is(reason, telegram.ErrFileTooLarge) ||
+		errors.Is(reason, sanitize.ErrMetadataKept)
+}
+
+// heldNote пометка задержанного документа для карточки в чате админов
+func heldNote(cleaned *sanitize.Cleaned) string {
+	note := "🔒 Документ не передан редакции: " + holdReason(cleaned.Held, cleaned.Kept)
+	if cleaned.OriginalID == 0 {
+		return note + "\n⚠️ Оригинал сохранить не удалось"
+	}
+
+	return note + fmt.Sprintf("\nОригинал №%d доступен суперадмину: /original %d", cleaned.OriginalID, cleaned.OriginalID)
+}
+
+// holdReason почему документ не очищен, для пометки на карточке. kept - метаданные, которые остались в файле
+func holdReason(err error, kept []string) string {
+	switch {
+	case errors.Is(err, metadata.ErrUnsupportedFormat):
+		return "формат не поддерживается"
+	case errors.Is(err, telegram.ErrFileTooLarge):
+		return "файл слишком большой для проверки"
+	case errors.Is(err, sanitize.ErrMetadataKept):
+		return "не удалось удалить " + strings.Join(kept, ", ")
+	case errors.Is(err, metadata.ErrCorrupted):
+		return "файл поврежден"
+	case errors.Is(err, sanitize.ErrNotChecked):
+		return "файл не удалось скачать для проверки"
+	}
+
+	return "не удалось обработать файл"
+}
+
+// cleanFileName имя очищенной копии. В режиме защиты источников исходное имя не показывается,
+// в нем бывают фамилии и даты
+func (t TelegramWebhookController) cleanFileName(appealID int64, media dto.Media, cleaned *sanitize.Cleaned) string {
+	ext := strings.ToLower(filepath.Ext(media.FileName))
+	if ext == "" {
+		ext = formatExtensions[cleaned.Format]
+	}
+	if media.FileName == "" || t.anonymity.Enabled() {
+		return fmt.Sprintf("appeal-%d%s", appealID, ext)
+	}
+
+	return media.FileName
+}
+
+// processOriginalCommand /original <№> или ответом на карточку документа: оригинал документа
+// до очистки. Оригинал уходит суперадмину в личные сообщения, выдача пишется в журнал аудита
+func (t TelegramWebhookController) processOriginalCommand(ctx context.Context, update tgbotapi.Update) {
+	var original *repo.MediaOriginal
+	var err error
+	if arg := strings.TrimPrefix(strings.TrimSpace(update.Message.CommandArguments()), "№"); arg != "" {
+		id, parseErr := strconv.ParseInt(arg, 10, 64)
+		if parseErr != nil {
+			t.replyToAdmin(update, "Укажите номер оригинала: /original 42")
+			return
+		}
+		original, err = t.repo.GetMediaOriginal(ctx, id)
+	} else if replyTo := update.Message.ReplyToMessage; replyTo != nil {
+		original, err = t.repo.GetMediaOriginalByMessage(ctx, int64(replyTo.MessageID))
+	} else {
+		t.replyToAdmin(update, "Отправьте /original <№> или /original ответом на документ пользователя")
+		return
+	}
+	if err != nil {
+		if errors.Is(err, repo.ErrMediaOriginalNotFound) {
+			t.replyToAdmin(update, "Оригинал не найден")
+			return
+		}
+		t.logger.Error(fmt.Sprintf("%s", err))
+		t.replyToAdmin(update, "Ошибка при получении оригинала")
+		return
+	}
+
+	var data []byte
+	if original.StoragePath != "" {
+		data, err = t.files.Get(ctx, original.StoragePath)
+		if err != nil {
+			t.logger.Error(fmt.Sprintf("%s", err))
+			t.replyToAdmin(update, "Не удалось получить оригинал из хранилища")
+			return
+		}
+	} else if !original.FileID.Valid {
+		t.replyToAdmin(update, "Оригинал не сохранен")
+		return
+	}
+
+	fileName := original.FileName.String
+	if fileName == "" {
+		fileName = fmt.Sprintf("original-%d%s", original.ID, formatExtensions[metadata.Format(original.Format)])
+	}
+	caption := fmt.Sprintf("Оригинал №%d из обращения #%d", original.ID, original.AppealID)
+	if original.HeldReason.Valid {
+		caption += "\nНе передан редакции: " + original.HeldReason.String
+	}
+
+	_, err = t.bot.SendFileToSuperAdmin(update.Message.From.ID, fileName, data, original.FileID.String, caption)
+	if err != nil {
+		t.replyToAdmin(update, "Не удалось отправить оригинал в личные сообщения: начните диалог с ботом и повторите команду")
+		return
+	}
+
+	t.audit.Record(ctx, update.Message.From.ID, audit.ActionExport, original.AppealID, audit.Payload{
+		"kind":        "original",
+		"original_id": original.ID,
+	})
+	t.replyToAdmin(update, "Оригинал отправлен вам в личные сообщения, выдача записана в журнал")
+}
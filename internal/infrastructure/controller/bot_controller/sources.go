@@ -0,0 +1,80 @@
+package bot_controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"medrussia_news_bot/internal/infrastructure/repo"
+	"medrussia_news_bot/internal/service/anonymity"
+	"medrussia_news_bot/internal/service/audit"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// processRevealCommand /reveal ответом на карточку или /reveal <псевдоним> раскрывает источник.
+// Данные уходят суперадмину в личные сообщения, чтобы не попасть в общий чат, а каждый просмотр
+// пишется в журнал аудита
+func (t TelegramWebhookController) processRevealCommand(ctx context.Context, update tgbotapi.Update) {
+	if !t.anonymity.Enabled() {
+		t.replyToAdmin(update, "Режим защиты источников выключен, данные пользователей видны на карточках")
+		return
+	}
+
+	var identity *repo.SourceIdentity
+	var appealID int64
+	var err error
+	if replyTo := update.Message.ReplyToMessage; replyTo != nil {
+		link := t.resolveReplyLink(ctx, replyTo)
+		if link == nil {
+			t.replyToAdmin(update, "Карточка обращения не найдена")
+			return
+		}
+		appealID = link.AppealID.Int64
+		identity, err = t.anonymity.RevealUser(ctx, link.UserID)
+	} else {
+		pseudonym := strings.TrimSpace(update.Message.CommandArguments())
+		if pseudonym == "" {
+			t.replyToAdmin(update, "Отправьте /reveal ответом на карточку или /reveal <псевдоним>, например /reveal A7F3C1B20D94")
+			return
+		}
+		identity, err = t.anonymity.Reveal(ctx, pseudonym)
+	}
+	if err != nil {
+		if errors.Is(err, repo.ErrSourceIdentityNotFound) {
+			t.replyToAdmin(update, "Данные источника не найдены")
+			return
+		}
+		if errors.Is(err, anonymity.ErrAmbiguousPseudonym) {
+			t.replyToAdmin(update, "Под этот псевдоним подходит несколько источников: укажите псевдоним полностью или отправьте /reveal ответом на карточку")
+			return
+		}
+		t.logger.Error(fmt.Sprintf("%s", err))
+		t.replyToAdmin(update, "Ошибка при получении данных источника")
+		return
+	}
+
+	_, err = t.bot.SendMessageToSuperAdmin(update.Message.From.ID, sourceIdentityText(t.anonymity.Pseudonym(identity.UserID), identity))
+	if err != nil {
+		t.replyToAdmin(update, "Не удалось отправить данные в личные сообщения: начните диалог с ботом и повторите команду")
+		return
+	}
+
+	t.audit.Record(ctx, update.Message.From.ID, audit.ActionReveal, appealID, audit.Payload{
+		"pseudonym": identity.Pseudonym,
+	})
+	t.replyToAdmin(update, "Данные источника отправлены вам в личные сообщения, просмотр записан в журнал")
+}
+
+// sourceIdentityText настоящие данные источника для суперадмина
+func sourceIdentityText(pseudonym string, identity *repo.SourceIdentity) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🔓 %s\nTelegram ID: %d", pseudonym, identity.UserID)
+	if identity.UserName.Valid {
+		fmt.Fprintf(&b, "\nUsername: @%s", identity.UserName.String)
+	}
+	fmt.Fprintf(&b, "\nИмя: %s %s", identity.LastName.String, identity.FirstName.String)
+	fmt.Fprintf(&b, "\nДанные обновлены: %s", identity.UpdatedAt.Format("02.01.2006 15:04"))
+
+	return b.String()
+}
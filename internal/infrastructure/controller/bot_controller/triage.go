@@ -0,0 +1,69 @@
+package bot_controller
+
+import (
+	"context"
+	"fmt"
+	"medrussia_news_bot/internal/infrastructure/repo"
+	"medrussia_news_bot/internal/pkg/telegram"
+	"medrussia_news_bot/internal/service/audit"
+	"strings"
+)
+
+// triageAppeal проверка сообщения правилами срочности. marker - отметка для шапки карточки,
+// escalated = true если обращение только что стало срочным и карточку нужно закрепить
+func (t TelegramWebhookController) triageAppeal(ctx context.Context, appeal *repo.Appeal, text string) (marker string, escalated bool) {
+	matched := t.triage.Match(text)
+	if len(matched) == 0 {
+		return "", false
+	}
+
+	marker = triageMarker(matched)
+	if appeal.Urgent {
+		return marker, false
+	}
+
+	err := t.repo.SetAppealUrgent(ctx, appeal.ID, true)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+		return marker, false
+	}
+	appeal.Urgent = true
+
+	// срочность выставил бот, а не редактор
+	t.recordAppealEvent(ctx, appeal.ID, audit.ActionUrgent, 0, audit.Payload{"auto": true, "keywords": matched})
+
+	return marker, true
+}
+
+// triageMarker отметка срочности над шапкой карточки, пустая если ключевых слов нет
+func triageMarker(matched []string) string {
+	if len(matched) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("🔥 СРОЧНО (%s)", strings.Join(matched, ", "))
+}
+
+// escalateCard закрепляет карточку срочного обращения и упоминает дежурных редакторов
+func (t TelegramWebhookController) escalateCard(ctx context.Context, appeal *repo.Appeal, cardMessageID int64) {
+	if t.cfg.Bot.Triage.Pin {
+		if err := t.bot.PinInAdminChat(cardMessageID); err != nil {
+			t.logger.Error(fmt.Sprintf("%s", err))
+		}
+	}
+
+	onCall := t.cfg.Bot.Triage.OnCall
+	if len(onCall) == 0 {
+		return
+	}
+
+	mentions := make([]telegram.Mention, 0, len(onCall))
+	for _, editorID := range onCall {
+		mentions = append(mentions, telegram.Mention{UserID: editorID, Name: t.editorName(ctx, editorID)})
+	}
+
+	_, err := t.bot.MentionInAdminChat(cardMessageID, fmt.Sprintf("🔥 Срочное обращение #%d", appeal.ID), mentions)
+	if err != nil {
+		t.logger.Error(fmt.Sprintf("%s", err))
+	}
+}
@@ -12,6 +12,12 @@ type BotController interface {
 	BotWebhookHandler(c *gin.Context)
 }
 
+// FilesController выдача файлов обращений из архива через API
+type FilesController interface {
+	AppealFilesHandler(c *gin.Context)
+	FileDownloadHandler(c *gin.Context)
+}
+
 type RestController struct {
 	router           *gin.Engine
 	cfg              *config.Config
@@ -23,11 +29,16 @@ func NewRestController(
 	cfg *config.Config,
 	logger *slog.Logger,
 	botApiController BotController,
+	filesController FilesController,
 ) *RestController {
 	router := gin.New()
 	router.Use(gin.Recovery())
 	router.POST("/"+cfg.Bot.Token+"/", botApiController.BotWebhookHandler)
 
+	api := router.Group("/api", gin.BasicAuth(gin.Accounts{cfg.HTTPServer.User: cfg.HTTPServer.Password}))
+	api.GET("/appeals/:id/files", filesController.AppealFilesHandler)
+	api.GET("/files/:id", filesController.FileDownloadHandler)
+
 	rest := &RestController{
 		router:           router,
 		cfg:              cfg,
@@ -0,0 +1,186 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Статусы обращения
+const (
+	AppealStatusOpen       = "open"
+	AppealStatusInProgress = "in_progress"
+	AppealStatusClosed     = "closed"
+	AppealStatusSpam       = "spam"
+)
+
+// uniqueViolationCode код ошибки postgres при нарушении уникального индекса
+const uniqueViolationCode = "23505"
+
+var (
+	// ErrAppealNotFound обращение не найдено
+	ErrAppealNotFound = errors.New("appeal not found")
+	// ErrAppealAlreadyOpen у пользователя уже есть незакрытое обращение
+	ErrAppealAlreadyOpen = errors.New("user already has an open appeal")
+)
+
+// Appeal представляет запись из таблицы appeals
+type Appeal struct {
+	ID         int64         `sql:"id"`
+	UserID     int64         `sql:"user_id"`
+	Status     string        `sql:"status"`
+	OpenedAt   time.Time     `sql:"opened_at"`
+	ClosedAt   sql.NullTime  `sql:"closed_at"`
+	ClosedBy   sql.NullInt64 `sql:"closed_by"`
+	Urgent     bool          `sql:"urgent"`
+	AssignedTo sql.NullInt64 `sql:"assigned_to"`
+	CategoryID sql.NullInt64 `sql:"category_id"`
+	RegionCode sql.NullInt64 `sql:"region_code"`
+}
+
+// IsClosed обращение закрыто, в том числе как спам
+func (a Appeal) IsClosed() bool {
+	return a.ClosedAt.Valid
+}
+
+const appealColumns = `id, user_id, status, opened_at, closed_at, closed_by, urgent, assigned_to, category_id, region_code`
+
+func scanAppeal(row pgx.Row) (*Appeal, error) {
+	var appeal Appeal
+	err := row.Scan(
+		&appeal.ID,
+		&appeal.UserID,
+		&appeal.Status,
+		&appeal.OpenedAt,
+		&appeal.ClosedAt,
+		&appeal.ClosedBy,
+		&appeal.Urgent,
+		&appeal.AssignedTo,
+		&appeal.CategoryID,
+		&appeal.RegionCode,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAppealNotFound
+		}
+		return nil, fmt.Errorf("failed to scan appeal: %w", err)
+	}
+
+	return &appeal, nil
+}
+
+// GetAppeal получает обращение по ID
+func (r *Repo) GetAppeal(ctx context.Context, appealID int64) (*Appeal, error) {
+	sql := `select ` + appealColumns + ` from appeals where id = $1`
+
+	return scanAppeal(r.client.QueryRow(ctx, sql, appealID))
+}
+
+// GetOpenAppeal получает незакрытое обращение пользователя
+func (r *Repo) GetOpenAppeal(ctx context.Context, userID int64) (*Appeal, error) {
+	sql := `select ` + appealColumns + ` from appeals where user_id = $1 and closed_at is null`
+
+	return scanAppeal(r.client.QueryRow(ctx, sql, userID))
+}
+
+// OpenAppeal открывает новое обращение, если у пользователя нет незакрытого, и возвращает текущее
+func (r *Repo) OpenAppeal(ctx context.Context, userID int64) (*Appeal, error) {
+	sql := `insert into appeals (user_id, status)
+				values ($1, $2)
+				on conflict (user_id) where closed_at is null do nothing
+				returning ` + appealColumns
+
+	appeal, err := scanAppeal(r.client.QueryRow(ctx, sql, userID, AppealStatusOpen))
+	if errors.Is(err, ErrAppealNotFound) {
+		// обращение уже открыто
+		return r.GetOpenAppeal(ctx, userID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open appeal: %w", err)
+	}
+
+	return appeal, nil
+}
+
+// ListAppeals получает все обращения пользователя, новые первыми
+func (r *Repo) ListAppeals(ctx context.Context, userID int64) ([]Appeal, error) {
+	sql := `select ` + appealColumns + ` from appeals where user_id = $1 order by opened_at desc`
+
+	rows, err := r.client.Query(ctx, sql, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list appeals: %w", err)
+	}
+	defer rows.Close()
+
+	appeals := make([]Appeal, 0)
+	for rows.Next() {
+		appeal, err := scanAppeal(rows)
+		if err != nil {
+			return nil, err
+		}
+		appeals = append(appeals, *appeal)
+	}
+
+	return appeals, rows.Err()
+}
+
+// AssignAppeal берет обращение в работу, ответственным становится редактор editorID
+func (r *Repo) AssignAppeal(ctx context.Context, appealID, editorID int64) error {
+	sql := `update appeals set status = $1, assigned_to = $2 where id = $3 and closed_at is null`
+
+	_, err := r.client.Exec(ctx, sql, AppealStatusInProgress, editorID, appealID)
+	if err != nil {
+		return fmt.Errorf("failed to assign appeal: %w", err)
+	}
+
+	return nil
+}
+
+// SetAppealUrgent отмечает обращение срочным или снимает отметку
+func (r *Repo) SetAppealUrgent(ctx context.Context, appealID int64, urgent bool) error {
+	sql := `update appeals set urgent = $1 where id = $2`
+
+	_, err := r.client.Exec(ctx, sql, urgent, appealID)
+	if err != nil {
+		return fmt.Errorf("failed to set appeal urgent: %w", err)
+	}
+
+	return nil
+}
+
+// ReopenAppeal переоткрывает закрытое обращение, карточка cardMessageID становится активной
+func (r *Repo) ReopenAppeal(ctx context.Context, appealID, cardMessageID int64) error {
+	tx, err := r.client.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var userID int64
+	sql := `update appeals set status = $1, closed_at = null, closed_by = null
+				where id = $2 and closed_at is not null
+				returning user_id`
+	err = tx.QueryRow(ctx, sql, AppealStatusOpen, appealID).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrAppealNotFound
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return ErrAppealAlreadyOpen
+		}
+		return fmt.Errorf("failed to reopen appeal: %w", err)
+	}
+
+	sql = `update users_dialog set available = true, last_user_message_id = $1 where user_id = $2`
+	if _, err = tx.Exec(ctx, sql, cardMessageID, userID); err != nil {
+		return fmt.Errorf("failed to update user dialog: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
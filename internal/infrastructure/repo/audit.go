@@ -0,0 +1,55 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// AuditRecord представляет запись из таблицы audit_log
+type AuditRecord struct {
+	ID        int64         `sql:"id"`
+	ActorID   int64         `sql:"actor_id"`
+	Action    string        `sql:"action"`
+	AppealID  sql.NullInt64 `sql:"appeal_id"`
+	Payload   []byte        `sql:"payload"`
+	CreatedAt time.Time     `sql:"created_at"`
+}
+
+// AddAuditRecord записывает действие в журнал аудита
+func (r *Repo) AddAuditRecord(ctx context.Context, record AuditRecord) error {
+	sql := `insert into audit_log (actor_id, action, appeal_id, payload) values ($1, $2, $3, $4)`
+
+	_, err := r.client.Exec(ctx, sql, record.ActorID, record.Action, record.AppealID, record.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to add audit record: %w", err)
+	}
+
+	return nil
+}
+
+// ListAuditRecords получает журнал аудита по обращению в хронологическом порядке
+func (r *Repo) ListAuditRecords(ctx context.Context, appealID int64) ([]AuditRecord, error) {
+	sql := `select id, actor_id, action, appeal_id, payload, created_at from audit_log
+				where appeal_id = $1
+				order by created_at, id`
+
+	rows, err := r.client.Query(ctx, sql, appealID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit records: %w", err)
+	}
+	defer rows.Close()
+
+	records := make([]AuditRecord, 0)
+	for rows.Next() {
+		var record AuditRecord
+		err = rows.Scan(&record.ID, &record.ActorID, &record.Action, &record.AppealID, &record.Payload, &record.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit record: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
@@ -0,0 +1,101 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrBanNotFound действующая блокировка не найдена
+var ErrBanNotFound = errors.New("ban not found")
+
+// Ban представляет запись из таблицы bans
+type Ban struct {
+	UserID     int64          `sql:"user_id"`
+	BannedBy   int64          `sql:"banned_by"`
+	Reason     sql.NullString `sql:"reason"`
+	ExpiresAt  sql.NullTime   `sql:"expires_at"`
+	NotifiedAt sql.NullTime   `sql:"notified_at"`
+	CreatedAt  time.Time      `sql:"created_at"`
+}
+
+// activeBanCondition блокировка бессрочная или еще не истекла
+const activeBanCondition = `(expires_at is null or expires_at > now())`
+
+// BanUser блокирует пользователя, повторная блокировка заменяет причину и срок
+func (r *Repo) BanUser(ctx context.Context, ban Ban) error {
+	sql := `insert into bans (user_id, banned_by, reason, expires_at)
+				values ($1, $2, $3, $4)
+				on conflict (user_id) do update
+				set banned_by = excluded.banned_by, reason = excluded.reason, expires_at = excluded.expires_at,
+				    notified_at = null, created_at = now()`
+
+	_, err := r.client.Exec(ctx, sql, ban.UserID, ban.BannedBy, ban.Reason, ban.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to ban user: %w", err)
+	}
+
+	return nil
+}
+
+// UnbanUser снимает блокировку пользователя
+func (r *Repo) UnbanUser(ctx context.Context, userID int64) error {
+	sql := `delete from bans where user_id = $1 and ` + activeBanCondition
+
+	tag, err := r.client.Exec(ctx, sql, userID)
+	if err != nil {
+		return fmt.Errorf("failed to unban user: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrBanNotFound
+	}
+
+	return nil
+}
+
+// GetActiveBan получает действующую блокировку пользователя
+func (r *Repo) GetActiveBan(ctx context.Context, userID int64) (*Ban, error) {
+	sql := `select user_id, banned_by, reason, expires_at, notified_at, created_at
+				from bans where user_id = $1 and ` + activeBanCondition
+
+	var ban Ban
+	err := r.client.QueryRow(ctx, sql, userID).
+		Scan(&ban.UserID, &ban.BannedBy, &ban.Reason, &ban.ExpiresAt, &ban.NotifiedAt, &ban.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrBanNotFound
+		}
+		return nil, fmt.Errorf("failed to get ban: %w", err)
+	}
+
+	return &ban, nil
+}
+
+// MarkBanNotified отмечает, что пользователю сообщили о блокировке
+func (r *Repo) MarkBanNotified(ctx context.Context, userID int64) error {
+	sql := `update bans set notified_at = now() where user_id = $1`
+
+	_, err := r.client.Exec(ctx, sql, userID)
+	if err != nil {
+		return fmt.Errorf("failed to mark ban notified: %w", err)
+	}
+
+	return nil
+}
+
+// IsUserBanned проверяет, заблокирован ли пользователь
+func (r *Repo) IsUserBanned(ctx context.Context, userID int64) (bool, error) {
+	sql := `select exists(select 1 from bans where user_id = $1 and ` + activeBanCondition + `)`
+
+	var banned bool
+	err := r.client.QueryRow(ctx, sql, userID).Scan(&banned)
+	if err != nil {
+		return false, fmt.Errorf("failed to check ban: %w", err)
+	}
+
+	return banned, nil
+}
@@ -0,0 +1,179 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrCategoryNotFound тема обращения не найдена
+var ErrCategoryNotFound = errors.New("category not found")
+
+// Category представляет запись из таблицы categories
+type Category struct {
+	ID    int64  `sql:"id"`
+	Code  string `sql:"code"`
+	Title string `sql:"title"`
+	Sort  int    `sql:"sort"`
+}
+
+// CategoryStat количество обращений по теме, Category невалидна для обращений без темы
+type CategoryStat struct {
+	CategoryID    sql.NullInt64
+	CategoryTitle sql.NullString
+	Total         int64
+	Open          int64
+}
+
+const categoryColumns = `id, code, title, sort`
+
+func scanCategory(row pgx.Row) (*Category, error) {
+	var category Category
+	err := row.Scan(&category.ID, &category.Code, &category.Title, &category.Sort)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, fmt.Errorf("failed to scan category: %w", err)
+	}
+
+	return &category, nil
+}
+
+// ListCategories получает действующие темы в порядке показа
+func (r *Repo) ListCategories(ctx context.Context) ([]Category, error) {
+	sql := `select ` + categoryColumns + ` from categories where active order by sort, id`
+
+	rows, err := r.client.Query(ctx, sql)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := make([]Category, 0)
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, *category)
+	}
+
+	return categories, rows.Err()
+}
+
+// GetCategory получает тему по ID
+func (r *Repo) GetCategory(ctx context.Context, categoryID int64) (*Category, error) {
+	sql := `select ` + categoryColumns + ` from categories where id = $1`
+
+	return scanCategory(r.client.QueryRow(ctx, sql, categoryID))
+}
+
+// GetCategoryByCode получает тему по коду
+func (r *Repo) GetCategoryByCode(ctx context.Context, code string) (*Category, error) {
+	sql := `select ` + categoryColumns + ` from categories where code = $1`
+
+	return scanCategory(r.client.QueryRow(ctx, sql, code))
+}
+
+// SetAppealCategory меняет тему обращения
+func (r *Repo) SetAppealCategory(ctx context.Context, appealID, categoryID int64) error {
+	sql := `update appeals set category_id = $1 where id = $2`
+
+	tag, err := r.client.Exec(ctx, sql, categoryID, appealID)
+	if err != nil {
+		return fmt.Errorf("failed to set appeal category: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAppealNotFound
+	}
+
+	return nil
+}
+
+// SetPendingCategory запоминает тему, выбранную пользователем до открытия обращения
+func (r *Repo) SetPendingCategory(ctx context.Context, userID, categoryID int64) error {
+	sql := `update users_dialog set pending_category_id = $1 where user_id = $2`
+
+	_, err := r.client.Exec(ctx, sql, categoryID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to set pending category: %w", err)
+	}
+
+	return nil
+}
+
+// ApplyPendingCategory переносит выбранную заранее тему на обращение без темы, applied = false если темы не было
+func (r *Repo) ApplyPendingCategory(ctx context.Context, userID, appealID int64) (applied bool, err error) {
+	sql := `with pending as (
+					update users_dialog set pending_category_id = null
+					where user_id = $1 and pending_category_id is not null
+					returning pending_category_id
+				)
+				update appeals set category_id = pending.pending_category_id
+				from pending
+				where appeals.id = $2 and appeals.category_id is null`
+
+	tag, err := r.client.Exec(ctx, sql, userID, appealID)
+	if err != nil {
+		return false, fmt.Errorf("failed to apply pending category: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListCategoryAppeals получает последние обращения по теме, onlyOpen - только незакрытые
+func (r *Repo) ListCategoryAppeals(ctx context.Context, categoryID int64, onlyOpen bool, limit int) ([]Appeal, error) {
+	sql := `select ` + appealColumns + ` from appeals
+				where category_id = $1 and (not $2 or closed_at is null)
+				order by opened_at desc
+				limit $3`
+
+	rows, err := r.client.Query(ctx, sql, categoryID, onlyOpen, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list category appeals: %w", err)
+	}
+	defer rows.Close()
+
+	appeals := make([]Appeal, 0)
+	for rows.Next() {
+		appeal, err := scanAppeal(rows)
+		if err != nil {
+			return nil, err
+		}
+		appeals = append(appeals, *appeal)
+	}
+
+	return appeals, rows.Err()
+}
+
+// CategoryStats количество обращений по темам, открытых начиная с since
+func (r *Repo) CategoryStats(ctx context.Context, since time.Time) ([]CategoryStat, error) {
+	sql := `select c.id, c.title, count(*), count(*) filter (where a.closed_at is null)
+				from appeals a
+				left join categories c on c.id = a.category_id
+				where a.opened_at >= $1 and a.status <> $2
+				group by c.id, c.title, c.sort
+				order by c.sort nulls last, c.id`
+
+	rows, err := r.client.Query(ctx, sql, since, AppealStatusSpam)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make([]CategoryStat, 0)
+	for rows.Next() {
+		var stat CategoryStat
+		if err = rows.Scan(&stat.CategoryID, &stat.CategoryTitle, &stat.Total, &stat.Open); err != nil {
+			return nil, fmt.Errorf("failed to scan category stat: %w", err)
+		}
+		stats = append(stats, stat)
+	}
+
+	return stats, rows.Err()
+}
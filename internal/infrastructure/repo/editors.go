@@ -0,0 +1,142 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrEditorNotFound редактор не найден
+var ErrEditorNotFound = errors.New("editor not found")
+
+// Editor представляет запись из таблицы editors
+type Editor struct {
+	TgID      int64          `sql:"tg_id"`
+	UserName  sql.NullString `sql:"username"`
+	FirstName sql.NullString `sql:"first_name"`
+	LastName  sql.NullString `sql:"last_name"`
+	UpdatedAt time.Time      `sql:"updated_at"`
+}
+
+// DisplayName имя редактора для карточек и отчетов
+func (e Editor) DisplayName() string {
+	name := strings.TrimSpace(e.FirstName.String + " " + e.LastName.String)
+	switch {
+	case name != "" && e.UserName.Valid:
+		return fmt.Sprintf("%s (@%s)", name, e.UserName.String)
+	case name != "":
+		return name
+	case e.UserName.Valid:
+		return "@" + e.UserName.String
+	}
+
+	return fmt.Sprintf("id%d", e.TgID)
+}
+
+// EditorWorkload количество незакрытых обращений редактора
+type EditorWorkload struct {
+	Editor      Editor
+	OpenAppeals int64
+}
+
+const editorColumns = `tg_id, username, first_name, last_name, updated_at`
+
+func scanEditor(row pgx.Row) (*Editor, error) {
+	var editor Editor
+	err := row.Scan(&editor.TgID, &editor.UserName, &editor.FirstName, &editor.LastName, &editor.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrEditorNotFound
+		}
+		return nil, fmt.Errorf("failed to scan editor: %w", err)
+	}
+
+	return &editor, nil
+}
+
+// SaveEditor создает или обновляет данные редактора
+func (r *Repo) SaveEditor(ctx context.Context, editor Editor) error {
+	sql := `insert into editors (tg_id, username, first_name, last_name)
+				values ($1, $2, $3, $4)
+				on conflict (tg_id) do update
+				set username = excluded.username, first_name = excluded.first_name,
+				    last_name = excluded.last_name, updated_at = now()`
+
+	_, err := r.client.Exec(ctx, sql, editor.TgID, editor.UserName, editor.FirstName, editor.LastName)
+	if err != nil {
+		return fmt.Errorf("failed to save editor: %w", err)
+	}
+
+	return nil
+}
+
+// GetEditor получает редактора по telegram ID
+func (r *Repo) GetEditor(ctx context.Context, tgID int64) (*Editor, error) {
+	sql := `select ` + editorColumns + ` from editors where tg_id = $1`
+
+	return scanEditor(r.client.QueryRow(ctx, sql, tgID))
+}
+
+// GetEditorByUsername получает редактора по username без учета регистра.
+// Username может перейти к другому человеку, поэтому берем того, кто заходил последним
+func (r *Repo) GetEditorByUsername(ctx context.Context, username string) (*Editor, error) {
+	sql := `select ` + editorColumns + ` from editors where lower(username) = lower($1) order by updated_at desc limit 1`
+
+	return scanEditor(r.client.QueryRow(ctx, sql, strings.TrimPrefix(username, "@")))
+}
+
+// ListEditorAppeals получает незакрытые обращения, назначенные редактору
+func (r *Repo) ListEditorAppeals(ctx context.Context, editorID int64) ([]Appeal, error) {
+	sql := `select ` + appealColumns + ` from appeals
+				where assigned_to = $1 and closed_at is null
+				order by urgent desc, opened_at`
+
+	rows, err := r.client.Query(ctx, sql, editorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list editor appeals: %w", err)
+	}
+	defer rows.Close()
+
+	appeals := make([]Appeal, 0)
+	for rows.Next() {
+		appeal, err := scanAppeal(rows)
+		if err != nil {
+			return nil, err
+		}
+		appeals = append(appeals, *appeal)
+	}
+
+	return appeals, rows.Err()
+}
+
+// ListEditorsWorkload получает количество незакрытых обращений по каждому редактору
+func (r *Repo) ListEditorsWorkload(ctx context.Context) ([]EditorWorkload, error) {
+	sql := `select e.tg_id, e.username, e.first_name, e.last_name, e.updated_at, count(a.id)
+				from editors e
+				left join appeals a on a.assigned_to = e.tg_id and a.closed_at is null
+				group by e.tg_id
+				order by count(a.id) desc, e.tg_id`
+
+	rows, err := r.client.Query(ctx, sql)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list editors workload: %w", err)
+	}
+	defer rows.Close()
+
+	workload := make([]EditorWorkload, 0)
+	for rows.Next() {
+		var w EditorWorkload
+		err = rows.Scan(&w.Editor.TgID, &w.Editor.UserName, &w.Editor.FirstName, &w.Editor.LastName, &w.Editor.UpdatedAt, &w.OpenAppeals)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan editor workload: %w", err)
+		}
+		workload = append(workload, w)
+	}
+
+	return workload, rows.Err()
+}
@@ -0,0 +1,233 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// encryptedTable таблица с зашифрованными полями. У каждой строки свой ключ данных:
+// enc_key_id - ID ключа, которым он зашифрован, enc_data_key - сам ключ. Строки без enc_key_id не зашифрованы
+type encryptedTable struct {
+	name    string
+	id      string
+	columns []string
+}
+
+var encryptedTables = []encryptedTable{
+	{name: "messages", id: "id", columns: []string{"text", "caption", "file_name"}},
+	{name: "message_edits", id: "id", columns: []string{"text", "caption"}},
+	{name: "source_identities", id: "user_id", columns: []string{"username", "first_name", "last_name"}},
+	{name: "quarantine", id: "id", columns: []string{"raw_update", "preview"}},
+	{name: "media_originals", id: "id", columns: []string{"file_name"}},
+}
+
+// ReencryptStat сколько строк таблицы перешифровано
+type ReencryptStat struct {
+	Table string
+	Rows  int
+}
+
+// ListStoredFileKeys ключи всех файлов в хранилище: архив полученных файлов и оригиналы документов
+func (r *Repo) ListStoredFileKeys(ctx context.Context) ([]string, error) {
+	sql := `select storage_key from messages where storage_key is not null
+				union
+				select storage_path from media_originals where storage_path <> ''`
+
+	rows, err := r.client.Query(ctx, sql)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stored files: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]string, 0)
+	for rows.Next() {
+		var key string
+		if err = rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to scan stored file: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// sealRow шифрует поля строки новым ключом данных, поля меняются на месте.
+// При выключенном шифровании поля не меняются, а ключ строки пустой.
+// Поле привязано к таблице и колонке, но не к ID строки: строку целиком с ключом данных
+// можно переставить на место другой, см. README.md
+func (r *Repo) sealRow(table string, fields map[string]*sql.NullString) (keyID sql.NullString, dataKey []byte, err error) {
+	if !r.keyring.Enabled() {
+		return sql.NullString{}, nil, nil
+	}
+
+	key, err := r.keyring.NewDataKey()
+	if err != nil {
+		return sql.NullString{}, nil, err
+	}
+	for column, field := range fields {
+		if !field.Valid {
+			continue
+		}
+		if field.String, err = key.SealString(field.String, table+"."+column); err != nil {
+			return sql.NullString{}, nil, err
+		}
+	}
+
+	return sql.NullString{String: key.KeyID, Valid: true}, key.Wrapped, nil
+}
+
+// openRow расшифровывает поля строки на месте
+func (r *Repo) openRow(table string, keyID sql.NullString, dataKey []byte, fields map[string]*sql.NullString) error {
+	if !keyID.Valid {
+		return nil
+	}
+
+	key, err := r.keyring.OpenDataKey(keyID.String, dataKey)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt %s: %w", table, err)
+	}
+	for column, field := range fields {
+		if !field.Valid {
+			continue
+		}
+		if field.String, err = key.OpenString(field.String, table+"."+column); err != nil {
+			return fmt.Errorf("failed to decrypt %s.%s: %w", table, column, err)
+		}
+	}
+
+	return nil
+}
+
+// Reencrypt перешифровывает все зашифрованные таблицы под активный ключ пачками по batchSize строк.
+// У зашифрованных строк меняется только обертка ключа данных, незашифрованные строки шифруются.
+// С full все строки, в том числе уже под активным ключом, шифруются новыми ключами данных.
+// При выключенном шифровании все строки расшифровываются
+func (r *Repo) Reencrypt(ctx context.Context, batchSize int, full bool) ([]ReencryptStat, error) {
+	stats := make([]ReencryptStat, 0, len(encryptedTables))
+	for _, table := range encryptedTables {
+		stat := ReencryptStat{Table: table.name}
+		var after int64
+		for {
+			n, last, err := r.reencryptBatch(ctx, table, after, batchSize, full)
+			if err != nil {
+				return stats, err
+			}
+			if n == 0 {
+				break
+			}
+			stat.Rows += n
+			after = last
+		}
+		stats = append(stats, stat)
+	}
+
+	return stats, nil
+}
+
+// reencryptBatch перешифровывает пачку строк после строки after, ключ которых отличается от активного,
+// а с full - все строки. Возвращает число строк и id последней
+func (r *Repo) reencryptBatch(ctx context.Context, table encryptedTable, after int64, limit int, full bool) (int, int64, error) {
+	target := sql.NullString{String: r.keyring.ActiveKeyID(), Valid: r.keyring.Enabled()}
+	full = full && r.keyring.Enabled()
+	query := fmt.Sprintf(`select %s, enc_key_id, enc_data_key, %s from %s
+				where (enc_key_id is distinct from $1 or $3) and %s > $4
+				order by %s limit $2`,
+		table.id, strings.Join(table.columns, ", "), table.name, table.id, table.id)
+
+	rows, err := r.client.Query(ctx, query, target, limit, full, after)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to select %s for reencryption: %w", table.name, err)
+	}
+
+	type row struct {
+		id      int64
+		keyID   sql.NullString
+		dataKey []byte
+		values  []sql.NullString
+	}
+	batch := make([]row, 0, limit)
+	for rows.Next() {
+		item := row{values: make([]sql.NullString, len(table.columns))}
+		dest := []any{&item.id, &item.keyID, &item.dataKey}
+		for i := range item.values {
+			dest = append(dest, &item.values[i])
+		}
+		if err = rows.Scan(dest...); err != nil {
+			rows.Close()
+			return 0, 0, fmt.Errorf("failed to scan %s: %w", table.name, err)
+		}
+		batch = append(batch, item)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, 0, fmt.Errorf("failed to select %s for reencryption: %w", table.name, err)
+	}
+	if len(batch) == 0 {
+		return 0, 0, nil
+	}
+
+	for _, item := range batch {
+		if err = r.reencryptRow(ctx, table, item.id, item.keyID, item.dataKey, item.values, full); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return len(batch), batch[len(batch)-1].id, nil
+}
+
+// reencryptRow перешифровывает строку. Обновление проходит, только если ключ строки не сменился с момента чтения.
+// Без full у зашифрованной строки меняется только обертка ключа данных, с full поля шифруются новым ключом
+func (r *Repo) reencryptRow(
+	ctx context.Context,
+	table encryptedTable,
+	id int64,
+	keyID sql.NullString,
+	dataKey []byte,
+	values []sql.NullString,
+	full bool,
+) error {
+	if keyID.Valid && r.keyring.Enabled() && !full {
+		key, err := r.keyring.OpenDataKey(keyID.String, dataKey)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %s %d: %w", table.name, id, err)
+		}
+		if key, err = r.keyring.Rewrap(key); err != nil {
+			return err
+		}
+
+		query := fmt.Sprintf(`update %s set enc_key_id = $2, enc_data_key = $3 where %s = $1 and enc_key_id = $4`, table.name, table.id)
+		if _, err = r.client.Exec(ctx, query, id, key.KeyID, key.Wrapped, keyID); err != nil {
+			return fmt.Errorf("failed to reencrypt %s %d: %w", table.name, id, err)
+		}
+		return nil
+	}
+
+	fields := make(map[string]*sql.NullString, len(table.columns))
+	for i, column := range table.columns {
+		fields[column] = &values[i]
+	}
+	if err := r.openRow(table.name, keyID, dataKey, fields); err != nil {
+		return err
+	}
+	newKeyID, newDataKey, err := r.sealRow(table.name, fields)
+	if err != nil {
+		return err
+	}
+
+	set := make([]string, 0, len(table.columns))
+	args := []any{id, newKeyID, newDataKey, keyID, dataKey}
+	for i, column := range table.columns {
+		set = append(set, fmt.Sprintf("%s = $%d", column, len(args)+1))
+		args = append(args, values[i])
+	}
+	query := fmt.Sprintf(`update %s set enc_key_id = $2, enc_data_key = $3, %s where %s = $1
+				and enc_key_id is not distinct from $4 and enc_data_key is not distinct from $5`,
+		table.name, strings.Join(set, ", "), table.id)
+	if _, err = r.client.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to reencrypt %s %d: %w", table.name, id, err)
+	}
+
+	return nil
+}
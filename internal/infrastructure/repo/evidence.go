@@ -0,0 +1,107 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// EvidenceItem представляет запись из таблицы evidence. SHA256 - хеш файла в том виде, в каком
+// его прислал источник, CleanedSHA256 - хеш копии без метаданных, которую получила редакция
+type EvidenceItem struct {
+	ID            int64          `sql:"id"`
+	AppealID      int64          `sql:"appeal_id"`
+	UserID        int64          `sql:"user_id"`
+	UserMessageID int64          `sql:"user_message_id"`
+	Kind          string         `sql:"kind"`
+	FileUniqueID  string         `sql:"file_unique_id"`
+	MimeType      sql.NullString `sql:"mime_type"`
+	Size          sql.NullInt64  `sql:"size"`
+	SHA256        sql.NullString `sql:"sha256"`
+	Error         sql.NullString `sql:"error"`
+	SentAt        time.Time      `sql:"sent_at"`
+	ReceivedAt    time.Time      `sql:"received_at"`
+	CleanedSHA256 sql.NullString `sql:"cleaned_sha256"`
+	CleanedSize   sql.NullInt64  `sql:"cleaned_size"`
+	Held          bool           `sql:"held"`
+	OriginalID    sql.NullInt64  `sql:"original_id"`
+}
+
+const evidenceColumns = `id, appeal_id, user_id, user_message_id, kind, file_unique_id, mime_type, size, sha256, error,
+	sent_at, received_at, cleaned_sha256, cleaned_size, held, original_id`
+
+func scanEvidenceItem(row pgx.Row) (*EvidenceItem, error) {
+	var item EvidenceItem
+	err := row.Scan(
+		&item.ID, &item.AppealID, &item.UserID, &item.UserMessageID, &item.Kind, &item.FileUniqueID,
+		&item.MimeType, &item.Size, &item.SHA256, &item.Error, &item.SentAt, &item.ReceivedAt,
+		&item.CleanedSHA256, &item.CleanedSize, &item.Held, &item.OriginalID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan evidence item: %w", err)
+	}
+
+	return &item, nil
+}
+
+// AddEvidenceItem сохраняет сведения о полученном файле. Если время получения не задано,
+// его ставит база
+func (r *Repo) AddEvidenceItem(ctx context.Context, item EvidenceItem) (*EvidenceItem, error) {
+	receivedAt := sql.NullTime{Time: item.ReceivedAt, Valid: !item.ReceivedAt.IsZero()}
+
+	sql := `insert into evidence (appeal_id, user_id, user_message_id, kind, file_unique_id, mime_type, size, sha256, error, sent_at,
+				received_at)
+				values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, coalesce($11, now()))
+				returning ` + evidenceColumns
+
+	return scanEvidenceItem(r.client.QueryRow(ctx, sql,
+		item.AppealID,
+		item.UserID,
+		item.UserMessageID,
+		item.Kind,
+		item.FileUniqueID,
+		item.MimeType,
+		item.Size,
+		item.SHA256,
+		item.Error,
+		item.SentAt,
+		receivedAt,
+	))
+}
+
+// SetEvidenceDelivery сохраняет, что получила редакция: хеш очищенной копии или отметку о задержке
+func (r *Repo) SetEvidenceDelivery(ctx context.Context, item EvidenceItem) error {
+	sql := `update evidence set cleaned_sha256 = $2, cleaned_size = $3, held = $4, original_id = $5 where id = $1`
+
+	_, err := r.client.Exec(ctx, sql, item.ID, item.CleanedSHA256, item.CleanedSize, item.Held, item.OriginalID)
+	if err != nil {
+		return fmt.Errorf("failed to update evidence delivery: %w", err)
+	}
+
+	return nil
+}
+
+// ListAppealEvidence материалы обращения в порядке получения
+func (r *Repo) ListAppealEvidence(ctx context.Context, appealID int64) ([]EvidenceItem, error) {
+	sql := `select ` + evidenceColumns + ` from evidence where appeal_id = $1 order by received_at, id`
+
+	rows, err := r.client.Query(ctx, sql, appealID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list evidence: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]EvidenceItem, 0)
+	for rows.Next() {
+		item, err := scanEvidenceItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+
+	return items, rows.Err()
+}
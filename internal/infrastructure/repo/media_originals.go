@@ -0,0 +1,138 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrMediaOriginalNotFound оригинал не найден
+var ErrMediaOriginalNotFound = errors.New("media original not found")
+
+// MediaOriginal представляет запись из таблицы media_originals.
+// HeldReason заполнен, если документ не удалось очистить и редакции он не передан,
+// по FileID такой документ выдается, даже если его не удалось скачать
+type MediaOriginal struct {
+	ID                 int64          `sql:"id"`
+	AppealID           int64          `sql:"appeal_id"`
+	UserID             int64          `sql:"user_id"`
+	FileUniqueID       string         `sql:"file_unique_id"`
+	FileName           sql.NullString `sql:"file_name"`
+	Format             string         `sql:"format"`
+	StoragePath        string         `sql:"storage_path"`
+	Size               int64          `sql:"size"`
+	Removed            string         `sql:"removed"`
+	FileID             sql.NullString `sql:"file_id"`
+	HeldReason         sql.NullString `sql:"held_reason"`
+	AdminChatMessageID sql.NullInt64  `sql:"admin_chat_message_id"`
+	CreatedAt          time.Time      `sql:"created_at"`
+}
+
+// mediaOriginalFields зашифрованные поля оригинала
+func mediaOriginalFields(original *MediaOriginal) map[string]*sql.NullString {
+	return map[string]*sql.NullString{"file_name": &original.FileName}
+}
+
+// AddMediaOriginal сохраняет сведения об оригинале очищенного документа
+func (r *Repo) AddMediaOriginal(ctx context.Context, original MediaOriginal) (int64, error) {
+	keyID, dataKey, err := r.sealRow("media_originals", mediaOriginalFields(&original))
+	if err != nil {
+		return 0, fmt.Errorf("failed to encrypt media original: %w", err)
+	}
+
+	sql := `insert into media_originals (appeal_id, user_id, file_unique_id, file_name, format, storage_path, size, removed,
+				file_id, held_reason, enc_key_id, enc_data_key)
+				values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+				returning id`
+
+	var id int64
+	err = r.client.QueryRow(ctx, sql,
+		original.AppealID,
+		original.UserID,
+		original.FileUniqueID,
+		original.FileName,
+		original.Format,
+		original.StoragePath,
+		original.Size,
+		original.Removed,
+		original.FileID,
+		original.HeldReason,
+		keyID,
+		dataKey,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add media original: %w", err)
+	}
+
+	return id, nil
+}
+
+// SetMediaOriginalMessage привязывает оригинал к сообщению с очищенной копией в чате админов
+func (r *Repo) SetMediaOriginalMessage(ctx context.Context, id, adminChatMessageID int64) error {
+	sql := `update media_originals set admin_chat_message_id = $2 where id = $1`
+
+	_, err := r.client.Exec(ctx, sql, id, adminChatMessageID)
+	if err != nil {
+		return fmt.Errorf("failed to set media original message: %w", err)
+	}
+
+	return nil
+}
+
+// GetMediaOriginal оригинал по ID
+func (r *Repo) GetMediaOriginal(ctx context.Context, id int64) (*MediaOriginal, error) {
+	var original MediaOriginal
+	var keyID sql.NullString
+	var dataKey []byte
+
+	sql := `select id, appeal_id, user_id, file_unique_id, file_name, format, storage_path, size, removed,
+				file_id, held_reason, admin_chat_message_id, created_at, enc_key_id, enc_data_key
+				from media_originals where id = $1`
+	err := r.client.QueryRow(ctx, sql, id).Scan(
+		&original.ID,
+		&original.AppealID,
+		&original.UserID,
+		&original.FileUniqueID,
+		&original.FileName,
+		&original.Format,
+		&original.StoragePath,
+		&original.Size,
+		&original.Removed,
+		&original.FileID,
+		&original.HeldReason,
+		&original.AdminChatMessageID,
+		&original.CreatedAt,
+		&keyID,
+		&dataKey,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMediaOriginalNotFound
+		}
+		return nil, fmt.Errorf("failed to get media original: %w", err)
+	}
+	if err = r.openRow("media_originals", keyID, dataKey, mediaOriginalFields(&original)); err != nil {
+		return nil, err
+	}
+
+	return &original, nil
+}
+
+// GetMediaOriginalByMessage оригинал документа по сообщению в чате админов
+func (r *Repo) GetMediaOriginalByMessage(ctx context.Context, adminChatMessageID int64) (*MediaOriginal, error) {
+	var id int64
+	sql := `select id from media_originals where admin_chat_message_id = $1 order by id desc limit 1`
+	err := r.client.QueryRow(ctx, sql, adminChatMessageID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMediaOriginalNotFound
+		}
+		return nil, fmt.Errorf("failed to get media original: %w", err)
+	}
+
+	return r.GetMediaOriginal(ctx, id)
+}
@@ -0,0 +1,97 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrMessageLinkNotFound сообщение не связано с перепиской
+var ErrMessageLinkNotFound = errors.New("message link not found")
+
+// MessageLink представляет запись из таблицы message_links
+type MessageLink struct {
+	AdminChatMessageID int64         `sql:"admin_chat_message_id"`
+	UserID             int64         `sql:"user_id"`
+	UserMessageID      int64         `sql:"user_message_id"`
+	AppealID           sql.NullInt64 `sql:"appeal_id"`
+	Direction          string        `sql:"direction"`
+	CreatedAt          time.Time     `sql:"created_at"`
+	RecalledAt         sql.NullTime  `sql:"recalled_at"`
+}
+
+const messageLinkColumns = `admin_chat_message_id, user_id, user_message_id, appeal_id, direction, created_at, recalled_at`
+
+func scanMessageLink(row pgx.Row) (*MessageLink, error) {
+	var link MessageLink
+	err := row.Scan(
+		&link.AdminChatMessageID,
+		&link.UserID,
+		&link.UserMessageID,
+		&link.AppealID,
+		&link.Direction,
+		&link.CreatedAt,
+		&link.RecalledAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMessageLinkNotFound
+		}
+		return nil, fmt.Errorf("failed to scan message link: %w", err)
+	}
+
+	return &link, nil
+}
+
+// SaveMessageLink сохраняет связь сообщения в чате админов с сообщением пользователя
+func (r *Repo) SaveMessageLink(ctx context.Context, link MessageLink) error {
+	sql := `insert into message_links (admin_chat_message_id, user_id, user_message_id, appeal_id, direction)
+				values ($1, $2, $3, $4, $5)
+				on conflict (admin_chat_message_id) do nothing`
+
+	_, err := r.client.Exec(ctx, sql,
+		link.AdminChatMessageID,
+		link.UserID,
+		link.UserMessageID,
+		link.AppealID,
+		link.Direction,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save message link: %w", err)
+	}
+
+	return nil
+}
+
+// GetMessageLink получает связь по ID сообщения в чате админов
+func (r *Repo) GetMessageLink(ctx context.Context, adminChatMessageID int64) (*MessageLink, error) {
+	sql := `select ` + messageLinkColumns + ` from message_links where admin_chat_message_id = $1`
+
+	return scanMessageLink(r.client.QueryRow(ctx, sql, adminChatMessageID))
+}
+
+// GetMessageLinkByUserMessage получает первое сообщение в чате админов, связанное с сообщением в чате пользователя
+func (r *Repo) GetMessageLinkByUserMessage(ctx context.Context, userID, userMessageID int64, direction string) (*MessageLink, error) {
+	sql := `select ` + messageLinkColumns + ` from message_links
+				where user_id = $1 and user_message_id = $2 and direction = $3
+				order by admin_chat_message_id
+				limit 1`
+
+	return scanMessageLink(r.client.QueryRow(ctx, sql, userID, userMessageID, direction))
+}
+
+// MarkMessageLinkRecalled отмечает, что копия ответа удалена у пользователя
+func (r *Repo) MarkMessageLinkRecalled(ctx context.Context, adminChatMessageID int64) error {
+	sql := `update message_links set recalled_at = now() where admin_chat_message_id = $1`
+
+	_, err := r.client.Exec(ctx, sql, adminChatMessageID)
+	if err != nil {
+		return fmt.Errorf("failed to mark message link recalled: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,268 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Направления сообщений
+const (
+	DirectionUserToAdmin = "user_to_admin"
+	DirectionAdminToUser = "admin_to_user"
+)
+
+// ErrMessageNotFound сообщения нет в журнале переписки
+var ErrMessageNotFound = errors.New("message not found")
+
+// Message представляет запись из таблицы messages
+type Message struct {
+	ID                 int64          `sql:"id"`
+	AppealID           sql.NullInt64  `sql:"appeal_id"`
+	UserID             int64          `sql:"user_id"`
+	Direction          string         `sql:"direction"`
+	AuthorID           int64          `sql:"author_id"`
+	UserChatMessageID  sql.NullInt64  `sql:"user_chat_message_id"`
+	AdminChatMessageID sql.NullInt64  `sql:"admin_chat_message_id"`
+	Text               sql.NullString `sql:"text"`
+	Caption            sql.NullString `sql:"caption"`
+	MediaKind          sql.NullString `sql:"media_kind"`
+	FileID             sql.NullString `sql:"file_id"`
+	FileUniqueID       sql.NullString `sql:"file_unique_id"`
+	MediaGroupID       sql.NullString `sql:"media_group_id"`
+	StorageKey         sql.NullString `sql:"storage_key"`
+	FileName           sql.NullString `sql:"file_name"`
+	CardNote           sql.NullString `sql:"card_note"`
+	CreatedAt          time.Time      `sql:"created_at"`
+}
+
+const messageColumns = `id, appeal_id, user_id, direction, author_id, user_chat_message_id, admin_chat_message_id,
+	text, caption, media_kind, file_id, file_unique_id, media_group_id, storage_key, file_name, card_note,
+	created_at, enc_key_id, enc_data_key`
+
+// MessageEdit представляет запись из таблицы message_edits
+type MessageEdit struct {
+	ID        int64          `sql:"id"`
+	MessageID int64          `sql:"message_id"`
+	EditorID  int64          `sql:"editor_id"`
+	Text      sql.NullString `sql:"text"`
+	Caption   sql.NullString `sql:"caption"`
+	EditedAt  time.Time      `sql:"edited_at"`
+}
+
+// messageFields зашифрованные поля сообщения
+func messageFields(message *Message) map[string]*sql.NullString {
+	return map[string]*sql.NullString{
+		"text":      &message.Text,
+		"caption":   &message.Caption,
+		"file_name": &message.FileName,
+	}
+}
+
+func (r *Repo) scanMessages(rows pgx.Rows) ([]Message, error) {
+	defer rows.Close()
+
+	messages := make([]Message, 0)
+	for rows.Next() {
+		var message Message
+		var keyID sql.NullString
+		var dataKey []byte
+		err := rows.Scan(
+			&message.ID,
+			&message.AppealID,
+			&message.UserID,
+			&message.Direction,
+			&message.AuthorID,
+			&message.UserChatMessageID,
+			&message.AdminChatMessageID,
+			&message.Text,
+			&message.Caption,
+			&message.MediaKind,
+			&message.FileID,
+			&message.FileUniqueID,
+			&message.MediaGroupID,
+			&message.StorageKey,
+			&message.FileName,
+			&message.CardNote,
+			&message.CreatedAt,
+			&keyID,
+			&dataKey,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		if err = r.openRow("messages", keyID, dataKey, messageFields(&message)); err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
+
+// SaveMessage сохраняет сообщение переписки и возвращает его ID
+func (r *Repo) SaveMessage(ctx context.Context, message Message) (int64, error) {
+	keyID, dataKey, err := r.sealRow("messages", messageFields(&message))
+	if err != nil {
+		return 0, fmt.Errorf("failed to encrypt message: %w", err)
+	}
+
+	sql := `insert into messages (appeal_id, user_id, direction, author_id, user_chat_message_id, admin_chat_message_id,
+				text, caption, media_kind, file_id, file_unique_id, media_group_id, storage_key, file_name, card_note,
+				enc_key_id, enc_data_key)
+				values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+				returning id`
+
+	var id int64
+	err = r.client.QueryRow(ctx, sql,
+		message.AppealID,
+		message.UserID,
+		message.Direction,
+		message.AuthorID,
+		message.UserChatMessageID,
+		message.AdminChatMessageID,
+		message.Text,
+		message.Caption,
+		message.MediaKind,
+		message.FileID,
+		message.FileUniqueID,
+		message.MediaGroupID,
+		message.StorageKey,
+		message.FileName,
+		message.CardNote,
+		keyID,
+		dataKey,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to save message: %w", err)
+	}
+
+	return id, nil
+}
+
+// ListAppealFiles получает сообщения обращения с файлами в архиве в хронологическом порядке
+func (r *Repo) ListAppealFiles(ctx context.Context, appealID int64) ([]Message, error) {
+	sql := `select ` + messageColumns + ` from messages
+				where appeal_id = $1 and storage_key is not null
+				order by created_at, id`
+
+	rows, err := r.client.Query(ctx, sql, appealID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list appeal files: %w", err)
+	}
+
+	return r.scanMessages(rows)
+}
+
+// GetMessage получает сообщение из журнала по ID
+func (r *Repo) GetMessage(ctx context.Context, id int64) (*Message, error) {
+	sql := `select ` + messageColumns + ` from messages where id = $1`
+
+	rows, err := r.client.Query(ctx, sql, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	messages, err := r.scanMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, ErrMessageNotFound
+	}
+
+	return &messages[0], nil
+}
+
+// ListUserCardMessageIDs получает ID последних limit карточек пользователя в чате админов, новые первыми
+func (r *Repo) ListUserCardMessageIDs(ctx context.Context, userID int64, limit int) ([]int64, error) {
+	sql := `select admin_chat_message_id from messages
+				where user_id = $1 and direction = $2 and admin_chat_message_id is not null
+				order by id desc
+				limit $3`
+
+	rows, err := r.client.Query(ctx, sql, userID, DirectionUserToAdmin, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user cards: %w", err)
+	}
+	defer rows.Close()
+
+	messageIDs := make([]int64, 0)
+	for rows.Next() {
+		var messageID int64
+		if err = rows.Scan(&messageID); err != nil {
+			return nil, fmt.Errorf("failed to scan user card: %w", err)
+		}
+		messageIDs = append(messageIDs, messageID)
+	}
+
+	return messageIDs, rows.Err()
+}
+
+// GetMessageByUserChatMessage получает сообщение из журнала по его ID в чате пользователя
+func (r *Repo) GetMessageByUserChatMessage(ctx context.Context, userID, userChatMessageID int64, direction string) (*Message, error) {
+	sql := `select ` + messageColumns + ` from messages
+				where user_id = $1 and user_chat_message_id = $2 and direction = $3
+				order by id
+				limit 1`
+
+	rows, err := r.client.Query(ctx, sql, userID, userChatMessageID, direction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	messages, err := r.scanMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, ErrMessageNotFound
+	}
+
+	return &messages[0], nil
+}
+
+// GetMessageByAdminChatMessage получает сообщение из журнала по его ID в чате админов
+func (r *Repo) GetMessageByAdminChatMessage(ctx context.Context, adminChatMessageID int64, direction string) (*Message, error) {
+	sql := `select ` + messageColumns + ` from messages
+				where admin_chat_message_id = $1 and direction = $2
+				order by id
+				limit 1`
+
+	rows, err := r.client.Query(ctx, sql, adminChatMessageID, direction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	messages, err := r.scanMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, ErrMessageNotFound
+	}
+
+	return &messages[0], nil
+}
+
+// SaveMessageEdit сохраняет новую версию отредактированного сообщения
+func (r *Repo) SaveMessageEdit(ctx context.Context, edit MessageEdit) error {
+	keyID, dataKey, err := r.sealRow("message_edits", map[string]*sql.NullString{"text": &edit.Text, "caption": &edit.Caption})
+	if err != nil {
+		return fmt.Errorf("failed to encrypt message edit: %w", err)
+	}
+
+	sql := `insert into message_edits (message_id, editor_id, text, caption, enc_key_id, enc_data_key)
+				values ($1, $2, $3, $4, $5, $6)`
+
+	_, err = r.client.Exec(ctx, sql, edit.MessageID, edit.EditorID, edit.Text, edit.Caption, keyID, dataKey)
+	if err != nil {
+		return fmt.Errorf("failed to save message edit: %w", err)
+	}
+
+	return nil
+}
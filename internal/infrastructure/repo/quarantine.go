@@ -0,0 +1,174 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrQuarantineNotFound сообщение в карантине не найдено
+	ErrQuarantineNotFound = errors.New("quarantine item not found")
+	// ErrQuarantineReviewed сообщение уже выпущено или удалено из карантина
+	ErrQuarantineReviewed = errors.New("quarantine item already reviewed")
+)
+
+// QuarantineItem представляет запись из таблицы quarantine
+type QuarantineItem struct {
+	ID            int64         `sql:"id"`
+	UserID        int64         `sql:"user_id"`
+	UserMessageID int64         `sql:"user_message_id"`
+	RawUpdate     []byte        `sql:"raw_update"`
+	Preview       string        `sql:"preview"`
+	Score         float64       `sql:"score"`
+	Reasons       string        `sql:"reasons"`
+	CreatedAt     time.Time     `sql:"created_at"`
+	ReviewedAt    sql.NullTime  `sql:"reviewed_at"`
+	ReviewedBy    sql.NullInt64 `sql:"reviewed_by"`
+	Released      bool          `sql:"released"`
+}
+
+// UserActivity активность пользователя для фильтра спама
+type UserActivity struct {
+	// FirstMessageAt время первого сообщения, невалидно если пользователь пишет впервые
+	FirstMessageAt sql.NullTime
+	// RecentMessages количество сообщений после since
+	RecentMessages int
+}
+
+const quarantineColumns = `id, user_id, user_message_id, raw_update, preview, score, reasons,
+	created_at, reviewed_at, reviewed_by, released, enc_key_id, enc_data_key`
+
+func (r *Repo) scanQuarantineItem(row pgx.Row) (*QuarantineItem, error) {
+	var item QuarantineItem
+	var rawUpdate, preview, keyID sql.NullString
+	var dataKey []byte
+	err := row.Scan(
+		&item.ID, &item.UserID, &item.UserMessageID, &rawUpdate, &preview, &item.Score, &item.Reasons,
+		&item.CreatedAt, &item.ReviewedAt, &item.ReviewedBy, &item.Released, &keyID, &dataKey,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrQuarantineNotFound
+		}
+		return nil, fmt.Errorf("failed to scan quarantine item: %w", err)
+	}
+
+	err = r.openRow("quarantine", keyID, dataKey, map[string]*sql.NullString{"raw_update": &rawUpdate, "preview": &preview})
+	if err != nil {
+		return nil, err
+	}
+	item.RawUpdate, item.Preview = []byte(rawUpdate.String), preview.String
+
+	return &item, nil
+}
+
+// AddQuarantineItem помещает сообщение в карантин
+func (r *Repo) AddQuarantineItem(ctx context.Context, item QuarantineItem) (int64, error) {
+	rawUpdate := sql.NullString{String: string(item.RawUpdate), Valid: true}
+	preview := sql.NullString{String: item.Preview, Valid: true}
+	keyID, dataKey, err := r.sealRow("quarantine", map[string]*sql.NullString{"raw_update": &rawUpdate, "preview": &preview})
+	if err != nil {
+		return 0, fmt.Errorf("failed to encrypt quarantine item: %w", err)
+	}
+
+	sql := `insert into quarantine (user_id, user_message_id, raw_update, preview, score, reasons, enc_key_id, enc_data_key)
+				values ($1, $2, $3, $4, $5, $6, $7, $8)
+				returning id`
+
+	var id int64
+	err = r.client.QueryRow(ctx, sql,
+		item.UserID, item.UserMessageID, rawUpdate, preview, item.Score, item.Reasons, keyID, dataKey,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add quarantine item: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetQuarantineItem получает сообщение из карантина
+func (r *Repo) GetQuarantineItem(ctx context.Context, id int64) (*QuarantineItem, error) {
+	sql := `select ` + quarantineColumns + ` from quarantine where id = $1`
+
+	return r.scanQuarantineItem(r.client.QueryRow(ctx, sql, id))
+}
+
+// ListPendingQuarantine получает нерассмотренные сообщения из карантина, старые первыми
+func (r *Repo) ListPendingQuarantine(ctx context.Context, limit int) ([]QuarantineItem, error) {
+	sql := `select ` + quarantineColumns + ` from quarantine
+				where reviewed_at is null
+				order by created_at
+				limit $1`
+
+	rows, err := r.client.Query(ctx, sql, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list quarantine: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]QuarantineItem, 0)
+	for rows.Next() {
+		item, err := r.scanQuarantineItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+
+	return items, rows.Err()
+}
+
+// CountPendingQuarantine количество нерассмотренных сообщений в карантине
+func (r *Repo) CountPendingQuarantine(ctx context.Context) (int64, error) {
+	sql := `select count(*) from quarantine where reviewed_at is null`
+
+	var count int64
+	err := r.client.QueryRow(ctx, sql).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count quarantine: %w", err)
+	}
+
+	return count, nil
+}
+
+// ReviewQuarantineItem отмечает решение редактора: released = true - сообщение выпущено, иначе удалено
+func (r *Repo) ReviewQuarantineItem(ctx context.Context, id, reviewedBy int64, released bool) error {
+	sql := `update quarantine set reviewed_at = now(), reviewed_by = $2, released = $3
+				where id = $1 and reviewed_at is null`
+
+	tag, err := r.client.Exec(ctx, sql, id, reviewedBy, released)
+	if err != nil {
+		return fmt.Errorf("failed to review quarantine item: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err = r.GetQuarantineItem(ctx, id); err != nil {
+			return err
+		}
+		return ErrQuarantineReviewed
+	}
+
+	return nil
+}
+
+// GetUserActivity активность пользователя по журналу сообщений и карантину
+func (r *Repo) GetUserActivity(ctx context.Context, userID int64, since time.Time) (UserActivity, error) {
+	sql := `select min(created_at), count(*) filter (where created_at > $3)
+				from (
+					select created_at from messages where user_id = $1 and direction = $2
+					union all
+					select created_at from quarantine where user_id = $1
+				) m`
+
+	var activity UserActivity
+	err := r.client.QueryRow(ctx, sql, userID, DirectionUserToAdmin, since).Scan(&activity.FirstMessageAt, &activity.RecentMessages)
+	if err != nil {
+		return UserActivity{}, fmt.Errorf("failed to get user activity: %w", err)
+	}
+
+	return activity, nil
+}
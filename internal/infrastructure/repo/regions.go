@@ -0,0 +1,111 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RegionStat количество обращений по субъекту, RegionCode = 0 для обращений без региона
+type RegionStat struct {
+	RegionCode int64
+	Total      int64
+	Open       int64
+}
+
+// SetAppealRegion меняет регион обращения
+func (r *Repo) SetAppealRegion(ctx context.Context, appealID, regionCode int64) error {
+	sql := `update appeals set region_code = $1 where id = $2`
+
+	tag, err := r.client.Exec(ctx, sql, regionCode, appealID)
+	if err != nil {
+		return fmt.Errorf("failed to set appeal region: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAppealNotFound
+	}
+
+	return nil
+}
+
+// SetPendingRegion запоминает регион, выбранный пользователем до открытия обращения
+func (r *Repo) SetPendingRegion(ctx context.Context, userID, regionCode int64) error {
+	sql := `update users_dialog set pending_region_code = $1 where user_id = $2`
+
+	_, err := r.client.Exec(ctx, sql, regionCode, userID)
+	if err != nil {
+		return fmt.Errorf("failed to set pending region: %w", err)
+	}
+
+	return nil
+}
+
+// ApplyPendingRegion переносит выбранный заранее регион на обращение без региона, applied = false если региона не было
+func (r *Repo) ApplyPendingRegion(ctx context.Context, userID, appealID int64) (applied bool, err error) {
+	sql := `with pending as (
+					update users_dialog set pending_region_code = null
+					where user_id = $1 and pending_region_code is not null
+					returning pending_region_code
+				)
+				update appeals set region_code = pending.pending_region_code
+				from pending
+				where appeals.id = $2 and appeals.region_code is null`
+
+	tag, err := r.client.Exec(ctx, sql, userID, appealID)
+	if err != nil {
+		return false, fmt.Errorf("failed to apply pending region: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListRegionAppeals получает последние обращения из региона, onlyOpen - только незакрытые
+func (r *Repo) ListRegionAppeals(ctx context.Context, regionCode int64, onlyOpen bool, limit int) ([]Appeal, error) {
+	sql := `select ` + appealColumns + ` from appeals
+				where region_code = $1 and (not $2 or closed_at is null)
+				order by opened_at desc
+				limit $3`
+
+	rows, err := r.client.Query(ctx, sql, regionCode, onlyOpen, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list region appeals: %w", err)
+	}
+	defer rows.Close()
+
+	appeals := make([]Appeal, 0)
+	for rows.Next() {
+		appeal, err := scanAppeal(rows)
+		if err != nil {
+			return nil, err
+		}
+		appeals = append(appeals, *appeal)
+	}
+
+	return appeals, rows.Err()
+}
+
+// RegionStats количество обращений по регионам, открытых начиная с since, самые частые первыми
+func (r *Repo) RegionStats(ctx context.Context, since time.Time) ([]RegionStat, error) {
+	sql := `select coalesce(region_code, 0), count(*), count(*) filter (where closed_at is null)
+				from appeals
+				where opened_at >= $1 and status <> $2
+				group by region_code
+				order by count(*) desc, region_code`
+
+	rows, err := r.client.Query(ctx, sql, since, AppealStatusSpam)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get region stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make([]RegionStat, 0)
+	for rows.Next() {
+		var stat RegionStat
+		if err = rows.Scan(&stat.RegionCode, &stat.Total, &stat.Open); err != nil {
+			return nil, fmt.Errorf("failed to scan region stat: %w", err)
+		}
+		stats = append(stats, stat)
+	}
+
+	return stats, rows.Err()
+}
@@ -5,14 +5,16 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"medrussia_news_bot/internal/pkg/envelope"
 	"medrussia_news_bot/internal/pkg/postgres"
 
 	"github.com/jackc/pgx/v5"
 )
 
 type Repo struct {
-	client postgres.Client
-	logger *slog.Logger
+	client  postgres.Client
+	logger  *slog.Logger
+	keyring *envelope.Keyring
 }
 
 // UserDialog представляет запись из таблицы users_dialog
@@ -86,15 +88,40 @@ func (r *Repo) TurnOnAvailable(ctx context.Context, userID int64) error {
 }
 
 // CloseAppeal - закрывает обращение
-func (r *Repo) CloseAppeal(ctx context.Context, userID int64) error {
-	sql := `update users_dialog set available = false, last_admin_message_id = 0, last_user_message_id = 0 where user_id = $1`
-	_, err := r.client.Exec(ctx, sql, userID)
-	return err
+func (r *Repo) CloseAppeal(ctx context.Context, userID, closedBy int64) error {
+	return r.closeAppeal(ctx, userID, closedBy, AppealStatusClosed)
+}
+
+// MarkAppealSpam закрывает обращение как спам
+func (r *Repo) MarkAppealSpam(ctx context.Context, userID, closedBy int64) error {
+	return r.closeAppeal(ctx, userID, closedBy, AppealStatusSpam)
+}
+
+// closeAppeal закрывает незакрытое обращение пользователя с указанным статусом и сбрасывает диалог
+func (r *Repo) closeAppeal(ctx context.Context, userID, closedBy int64, status string) error {
+	tx, err := r.client.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sql := `update appeals set status = $1, closed_at = now(), closed_by = $2 where user_id = $3 and closed_at is null`
+	if _, err = tx.Exec(ctx, sql, status, closedBy, userID); err != nil {
+		return fmt.Errorf("failed to close appeal: %w", err)
+	}
+
+	sql = `update users_dialog set available = false, last_admin_message_id = 0, last_user_message_id = 0 where user_id = $1`
+	if _, err = tx.Exec(ctx, sql, userID); err != nil {
+		return fmt.Errorf("failed to reset user dialog: %w", err)
+	}
+
+	return tx.Commit(ctx)
 }
 
-func NewRepo(client postgres.Client, logger *slog.Logger) *Repo {
+func NewRepo(client postgres.Client, logger *slog.Logger, keyring *envelope.Keyring) *Repo {
 	return &Repo{
-		client: client,
-		logger: logger,
+		client:  client,
+		logger:  logger,
+		keyring: keyring,
 	}
 }
@@ -0,0 +1,43 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrEditorRoleNotFound роль участнику чата админов не выдавалась
+var ErrEditorRoleNotFound = errors.New("editor role not found")
+
+// GetEditorRole получает роль, выданную участнику чата админов
+func (r *Repo) GetEditorRole(ctx context.Context, tgID int64) (string, error) {
+	sql := `select role from editor_roles where tg_id = $1`
+
+	var role string
+	err := r.client.QueryRow(ctx, sql, tgID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrEditorRoleNotFound
+		}
+		return "", fmt.Errorf("failed to get editor role: %w", err)
+	}
+
+	return role, nil
+}
+
+// SetEditorRole выдает роль участнику чата админов
+func (r *Repo) SetEditorRole(ctx context.Context, tgID int64, role string, grantedBy int64) error {
+	sql := `insert into editor_roles (tg_id, role, granted_by)
+				values ($1, $2, $3)
+				on conflict (tg_id) do update
+				set role = excluded.role, granted_by = excluded.granted_by, updated_at = now()`
+
+	_, err := r.client.Exec(ctx, sql, tgID, role, grantedBy)
+	if err != nil {
+		return fmt.Errorf("failed to set editor role: %w", err)
+	}
+
+	return nil
+}
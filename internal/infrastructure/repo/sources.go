@@ -0,0 +1,166 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrSourceIdentityNotFound данные источника не сохранялись
+var ErrSourceIdentityNotFound = errors.New("source identity not found")
+
+// SourceIdentity настоящие данные пользователя, скрытые псевдонимом
+type SourceIdentity struct {
+	UserID    int64
+	Pseudonym string
+	UserName  sql.NullString
+	FirstName sql.NullString
+	LastName  sql.NullString
+	UpdatedAt time.Time
+}
+
+// sourceIdentityFields зашифрованные поля данных источника
+func sourceIdentityFields(identity *SourceIdentity) map[string]*sql.NullString {
+	return map[string]*sql.NullString{
+		"username":   &identity.UserName,
+		"first_name": &identity.FirstName,
+		"last_name":  &identity.LastName,
+	}
+}
+
+// SaveSourceIdentity сохраняет или обновляет данные источника
+func (r *Repo) SaveSourceIdentity(ctx context.Context, identity SourceIdentity) error {
+	if r.keyring.Enabled() {
+		// зашифрованные значения каждый раз разные, поэтому изменения сверяются до шифрования
+		current, err := r.GetSourceIdentity(ctx, identity.UserID)
+		if err == nil && current.Pseudonym == identity.Pseudonym && current.UserName == identity.UserName &&
+			current.FirstName == identity.FirstName && current.LastName == identity.LastName {
+			return nil
+		}
+	}
+
+	keyID, dataKey, err := r.sealRow("source_identities", sourceIdentityFields(&identity))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt source identity: %w", err)
+	}
+
+	sql := `insert into source_identities (user_id, pseudonym, username, first_name, last_name, enc_key_id, enc_data_key)
+				values ($1, $2, $3, $4, $5, $6, $7)
+				on conflict (user_id) do update
+				set pseudonym = excluded.pseudonym, username = excluded.username,
+					first_name = excluded.first_name, last_name = excluded.last_name,
+					enc_key_id = excluded.enc_key_id, enc_data_key = excluded.enc_data_key, updated_at = now()
+				where (source_identities.pseudonym, source_identities.username, source_identities.first_name, source_identities.last_name)
+					is distinct from (excluded.pseudonym, excluded.username, excluded.first_name, excluded.last_name)`
+
+	_, err = r.client.Exec(ctx, sql,
+		identity.UserID, identity.Pseudonym, identity.UserName, identity.FirstName, identity.LastName, keyID, dataKey)
+	if err != nil {
+		return fmt.Errorf("failed to save source identity: %w", err)
+	}
+
+	return nil
+}
+
+// GetSourceIdentity данные источника по telegram ID
+func (r *Repo) GetSourceIdentity(ctx context.Context, userID int64) (*SourceIdentity, error) {
+	return r.getSourceIdentity(ctx, `where user_id = $1`, userID)
+}
+
+// FindSourceIdentities данные источников, псевдоним которых начинается с prefix, не больше limit.
+// Короткий псевдоним со старых карточек - начало нового, поэтому ищем по началу
+func (r *Repo) FindSourceIdentities(ctx context.Context, prefix string, limit int) ([]SourceIdentity, error) {
+	rows, err := r.client.Query(ctx, sourceIdentitySelect+` where starts_with(pseudonym, $1) order by user_id limit $2`, prefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find source identities: %w", err)
+	}
+	defer rows.Close()
+
+	identities := make([]SourceIdentity, 0)
+	for rows.Next() {
+		identity, err := r.scanSourceIdentity(rows)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, *identity)
+	}
+
+	return identities, rows.Err()
+}
+
+// ListLegacyPseudonymUsers пользователи, псевдоним которых короче length: сохранены до удлинения псевдонимов
+func (r *Repo) ListLegacyPseudonymUsers(ctx context.Context, length int) ([]int64, error) {
+	rows, err := r.client.Query(ctx, `select user_id from source_identities where length(pseudonym) < $1`, length)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list legacy pseudonyms: %w", err)
+	}
+	defer rows.Close()
+
+	userIDs := make([]int64, 0)
+	for rows.Next() {
+		var userID int64
+		if err = rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan legacy pseudonym: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, rows.Err()
+}
+
+// SetSourcePseudonym замена псевдонима источника
+func (r *Repo) SetSourcePseudonym(ctx context.Context, userID int64, pseudonym string) error {
+	_, err := r.client.Exec(ctx, `update source_identities set pseudonym = $2 where user_id = $1`, userID, pseudonym)
+	if err != nil {
+		return fmt.Errorf("failed to set source pseudonym: %w", err)
+	}
+
+	return nil
+}
+
+const sourceIdentitySelect = `select user_id, pseudonym, username, first_name, last_name, updated_at, enc_key_id, enc_data_key
+				from source_identities`
+
+func (r *Repo) getSourceIdentity(ctx context.Context, where string, arg any) (*SourceIdentity, error) {
+	identity, err := r.scanSourceIdentity(r.client.QueryRow(ctx, sourceIdentitySelect+" "+where, arg))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSourceIdentityNotFound
+		}
+		return nil, err
+	}
+
+	return identity, nil
+}
+
+func (r *Repo) scanSourceIdentity(row pgx.Row) (*SourceIdentity, error) {
+	var identity SourceIdentity
+	var keyID sql.NullString
+	var dataKey []byte
+
+	err := row.Scan(
+		&identity.UserID,
+		&identity.Pseudonym,
+		&identity.UserName,
+		&identity.FirstName,
+		&identity.LastName,
+		&identity.UpdatedAt,
+		&keyID,
+		&dataKey,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get source identity: %w", err)
+	}
+	if err = r.openRow("source_identities", keyID, dataKey, sourceIdentityFields(&identity)); err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
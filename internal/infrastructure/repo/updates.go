@@ -0,0 +1,34 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+)
+
+// ClaimUpdate берет update_id вебхука в обработку, first = false если такой update уже обрабатывается
+// или обработан. Записи старше суток удаляются тем же запросом: Telegram столько не повторяет обновления
+func (r *Repo) ClaimUpdate(ctx context.Context, updateID int64) (first bool, err error) {
+	sql := `with expired as (
+					delete from webhook_updates where received_at < now() - interval '1 day'
+				)
+				insert into webhook_updates (update_id) values ($1)
+				on conflict (update_id) do nothing`
+
+	tag, err := r.client.Exec(ctx, sql, updateID)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim update: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// ReleaseUpdate снимает update_id, если обработка не удалась, чтобы повтор Telegram обработался заново
+func (r *Repo) ReleaseUpdate(ctx context.Context, updateID int64) error {
+	sql := `delete from webhook_updates where update_id = $1`
+
+	if _, err := r.client.Exec(ctx, sql, updateID); err != nil {
+		return fmt.Errorf("failed to release update: %w", err)
+	}
+
+	return nil
+}
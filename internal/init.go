@@ -2,14 +2,25 @@ package internal
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"log/slog"
 	"medrussia_news_bot/internal/config"
 	"medrussia_news_bot/internal/infrastructure/controller"
 	"medrussia_news_bot/internal/infrastructure/controller/bot_controller"
 	"medrussia_news_bot/internal/infrastructure/repo"
+	"medrussia_news_bot/internal/pkg/envelope"
+	"medrussia_news_bot/internal/pkg/filestore"
 	"medrussia_news_bot/internal/pkg/postgres"
 	"medrussia_news_bot/internal/pkg/telegram"
+	"medrussia_news_bot/internal/service/access"
+	"medrussia_news_bot/internal/service/alert"
+	"medrussia_news_bot/internal/service/anonymity"
+	"medrussia_news_bot/internal/service/audit"
+	"medrussia_news_bot/internal/service/evidence"
+	"medrussia_news_bot/internal/service/sanitize"
+	"medrussia_news_bot/internal/service/spam"
+	"medrussia_news_bot/internal/service/triage"
 	"net/http"
 	"os"
 	"time"
@@ -31,7 +42,7 @@ func (a *App) initBot(_ context.Context) *App {
 }
 
 func (a *App) initBotController(_ context.Context) *App {
-	a.controllers.botController = bot_controller.NewTelegramWebhookController(a.config, a.logger, a.bot, a.repo)
+	a.controllers.botController = bot_controller.NewTelegramWebhookController(a.config, a.logger, a.bot, a.repo, a.audit, a.access, a.alerts, a.spam, a.triage, a.anonymity, a.sanitizer, a.evidence, a.files)
 	return a
 }
 
@@ -41,12 +52,85 @@ func (a *App) initPgxConn(ctx context.Context) *App {
 		log.Fatal(err)
 	}
 	a.pgxClient = client
-	a.logger.Info("init pgxclient", a.pgxClient)
+	a.logger.Info(fmt.Sprintf("init pgxclient %v", a.pgxClient))
+	return a
+}
+
+func (a *App) initKeyring(_ context.Context) *App {
+	keyring, err := envelope.NewKeyring(a.config.Bot.Encryption)
+	if err != nil {
+		log.Fatal(err)
+	}
+	a.keyring = keyring
 	return a
 }
 
 func (a *App) initRepo(_ context.Context) *App {
-	a.repo = repo.NewRepo(a.pgxClient, a.logger)
+	a.repo = repo.NewRepo(a.pgxClient, a.logger, a.keyring)
+	return a
+}
+
+func (a *App) initAudit(_ context.Context) *App {
+	a.audit = audit.NewService(a.repo, a.logger)
+	return a
+}
+
+func (a *App) initAccess(_ context.Context) *App {
+	a.access = access.NewService(a.config.Bot.Roles, a.repo, a.logger)
+	return a
+}
+
+func (a *App) initAlerts(_ context.Context) *App {
+	a.alerts = alert.NewService(a.config.Bot.Alerts, a.config.Bot.Roles.SuperAdmins, a.bot, a.logger)
+	return a
+}
+
+func (a *App) initSpam(_ context.Context) *App {
+	a.spam = spam.NewFilter(a.config.Bot.Spam, nil, a.repo, a.logger)
+	return a
+}
+
+func (a *App) initTriage(_ context.Context) *App {
+	matcher, err := triage.NewMatcher(a.config.Bot.Triage)
+	if err != nil {
+		log.Fatal(err)
+	}
+	a.triage = matcher
+	return a
+}
+
+func (a *App) initAnonymity(ctx context.Context) *App {
+	service, err := anonymity.NewService(a.config.Bot.Anonymity, a.repo, a.logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = service.UpgradePseudonyms(ctx); err != nil {
+		log.Fatal(err)
+	}
+	a.anonymity = service
+	return a
+}
+
+func (a *App) initFileStore(_ context.Context) *App {
+	store, err := filestore.New(a.config.Bot.Files)
+	if err != nil {
+		log.Fatal(err)
+	}
+	a.files = filestore.NewEncrypted(store, a.keyring)
+	return a
+}
+
+func (a *App) initSanitize(_ context.Context) *App {
+	a.sanitizer = sanitize.NewService(a.config.Bot.Sanitize, a.bot, a.repo, a.files, a.logger)
+	return a
+}
+
+func (a *App) initEvidence(_ context.Context) *App {
+	service, err := evidence.NewService(a.config.Bot.Evidence, a.bot, a.repo, a.logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	a.evidence = service
 	return a
 }
 
@@ -56,7 +140,7 @@ func (a *App) iniControllers(_ context.Context) *App {
 }
 
 func (a *App) initServer(_ context.Context) *App {
-	a.controllers.restController = controller.NewRestController(a.config, a.logger, a.controllers.botController)
+	a.controllers.restController = controller.NewRestController(a.config, a.logger, a.controllers.botController, a.controllers.botController)
 
 	a.server = &http.Server{
 		Addr:         a.config.HTTPServer.Address,
@@ -0,0 +1,176 @@
+package callback
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Version текущая версия формата callback_data. С версии 2 кнопки карточки обращения
+	// содержат только ID обращения: callback_data видна всем в чате и не должна раскрывать источник
+	Version = 2
+	// MaxDataLen ограничение Telegram на длину callback_data в байтах
+	MaxDataLen = 64
+
+	separator = ":"
+)
+
+// Действия inline кнопок, коды короткие чтобы уложиться в MaxDataLen
+const (
+	ActionClose   = "cl"
+	ActionIgnore  = "ig"
+	ActionTake    = "tk"
+	ActionUrgent  = "ur"
+	ActionSpam    = "sp"
+	ActionBan     = "bn"
+	ActionReopen  = "ro"
+	ActionHistory = "hs"
+	ActionRelease = "rl"
+	ActionDiscard = "dq"
+	// ActionCategoryMenu меню смены темы на карточке
+	ActionCategoryMenu = "cm"
+	// ActionSetCategory выбор темы редактором, тема 0 - возврат к карточке
+	ActionSetCategory = "cs"
+	// ActionUserCategory выбор темы пользователем в личном чате
+	ActionUserCategory = "uc"
+	// ActionRegionLetter список регионов на выбранную букву
+	ActionRegionLetter = "rk"
+	// ActionRegionBack возврат к выбору буквы региона
+	ActionRegionBack = "rb"
+	// ActionUserRegion выбор региона пользователем
+	ActionUserRegion = "rs"
+)
+
+// cardActions действия кнопок карточки обращения: в версии 1 первым аргументом шел ID пользователя
+var cardActions = []string{
+	ActionClose, ActionTake, ActionUrgent, ActionSpam, ActionBan, ActionReopen,
+	ActionHistory, ActionCategoryMenu, ActionSetCategory,
+}
+
+var (
+	// ErrTooLong закодированные данные не помещаются в callback_data
+	ErrTooLong = errors.New("callback data too long")
+	// ErrMalformed данные кнопки не удалось разобрать
+	ErrMalformed = errors.New("malformed callback data")
+	// ErrUnsupportedVersion кнопка создана несовместимой версией бота
+	ErrUnsupportedVersion = errors.New("unsupported callback data version")
+)
+
+// Data действие inline кнопки и его аргументы
+type Data struct {
+	Version int
+	Action  string
+	Args    []int64
+	// LegacyUserID ID пользователя из кнопок карточки старых версий, в которых еще нет ID обращения
+	LegacyUserID int64
+}
+
+// Arg возвращает i-й аргумент или 0, если его нет
+func (d Data) Arg(i int) int64 {
+	if i < 0 || i >= len(d.Args) {
+		return 0
+	}
+
+	return d.Args[i]
+}
+
+// Encode кодирует действие и аргументы в строку вида "1:cl:5yc1s", числа пишутся в base36
+func Encode(action string, args ...int64) (string, error) {
+	if action == "" || strings.Contains(action, separator) {
+		return "", fmt.Errorf("%w: invalid action %q", ErrMalformed, action)
+	}
+
+	parts := make([]string, 0, len(args)+2)
+	parts = append(parts, strconv.Itoa(Version), action)
+	for _, arg := range args {
+		parts = append(parts, strconv.FormatInt(arg, 36))
+	}
+
+	data := strings.Join(parts, separator)
+	if len(data) > MaxDataLen {
+		return "", fmt.Errorf("%w: %d bytes", ErrTooLong, len(data))
+	}
+
+	return data, nil
+}
+
+// MustEncode как Encode, но паникует при ошибке. Для кнопок с заранее известной длиной данных
+func MustEncode(action string, args ...int64) string {
+	data, err := Encode(action, args...)
+	if err != nil {
+		panic(err)
+	}
+
+	return data
+}
+
+// Decode разбирает callback_data, в том числе кнопки старого формата "close_<id>" и "ignore"
+func Decode(data string) (Data, error) {
+	if legacy, ok := decodeLegacy(data); ok {
+		return legacy, nil
+	}
+
+	parts := strings.Split(data, separator)
+	if len(parts) < 2 || parts[1] == "" {
+		return Data{}, fmt.Errorf("%w: %q", ErrMalformed, data)
+	}
+
+	version, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return Data{}, fmt.Errorf("%w: %q", ErrMalformed, data)
+	}
+	if version < 1 || version > Version {
+		return Data{}, fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+
+	args := make([]int64, 0, len(parts)-2)
+	for _, part := range parts[2:] {
+		arg, err := strconv.ParseInt(part, 36, 64)
+		if err != nil {
+			return Data{}, fmt.Errorf("%w: %q", ErrMalformed, data)
+		}
+		args = append(args, arg)
+	}
+
+	decoded := Data{Version: version, Action: parts[1], Args: args}
+	if version == 1 {
+		decoded = upgradeCard(decoded)
+	}
+
+	return decoded, nil
+}
+
+// upgradeCard приводит кнопку карточки версии 1 к текущему порядку аргументов:
+// ID пользователя убирается из аргументов, дальше идут ID обращения и остальные аргументы
+func upgradeCard(data Data) Data {
+	if !slices.Contains(cardActions, data.Action) || len(data.Args) == 0 {
+		return data
+	}
+
+	data.LegacyUserID = data.Args[0]
+	data.Args = data.Args[1:]
+
+	return data
+}
+
+// decodeLegacy разбор кнопок, отправленных до появления версионированного формата
+func decodeLegacy(data string) (Data, bool) {
+	if data == "ignore" {
+		return Data{Action: ActionIgnore}, true
+	}
+
+	userID, found := strings.CutPrefix(data, "close_")
+	if !found {
+		return Data{}, false
+	}
+
+	id, err := strconv.ParseInt(userID, 10, 64)
+	if err != nil {
+		return Data{}, false
+	}
+
+	return Data{Action: ActionClose, LegacyUserID: id}, true
+}
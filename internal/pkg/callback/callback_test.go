@@ -0,0 +1,167 @@
+package callback
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		name   string
+		action string
+		args   []int64
+		want   string
+	}{
+		{name: "no args", action: ActionIgnore, want: "2:ig"},
+		{name: "appeal", action: ActionClose, args: []int64{42}, want: "2:cl:16"},
+		{name: "appeal and category", action: ActionSetCategory, args: []int64{42, 3}, want: "2:cs:16:3"},
+		{name: "zero and negative", action: ActionRegionLetter, args: []int64{0, -7}, want: "2:rk:0:-7"},
+		{name: "max int64", action: ActionHistory, args: []int64{math.MaxInt64}, want: "2:hs:1y2p0ij32e8e7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Encode(tt.action, tt.args...)
+			if err != nil || data != tt.want {
+				t.Fatalf("Encode() = %q, %v, want %q", data, err, tt.want)
+			}
+
+			decoded, err := Decode(data)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if decoded.Version != Version || decoded.Action != tt.action || !slices.Equal(decoded.Args, tt.args) ||
+				decoded.LegacyUserID != 0 {
+				t.Errorf("Decode() = %+v, want action %q args %v", decoded, tt.action, tt.args)
+			}
+		})
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		action  string
+		args    []int64
+		wantErr error
+	}{
+		{name: "empty action", action: "", wantErr: ErrMalformed},
+		{name: "separator in action", action: "c:l", wantErr: ErrMalformed},
+		{
+			name:    "longer than callback_data limit",
+			action:  ActionSetCategory,
+			args:    []int64{math.MaxInt64, math.MaxInt64, math.MaxInt64, math.MaxInt64, math.MaxInt64},
+			wantErr: ErrTooLong,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Encode(tt.action, tt.args...); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Encode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// четыре максимальных аргумента еще помещаются в 64 байта
+	data, err := Encode(ActionSetCategory, math.MaxInt64, math.MaxInt64, math.MaxInt64, math.MaxInt64)
+	if err != nil || len(data) > MaxDataLen {
+		t.Errorf("Encode() = %d bytes, %v, want at most %d", len(data), err, MaxDataLen)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("MustEncode() did not panic on invalid action")
+		}
+	}()
+	MustEncode("")
+}
+
+func TestDecodeVersion1(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		action   string
+		args     []int64
+		legacyID int64
+	}{
+		{name: "card button", data: "1:cl:5yc1s", action: ActionClose, legacyID: 10000000},
+		{name: "card button with appeal", data: "1:tk:5yc1s:16", action: ActionTake, args: []int64{42}, legacyID: 10000000},
+		{name: "category", data: "1:cs:5yc1s:16:3", action: ActionSetCategory, args: []int64{42, 3}, legacyID: 10000000},
+		{name: "card button without args", data: "1:ur", action: ActionUrgent},
+		{name: "not a card button", data: "1:rl:16", action: ActionRelease, args: []int64{42}},
+		{name: "user category", data: "1:uc:3", action: ActionUserCategory, args: []int64{3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := Decode(tt.data)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if decoded.Version != 1 || decoded.Action != tt.action || decoded.LegacyUserID != tt.legacyID ||
+				len(decoded.Args) != len(tt.args) || !slices.Equal(decoded.Args, tt.args) {
+				t.Errorf("Decode() = %+v, want action %q args %v legacy user %d", decoded, tt.action, tt.args, tt.legacyID)
+			}
+		})
+	}
+}
+
+func TestDecodeLegacy(t *testing.T) {
+	tests := []struct {
+		data     string
+		action   string
+		legacyID int64
+	}{
+		{data: "ignore", action: ActionIgnore},
+		{data: "close_123456789", action: ActionClose, legacyID: 123456789},
+	}
+
+	for _, tt := range tests {
+		decoded, err := Decode(tt.data)
+		if err != nil {
+			t.Fatalf("Decode(%q) error = %v", tt.data, err)
+		}
+		if decoded.Action != tt.action || decoded.LegacyUserID != tt.legacyID || len(decoded.Args) != 0 {
+			t.Errorf("Decode(%q) = %+v", tt.data, decoded)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		data    string
+		wantErr error
+	}{
+		{data: "", wantErr: ErrMalformed},
+		{data: "close", wantErr: ErrMalformed},
+		{data: "close_", wantErr: ErrMalformed},
+		{data: "close_abc", wantErr: ErrMalformed},
+		{data: "2:", wantErr: ErrMalformed},
+		{data: "2::16", wantErr: ErrMalformed},
+		{data: "x:cl:16", wantErr: ErrMalformed},
+		{data: "2:cl:16!", wantErr: ErrMalformed},
+		{data: "2:cl::16", wantErr: ErrMalformed},
+		{data: "2:cl:" + strings.Repeat("z", 20), wantErr: ErrMalformed},
+		{data: "0:cl:16", wantErr: ErrUnsupportedVersion},
+		{data: "3:cl:16", wantErr: ErrUnsupportedVersion},
+	}
+
+	for _, tt := range tests {
+		if _, err := Decode(tt.data); !errors.Is(err, tt.wantErr) {
+			t.Errorf("Decode(%q) error = %v, want %v", tt.data, err, tt.wantErr)
+		}
+	}
+}
+
+func TestArg(t *testing.T) {
+	data := Data{Args: []int64{42, 3}}
+	for i, want := range map[int]int64{-1: 0, 0: 42, 1: 3, 2: 0} {
+		if got := data.Arg(i); got != want {
+			t.Errorf("Arg(%d) = %d, want %d", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"medrussia_news_bot/internal/config"
+	"os"
+	"strings"
+)
+
+// keySize ключи AES-256
+const keySize = 32
+
+// blobMagic начало зашифрованного файла
+var blobMagic = []byte("MRENC1")
+
+var (
+	// ErrUnknownKey ключа с таким ID нет в конфиге, данные зашифрованы удаленным ключом
+	ErrUnknownKey = errors.New("unknown encryption key")
+	// ErrNoActiveKey шифрование включено, но активный ключ не задан или не найден
+	ErrNoActiveKey = errors.New("active encryption key is not configured")
+	// ErrCorrupted данные повреждены или изменены
+	ErrCorrupted = errors.New("encrypted data is corrupted")
+	// ErrNotEncrypted при включенном шифровании прочитан файл без заголовка: либо он сохранен
+	// до включения и не перешифрован командой reencrypt, либо подменен в хранилище
+	ErrNotEncrypted = errors.New("data is not encrypted")
+)
+
+// Keyring ключи шифрования (KEK) по ID. Каждая строка и каждый файл шифруются своим
+// случайным ключом данных (DEK), который хранится рядом с данными, зашифрованный активным KEK.
+// Старые ключи нужны для чтения, пока данные не перешифрованы командой reencrypt
+type Keyring struct {
+	keys    map[string]cipher.AEAD
+	active  string
+	enabled bool
+}
+
+// NewKeyring ключи из конфига. Без включенного шифрования ключи все равно загружаются,
+// чтобы читать ранее зашифрованные данные
+func NewKeyring(cfg config.EncryptionConfig) (*Keyring, error) {
+	k := &Keyring{keys: make(map[string]cipher.AEAD, len(cfg.Keys)), active: cfg.ActiveKey, enabled: cfg.Enabled}
+
+	for _, key := range cfg.Keys {
+		if key.ID == "" || len(key.ID) > 255 {
+			return nil, errors.New("encryption key id must be 1-255 bytes")
+		}
+		if _, ok := k.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate encryption key id %q", key.ID)
+		}
+
+		raw, err := loadKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %q: %w", key.ID, err)
+		}
+		aead, err := newAEAD(raw)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %q: %w", key.ID, err)
+		}
+		k.keys[key.ID] = aead
+	}
+
+	if k.enabled {
+		if _, ok := k.keys[k.active]; !ok {
+			return nil, ErrNoActiveKey
+		}
+	}
+
+	return k, nil
+}
+
+// loadKey ключ из конфига или из файла, в обоих случаях 32 байта в base64
+func loadKey(key config.EncryptionKey) ([]byte, error) {
+	encoded := key.Key
+	if key.File != "" {
+		data, err := os.ReadFile(key.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file: %w", err)
+		}
+		encoded = string(data)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("key must be base64: %w", err)
+	}
+	if len(raw) != keySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", keySize, len(raw))
+	}
+
+	return raw, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// Enabled шифруются ли новые данные
+func (k *Keyring) Enabled() bool {
+	return k != nil && k.enabled
+}
+
+// ActiveKeyID ID ключа, которым шифруются новые данные, пустой если шифрование выключено
+func (k *Keyring) ActiveKeyID() string {
+	if !k.Enabled() {
+		return ""
+	}
+
+	return k.active
+}
+
+// DataKey ключ данных одной строки или файла
+type DataKey struct {
+	// KeyID ключ, которым зашифрован ключ данных
+	KeyID string
+	// Wrapped ключ данных, зашифрованный ключом KeyID
+	Wrapped []byte
+	aead    cipher.AEAD
+	raw     []byte
+}
+
+// NewDataKey новый случайный ключ данных, зашифрованный активным ключом
+func (k *Keyring) NewDataKey() (*DataKey, error) {
+	raw := make([]byte, keySize)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	return k.wrap(raw)
+}
+
+// OpenDataKey расшифровка ключа данных
+func (k *Keyring) OpenDataKey(keyID string, wrapped []byte) (*DataKey, error) {
+	kek, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+
+	raw, err := open(kek, wrapped, []byte(keyID))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DataKey{KeyID: keyID, Wrapped: wrapped, aead: aead, raw: raw}, nil
+}
+
+// Rewrap тот же ключ данных, зашифрованный активным ключом. Сами данные перешифровывать не нужно,
+// но и от утечки старого ключа вместе с копией базы это не защищает: ключ данных остается прежним
+func (k *Keyring) Rewrap(key *DataKey) (*DataKey, error) {
+	return k.wrap(key.raw)
+}
+
+func (k *Keyring) wrap(raw []byte) (*DataKey, error) {
+	if !k.Enabled() {
+		return nil, ErrNoActiveKey
+	}
+
+	wrapped, err := seal(k.keys[k.active], raw, []byte(k.active))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DataKey{KeyID: k.active, Wrapped: wrapped, aead: aead, raw: raw}, nil
+}
+
+// SealString шифрует значение поля, aad - имя поля, чтобы значения нельзя было переставить между полями
+func (d *DataKey) SealString(value, aad string) (string, error) {
+	sealed, err := seal(d.aead, []byte(value), []byte(aad))
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// OpenString расшифровывает значение поля
+func (d *DataKey) OpenString(value, aad string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", ErrCorrupted
+	}
+
+	plain, err := open(d.aead, sealed, []byte(aad))
+	if err != nil {
+		return "", err
+	}
+
+	return string(plain), nil
+}
+
+// Seal шифрует файл активным ключом. Формат: MRENC1, длина и ID ключа,
+// длина и зашифрованный ключ данных, затем nonce и шифротекст. Заголовок входит в AAD
+func (k *Keyring) Seal(data []byte) ([]byte, error) {
+	key, err := k.NewDataKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return sealBlob(key, data)
+}
+
+// Open расшифровывает файл. Файлы без заголовка возвращаются как есть, только пока шифрование
+// выключено. При включенном шифровании такой файл отклоняется, иначе подмена файла в хранилище
+// открытым текстом прошла бы незаметно. Старые файлы после включения перешифровывает reencrypt
+func (k *Keyring) Open(data []byte) ([]byte, error) {
+	keyID, wrapped, body, ok, err := parseBlob(data)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if k.Enabled() {
+			return nil, ErrNotEncrypted
+		}
+		return data, nil
+	}
+
+	key, err := k.OpenDataKey(keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	return open(key.aead, body, data[:len(data)-len(body)])
+}
+
+// Reseal файл под активным ключом, при выключенном шифровании - расшифрованный.
+// changed = false, если файл уже в нужном виде. Без full ключ данных сохраняется, меняется
+// только его обертка. С full файл шифруется новым ключом данных, даже если он уже под
+// активным ключом: это нужно, если старый ключ скомпрометирован и могли утечь копии
+// зашифрованных ключей данных
+func (k *Keyring) Reseal(data []byte, full bool) (resealed []byte, changed bool, err error) {
+	keyID, wrapped, body, ok, err := parseBlob(data)
+	if err != nil {
+		return nil, false, err
+	}
+	if keyID == k.ActiveKeyID() && !(full && ok) {
+		return data, false, nil
+	}
+	if !ok {
+		resealed, err = k.Seal(data)
+		return resealed, true, err
+	}
+
+	key, err := k.OpenDataKey(keyID, wrapped)
+	if err != nil {
+		return nil, false, err
+	}
+	plain, err := open(key.aead, body, data[:len(data)-len(body)])
+	if err != nil {
+		return nil, false, err
+	}
+	if !k.Enabled() {
+		return plain, true, nil
+	}
+	if full {
+		resealed, err = k.Seal(plain)
+		return resealed, true, err
+	}
+
+	key, err = k.Rewrap(key)
+	if err != nil {
+		return nil, false, err
+	}
+	resealed, err = sealBlob(key, plain)
+
+	return resealed, true, err
+}
+
+func sealBlob(key *DataKey, data []byte) ([]byte, error) {
+	var header bytes.Buffer
+	header.Write(blobMagic)
+	header.WriteByte(byte(len(key.KeyID)))
+	header.WriteString(key.KeyID)
+	_ = binary.Write(&header, binary.BigEndian, uint16(len(key.Wrapped)))
+	header.Write(key.Wrapped)
+
+	body, err := seal(key.aead, data, header.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	return append(header.Bytes(), body...), nil
+}
+
+// parseBlob разбор заголовка файла, ok = false если файл не зашифрован
+func parseBlob(data []byte) (keyID string, wrapped, body []byte, ok bool, err error) {
+	if !bytes.HasPrefix(data, blobMagic) {
+		return "", nil, nil, false, nil
+	}
+
+	rest := data[len(blobMagic):]
+	if len(rest) < 1 || len(rest) < 1+int(rest[0])+2 {
+		return "", nil, nil, false, ErrCorrupted
+	}
+	keyID, rest = string(rest[1:1+int(rest[0])]), rest[1+int(rest[0]):]
+
+	size := int(binary.BigEndian.Uint16(rest))
+	if len(rest) < 2+size {
+		return "", nil, nil, false, ErrCorrupted
+	}
+
+	return keyID, rest[2 : 2+size], rest[2+size:], true, nil
+}
+
+// seal nonce + шифротекст AES-GCM
+func seal(aead cipher.AEAD, plain, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plain)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return aead.Seal(nonce, nonce, plain, aad), nil
+}
+
+func open(aead cipher.AEAD, sealed, aad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrCorrupted
+	}
+
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], aad)
+	if err != nil {
+		return nil, ErrCorrupted
+	}
+
+	return plain, nil
+}
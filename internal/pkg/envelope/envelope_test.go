@@ -0,0 +1,328 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"medrussia_news_bot/internal/config"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testKey ключ из повторяющегося байта в base64
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, keySize))
+}
+
+// newTestKeyring ключи с ID ids, ключ зависит только от ID, чтобы разные связки читали одни данные
+func newTestKeyring(t *testing.T, enabled bool, active string, ids ...string) *Keyring {
+	t.Helper()
+	cfg := config.EncryptionConfig{Enabled: enabled, ActiveKey: active}
+	for _, id := range ids {
+		cfg.Keys = append(cfg.Keys, config.EncryptionKey{ID: id, Key: testKey(id[len(id)-1])})
+	}
+
+	keyring, err := NewKeyring(cfg)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	return keyring
+}
+
+// dataKeyOf ключ данных файла
+func dataKeyOf(t *testing.T, keyring *Keyring, blob []byte) []byte {
+	t.Helper()
+	keyID, wrapped, _, ok, err := parseBlob(blob)
+	if err != nil || !ok {
+		t.Fatalf("parseBlob() ok = %v, error = %v", ok, err)
+	}
+	key, err := keyring.OpenDataKey(keyID, wrapped)
+	if err != nil {
+		t.Fatalf("OpenDataKey() error = %v", err)
+	}
+
+	return key.raw
+}
+
+func TestNewKeyring(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, []byte(testKey(9)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cfg     config.EncryptionConfig
+		wantErr bool
+		// is ожидаемая ошибка, если ее можно проверить через errors.Is
+		is error
+	}{
+		{
+			name: "key from file",
+			cfg:  config.EncryptionConfig{Enabled: true, ActiveKey: "k1", Keys: []config.EncryptionKey{{ID: "k1", File: keyFile}}},
+		},
+		{
+			name: "disabled without keys",
+			cfg:  config.EncryptionConfig{},
+		},
+		{
+			name:    "active key missing",
+			cfg:     config.EncryptionConfig{Enabled: true, ActiveKey: "k2", Keys: []config.EncryptionKey{{ID: "k1", Key: testKey(1)}}},
+			wantErr: true,
+			is:      ErrNoActiveKey,
+		},
+		{
+			name: "duplicate id",
+			cfg: config.EncryptionConfig{Keys: []config.EncryptionKey{
+				{ID: "k1", Key: testKey(1)},
+				{ID: "k1", Key: testKey(2)},
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "short key",
+			cfg:     config.EncryptionConfig{Keys: []config.EncryptionKey{{ID: "k1", Key: base64.StdEncoding.EncodeToString([]byte("short"))}}},
+			wantErr: true,
+		},
+		{
+			name:    "not base64",
+			cfg:     config.EncryptionConfig{Keys: []config.EncryptionKey{{ID: "k1", Key: "not base64!"}}},
+			wantErr: true,
+		},
+		{
+			name:    "empty id",
+			cfg:     config.EncryptionConfig{Keys: []config.EncryptionKey{{Key: testKey(1)}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeyring(tt.cfg)
+			if (err != nil) != tt.wantErr || (tt.is != nil && !errors.Is(err, tt.is)) {
+				t.Errorf("NewKeyring() error = %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSealFormat(t *testing.T) {
+	keyring := newTestKeyring(t, true, "k1", "k1")
+	data := []byte("secret document")
+
+	blob, err := keyring.Seal(data)
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+
+	// MRENC1 | длина ID | ID | длина обертки u16 | обертка | nonce + шифротекст
+	if !bytes.HasPrefix(blob, []byte("MRENC1\x02k1")) {
+		t.Fatalf("blob header = %q, want MRENC1, id length and id", blob[:9])
+	}
+	rest := blob[len("MRENC1\x02k1"):]
+	wrappedLen := int(binary.BigEndian.Uint16(rest))
+	if want := 12 + keySize + 16; wrappedLen != want {
+		t.Errorf("wrapped key length = %d, want %d", wrappedLen, want)
+	}
+	body := rest[2+wrappedLen:]
+	if want := 12 + len(data) + 16; len(body) != want {
+		t.Errorf("body length = %d, want %d", len(body), want)
+	}
+	if bytes.Contains(blob, data) {
+		t.Error("blob contains plain text")
+	}
+
+	plain, err := keyring.Open(blob)
+	if err != nil || !bytes.Equal(plain, data) {
+		t.Fatalf("Open() = %q, %v, want %q", plain, err, data)
+	}
+
+	other, _ := keyring.Seal(data)
+	if bytes.Equal(dataKeyOf(t, keyring, blob), dataKeyOf(t, keyring, other)) {
+		t.Error("two files share a data key")
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	keyring := newTestKeyring(t, true, "k1", "k1")
+	blob, err := keyring.Seal([]byte("secret document"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	headerLen := len(blob) - (12 + len("secret document") + 16)
+
+	tamper := func(i int) []byte {
+		changed := bytes.Clone(blob)
+		changed[i] ^= 1
+		return changed
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{name: "body changed", data: tamper(len(blob) - 1), wantErr: ErrCorrupted},
+		{name: "wrapped key changed", data: tamper(headerLen - 1), wantErr: ErrCorrupted},
+		{name: "key id changed", data: tamper(len("MRENC1\x02")), wantErr: ErrUnknownKey},
+		{name: "truncated header", data: blob[:len("MRENC1\x02k1")+1], wantErr: ErrCorrupted},
+		{name: "magic only", data: []byte("MRENC1"), wantErr: ErrCorrupted},
+		{name: "truncated body", data: blob[:headerLen+4], wantErr: ErrCorrupted},
+		{name: "plain text", data: []byte("replaced by attacker"), wantErr: ErrNotEncrypted},
+		{name: "empty", data: []byte{}, wantErr: ErrNotEncrypted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := keyring.Open(tt.data); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Open() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOpenDisabled(t *testing.T) {
+	sealing := newTestKeyring(t, true, "k1", "k1")
+	blob, err := sealing.Seal([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyring := newTestKeyring(t, false, "", "k1")
+	if plain, err := keyring.Open([]byte("old file")); err != nil || string(plain) != "old file" {
+		t.Errorf("Open() plain = %q, %v, want file as is", plain, err)
+	}
+	if plain, err := keyring.Open(blob); err != nil || string(plain) != "secret" {
+		t.Errorf("Open() sealed = %q, %v, want decrypted", plain, err)
+	}
+	if _, err = keyring.Seal([]byte("new")); !errors.Is(err, ErrNoActiveKey) {
+		t.Errorf("Seal() error = %v, want ErrNoActiveKey", err)
+	}
+}
+
+func TestReseal(t *testing.T) {
+	data := []byte("secret document")
+	old := newTestKeyring(t, true, "k1", "k1")
+	blob, err := old.Seal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := newTestKeyring(t, true, "k2", "k1", "k2")
+	disabled := newTestKeyring(t, false, "", "k1", "k2")
+
+	t.Run("rewrap keeps data key", func(t *testing.T) {
+		resealed, changed, err := rotated.Reseal(blob, false)
+		if err != nil || !changed {
+			t.Fatalf("Reseal() changed = %v, error = %v", changed, err)
+		}
+		if keyID, _, _, _, _ := parseBlob(resealed); keyID != "k2" {
+			t.Errorf("key id = %q, want k2", keyID)
+		}
+		if !bytes.Equal(dataKeyOf(t, rotated, resealed), dataKeyOf(t, rotated, blob)) {
+			t.Error("rewrap changed the data key")
+		}
+		if plain, err := rotated.Open(resealed); err != nil || !bytes.Equal(plain, data) {
+			t.Errorf("Open() = %q, %v", plain, err)
+		}
+
+		onlyNew := newTestKeyring(t, true, "k2", "k2")
+		if _, err = onlyNew.Open(blob); !errors.Is(err, ErrUnknownKey) {
+			t.Errorf("Open() old blob without old key error = %v, want ErrUnknownKey", err)
+		}
+		if _, err = onlyNew.Open(resealed); err != nil {
+			t.Errorf("Open() resealed blob without old key error = %v", err)
+		}
+
+		again, changed, err := rotated.Reseal(resealed, false)
+		if err != nil || changed || !bytes.Equal(again, resealed) {
+			t.Errorf("Reseal() under active key changed = %v, error = %v", changed, err)
+		}
+	})
+
+	t.Run("full changes data key", func(t *testing.T) {
+		resealed, changed, err := rotated.Reseal(blob, true)
+		if err != nil || !changed {
+			t.Fatalf("Reseal() changed = %v, error = %v", changed, err)
+		}
+		if bytes.Equal(dataKeyOf(t, rotated, resealed), dataKeyOf(t, rotated, blob)) {
+			t.Error("full reseal kept the data key")
+		}
+
+		again, changed, err := rotated.Reseal(resealed, true)
+		if err != nil || !changed || bytes.Equal(dataKeyOf(t, rotated, again), dataKeyOf(t, rotated, resealed)) {
+			t.Errorf("full Reseal() under active key changed = %v, error = %v, want new data key", changed, err)
+		}
+		if plain, err := rotated.Open(again); err != nil || !bytes.Equal(plain, data) {
+			t.Errorf("Open() = %q, %v", plain, err)
+		}
+	})
+
+	t.Run("plain file is sealed", func(t *testing.T) {
+		resealed, changed, err := rotated.Reseal(data, false)
+		if err != nil || !changed {
+			t.Fatalf("Reseal() changed = %v, error = %v", changed, err)
+		}
+		if plain, err := rotated.Open(resealed); err != nil || !bytes.Equal(plain, data) {
+			t.Errorf("Open() = %q, %v", plain, err)
+		}
+	})
+
+	t.Run("disabled decrypts", func(t *testing.T) {
+		plain, changed, err := disabled.Reseal(blob, true)
+		if err != nil || !changed || !bytes.Equal(plain, data) {
+			t.Errorf("Reseal() = %q, changed = %v, error = %v", plain, changed, err)
+		}
+
+		plain, changed, err = disabled.Reseal(data, true)
+		if err != nil || changed || !bytes.Equal(plain, data) {
+			t.Errorf("Reseal() plain = %q, changed = %v, error = %v", plain, changed, err)
+		}
+	})
+
+	t.Run("corrupted blob", func(t *testing.T) {
+		changed := bytes.Clone(blob)
+		changed[len(changed)-1] ^= 1
+		if _, _, err := rotated.Reseal(changed, false); !errors.Is(err, ErrCorrupted) {
+			t.Errorf("Reseal() error = %v, want ErrCorrupted", err)
+		}
+	})
+}
+
+func TestDataKeyStrings(t *testing.T) {
+	keyring := newTestKeyring(t, true, "k1", "k1")
+	key, err := keyring.NewDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := key.SealString("Иван Петров", "source_identities.first_name")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opened, err := keyring.OpenDataKey(key.KeyID, key.Wrapped)
+	if err != nil {
+		t.Fatalf("OpenDataKey() error = %v", err)
+	}
+	if plain, err := opened.OpenString(sealed, "source_identities.first_name"); err != nil || plain != "Иван Петров" {
+		t.Errorf("OpenString() = %q, %v", plain, err)
+	}
+	if _, err = opened.OpenString(sealed, "source_identities.last_name"); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("OpenString() other field error = %v, want ErrCorrupted", err)
+	}
+	if _, err = opened.OpenString("not base64!", "messages.text"); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("OpenString() garbage error = %v, want ErrCorrupted", err)
+	}
+
+	rewrapped, err := newTestKeyring(t, true, "k2", "k1", "k2").Rewrap(opened)
+	if err != nil || rewrapped.KeyID != "k2" {
+		t.Fatalf("Rewrap() = %v, %v", rewrapped, err)
+	}
+	if plain, err := rewrapped.OpenString(sealed, "source_identities.first_name"); err != nil || plain != "Иван Петров" {
+		t.Errorf("OpenString() after rewrap = %q, %v", plain, err)
+	}
+}
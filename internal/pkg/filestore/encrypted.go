@@ -0,0 +1,69 @@
+package filestore
+
+import (
+	"context"
+	"fmt"
+	"medrussia_news_bot/internal/pkg/envelope"
+)
+
+// Encrypted шифрует файлы перед записью в хранилище. Файлы, сохраненные до включения
+// шифрования, читаются как есть, только пока шифрование выключено: после включения
+// их нужно перешифровать командой reencrypt
+type Encrypted struct {
+	store   FileStore
+	keyring *envelope.Keyring
+}
+
+// NewEncrypted конструктор
+func NewEncrypted(store FileStore, keyring *envelope.Keyring) *Encrypted {
+	return &Encrypted{store: store, keyring: keyring}
+}
+
+// Put шифрует файл активным ключом, если шифрование включено
+func (e *Encrypted) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if !e.keyring.Enabled() {
+		return e.store.Put(ctx, key, data, contentType)
+	}
+
+	sealed, err := e.keyring.Seal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt file: %w", err)
+	}
+
+	// тип содержимого не сохраняется, он раскрывал бы вид файла
+	return e.store.Put(ctx, key, sealed, "application/octet-stream")
+}
+
+// Get читает и расшифровывает файл
+func (e *Encrypted) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := e.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := e.keyring.Open(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt file %s: %w", key, err)
+	}
+
+	return plain, nil
+}
+
+// Reencrypt перешифровывает файл под активный ключ, changed = false если файл уже в нужном виде.
+// full - шифровать новым ключом данных, а не только менять его обертку
+func (e *Encrypted) Reencrypt(ctx context.Context, key string, full bool) (changed bool, err error) {
+	data, err := e.store.Get(ctx, key)
+	if err != nil {
+		return false, err
+	}
+
+	resealed, changed, err := e.keyring.Reseal(data, full)
+	if err != nil {
+		return false, fmt.Errorf("failed to reencrypt file %s: %w", key, err)
+	}
+	if !changed {
+		return false, nil
+	}
+
+	return true, e.store.Put(ctx, key, resealed, "application/octet-stream")
+}
@@ -0,0 +1,51 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"medrussia_news_bot/internal/config"
+	"path"
+	"strings"
+)
+
+// Виды хранилищ
+const (
+	StoreLocal = "local"
+	StoreS3    = "s3"
+)
+
+var (
+	// ErrNotFound файла нет в хранилище
+	ErrNotFound = errors.New("file not found in store")
+	// ErrInvalidKey ключ пустой или выходит за пределы хранилища
+	ErrInvalidKey = errors.New("invalid file key")
+)
+
+// FileStore хранилище файлов. Ключ - относительный путь через «/», например appeals/42/AgADBQAD
+type FileStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+// New хранилище из конфига: local или s3
+func New(cfg config.FilesConfig) (FileStore, error) {
+	switch cfg.Store {
+	case StoreLocal:
+		return NewLocal(cfg.Dir), nil
+	case StoreS3:
+		return NewS3(cfg.S3)
+	}
+
+	return nil, fmt.Errorf("unknown file store %q, expected %s or %s", cfg.Store, StoreLocal, StoreS3)
+}
+
+// cleanKey проверяет ключ: без абсолютных путей и выхода наверх через «..»
+func cleanKey(key string) (string, error) {
+	cleaned := path.Clean(strings.TrimSpace(key))
+	if cleaned == "." || strings.HasPrefix(cleaned, "/") || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+
+	return cleaned, nil
+}
@@ -0,0 +1,138 @@
+package filestore
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"medrussia_news_bot/internal/config"
+	"medrussia_news_bot/internal/pkg/envelope"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCleanKey(t *testing.T) {
+	tests := []struct {
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{key: "appeals/42/file.jpg", want: "appeals/42/file.jpg"},
+		{key: " appeals//42/./file.jpg ", want: "appeals/42/file.jpg"},
+		{key: "appeals/42/../43/file.jpg", want: "appeals/43/file.jpg"},
+		{key: "", wantErr: true},
+		{key: ".", wantErr: true},
+		{key: "/etc/passwd", wantErr: true},
+		{key: "..", wantErr: true},
+		{key: "../secret", wantErr: true},
+		{key: "appeals/../../secret", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := cleanKey(tt.key)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("cleanKey(%q) error = %v, want ErrInvalidKey", tt.key, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("cleanKey(%q) = %q, %v, want %q", tt.key, got, err, tt.want)
+		}
+	}
+}
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	dir := filepath.Join(root, "store")
+	store := NewLocal(dir)
+	data := []byte("file content")
+
+	if err := store.Put(ctx, "appeals/42/file.jpg", data, "image/jpeg"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	got, err := store.Get(ctx, "appeals/42/file.jpg")
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("Get() = %q, %v, want %q", got, err, data)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "appeals", "42", "file.jpg"))
+	if err != nil {
+		t.Fatalf("stored file: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("file mode = %o, want 600", perm)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(dir, "appeals", "42")); len(entries) != 1 {
+		t.Errorf("store dir has %d entries, temp file left behind", len(entries))
+	}
+
+	if err = store.Put(ctx, "appeals/42/file.jpg", []byte("new"), ""); err != nil {
+		t.Fatalf("Put() overwrite error = %v", err)
+	}
+	if got, _ = store.Get(ctx, "appeals/42/file.jpg"); string(got) != "new" {
+		t.Errorf("Get() after overwrite = %q, want %q", got, "new")
+	}
+
+	if _, err = store.Get(ctx, "appeals/42/missing.jpg"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() missing error = %v, want ErrNotFound", err)
+	}
+
+	if err = store.Put(ctx, "../outside", data, ""); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Put() traversal error = %v, want ErrInvalidKey", err)
+	}
+	if _, err = os.Stat(filepath.Join(root, "outside")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("file written outside the store dir")
+	}
+	if _, err = store.Get(ctx, "/etc/passwd"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Get() absolute key error = %v, want ErrInvalidKey", err)
+	}
+}
+
+func TestEncrypted(t *testing.T) {
+	ctx := context.Background()
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	keyring, err := envelope.NewKeyring(config.EncryptionConfig{
+		Enabled:   true,
+		ActiveKey: "k1",
+		Keys:      []config.EncryptionKey{{ID: "k1", Key: key}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := NewLocal(t.TempDir())
+	store := NewEncrypted(local, keyring)
+	data := []byte("file content")
+
+	if err = store.Put(ctx, "appeals/42/file.jpg", data, "image/jpeg"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if raw, _ := local.Get(ctx, "appeals/42/file.jpg"); bytes.Contains(raw, data) {
+		t.Error("file stored in plain text")
+	}
+	if got, err := store.Get(ctx, "appeals/42/file.jpg"); err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("Get() = %q, %v, want %q", got, err, data)
+	}
+
+	// файл, подмененный в хранилище открытым текстом, не выдается
+	if err = local.Put(ctx, "appeals/42/file.jpg", []byte("replaced"), ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = store.Get(ctx, "appeals/42/file.jpg"); !errors.Is(err, envelope.ErrNotEncrypted) {
+		t.Errorf("Get() plain file error = %v, want ErrNotEncrypted", err)
+	}
+
+	// reencrypt шифрует файлы, сохраненные до включения шифрования
+	changed, err := store.Reencrypt(ctx, "appeals/42/file.jpg", false)
+	if err != nil || !changed {
+		t.Fatalf("Reencrypt() changed = %v, error = %v", changed, err)
+	}
+	if got, err := store.Get(ctx, "appeals/42/file.jpg"); err != nil || string(got) != "replaced" {
+		t.Errorf("Get() after reencrypt = %q, %v", got, err)
+	}
+	if changed, err = store.Reencrypt(ctx, "appeals/42/file.jpg", false); err != nil || changed {
+		t.Errorf("Reencrypt() again changed = %v, error = %v", changed, err)
+	}
+}
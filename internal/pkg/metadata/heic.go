@@ -0,0 +1,274 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// isoBox бокс контейнера ISO BMFF: тип и границы содержимого в файле
+type isoBox struct {
+	boxType string
+	start   int
+	end     int
+}
+
+// stripHEIC затирает нулями элементы Exif и XMP контейнера HEIF. Размер файла и смещения
+// остальных элементов не меняются, поэтому изображение читается как раньше
+func stripHEIC(data []byte) ([]byte, []string, error) {
+	meta, ok, err := findBox(data, 0, len(data), "meta")
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return data, nil, nil
+	}
+	// meta - full box: версия и флаги перед вложенными боксами
+	childrenStart := meta.start + 4
+
+	iinf, ok, err := findBox(data, childrenStart, meta.end, "iinf")
+	if err != nil || !ok {
+		return nil, nil, fmt.Errorf("%w: heif iinf box not found", ErrCorrupted)
+	}
+	iloc, ok, err := findBox(data, childrenStart, meta.end, "iloc")
+	if err != nil || !ok {
+		return nil, nil, fmt.Errorf("%w: heif iloc box not found", ErrCorrupted)
+	}
+	idat, hasIdat, err := findBox(data, childrenStart, meta.end, "idat")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items, err := heifMetadataItems(data, iinf)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(items) == 0 {
+		return data, nil, nil
+	}
+
+	extents, err := heifItemExtents(data, iloc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clean := bytes.Clone(data)
+	removed := make([]string, 0)
+	for itemID, name := range items {
+		for _, extent := range extents[itemID] {
+			// данные элемента: весь файл для method 0, содержимое idat для method 1
+			base, limit := uint64(0), uint64(len(clean))
+			if extent.method == 1 {
+				if !hasIdat {
+					return nil, nil, fmt.Errorf("%w: heif idat box not found", ErrCorrupted)
+				}
+				base, limit = uint64(idat.start), uint64(idat.end)
+			} else if extent.method != 0 {
+				return nil, nil, fmt.Errorf("%w: heif item construction method %d", ErrUnsupportedFormat, extent.method)
+			}
+
+			// сравнения без сложения смещения и длины: значения из файла могут переполнить uint64
+			size := limit - base
+			if extent.length == 0 || extent.offset > size || extent.length > size-extent.offset {
+				return nil, nil, fmt.Errorf("%w: heif item %d out of range", ErrCorrupted, itemID)
+			}
+			start := base + extent.offset
+			clear(clean[start : start+extent.length])
+		}
+		removed = addOnce(removed, name)
+	}
+	slices.Sort(removed)
+
+	return clean, removed, nil
+}
+
+// findBox первый бокс типа boxType среди боксов на отрезке [start, end)
+func findBox(data []byte, start, end int, boxType string) (isoBox, bool, error) {
+	for i := start; i+8 <= end; {
+		size := uint64(binary.BigEndian.Uint32(data[i:]))
+		current := string(data[i+4 : i+8])
+		header := 8
+		switch size {
+		case 0:
+			size = uint64(end - i)
+		case 1:
+			if i+16 > end {
+				return isoBox{}, false, fmt.Errorf("%w: heif box %q truncated", ErrCorrupted, current)
+			}
+			size = binary.BigEndian.Uint64(data[i+8:])
+			header = 16
+		}
+		if size < uint64(header) || size > uint64(end-i) {
+			return isoBox{}, false, fmt.Errorf("%w: heif box %q out of range", ErrCorrupted, current)
+		}
+
+		if current == boxType {
+			return isoBox{boxType: current, start: i + header, end: i + int(size)}, true, nil
+		}
+		i += int(size)
+	}
+
+	return isoBox{}, false, nil
+}
+
+// heifMetadataItems элементы с метаданными из iinf: Exif и XMP (mime application/rdf+xml)
+func heifMetadataItems(data []byte, iinf isoBox) (map[uint32]string, error) {
+	if iinf.end-iinf.start < 6 {
+		return nil, fmt.Errorf("%w: heif iinf box truncated", ErrCorrupted)
+	}
+	childrenStart := iinf.start + 6
+	if data[iinf.start] != 0 {
+		childrenStart = iinf.start + 8
+	}
+
+	items := make(map[uint32]string)
+	for i := childrenStart; i < iinf.end; {
+		infe, ok, err := findBox(data, i, iinf.end, "infe")
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		i = infe.end
+
+		body := data[infe.start:infe.end]
+		if len(body) < 12 {
+			continue
+		}
+		version := body[0]
+		if version < 2 {
+			continue
+		}
+		pos := 4
+		var itemID uint32
+		if version == 2 {
+			itemID = uint32(binary.BigEndian.Uint16(body[pos:]))
+			pos += 2
+		} else {
+			itemID = binary.BigEndian.Uint32(body[pos:])
+			pos += 4
+		}
+		pos += 2 // item_protection_index
+		if pos+4 > len(body) {
+			continue
+		}
+		itemType := string(body[pos : pos+4])
+		pos += 4
+
+		switch itemType {
+		case "Exif":
+			items[itemID] = "EXIF"
+		case "mime":
+			// после типа идут имя элемента и content type, обе строки оканчиваются нулем
+			fields := bytes.SplitN(body[pos:], []byte{0}, 3)
+			if len(fields) > 1 && string(fields[1]) == "application/rdf+xml" {
+				items[itemID] = "XMP"
+			}
+		}
+	}
+
+	return items, nil
+}
+
+// heifExtent кусок данных элемента: method 0 - смещение в файле, 1 - в боксе idat
+type heifExtent struct {
+	method uint8
+	offset uint64
+	length uint64
+}
+
+// heifItemExtents расположение данных элементов из iloc
+func heifItemExtents(data []byte, iloc isoBox) (map[uint32][]heifExtent, error) {
+	r := &boxReader{data: data[iloc.start:iloc.end]}
+
+	version := r.uint(1)
+	r.skip(3)
+	sizes := r.uint(1)
+	offsetSize, lengthSize := int(sizes>>4), int(sizes&0x0F)
+	sizes = r.uint(1)
+	baseOffsetSize, indexSize := int(sizes>>4), int(sizes&0x0F)
+	if version == 0 {
+		indexSize = 0
+	}
+
+	itemCount := r.uint(2)
+	if version == 2 {
+		itemCount = r.uint(4)
+	}
+
+	extents := make(map[uint32][]heifExtent)
+	for range itemCount {
+		var itemID uint32
+		if version == 2 {
+			itemID = uint32(r.uint(4))
+		} else {
+			itemID = uint32(r.uint(2))
+		}
+		var method uint8
+		if version == 1 || version == 2 {
+			method = uint8(r.uint(2) & 0x0F)
+		}
+		r.skip(2) // data_reference_index
+		baseOffset := r.uint(baseOffsetSize)
+
+		extentCount := r.uint(2)
+		for range extentCount {
+			r.skip(indexSize)
+			offset := r.uint(offsetSize)
+			length := r.uint(lengthSize)
+			if offset > math.MaxUint64-baseOffset {
+				return nil, fmt.Errorf("%w: heif item %d offset overflow", ErrCorrupted, itemID)
+			}
+			extents[itemID] = append(extents[itemID], heifExtent{
+				method: method,
+				offset: baseOffset + offset,
+				length: length,
+			})
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+	}
+
+	return extents, r.err
+}
+
+// boxReader последовательное чтение целых чисел big endian из бокса
+type boxReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *boxReader) uint(size int) uint64 {
+	if r.err != nil {
+		return 0
+	}
+	if size != 0 && size != 1 && size != 2 && size != 4 && size != 8 {
+		r.err = fmt.Errorf("%w: heif field size %d", ErrCorrupted, size)
+		return 0
+	}
+	if r.pos+size > len(r.data) {
+		r.err = fmt.Errorf("%w: heif iloc box truncated", ErrCorrupted)
+		return 0
+	}
+
+	var value uint64
+	for _, b := range r.data[r.pos : r.pos+size] {
+		value = value<<8 | uint64(b)
+	}
+	r.pos += size
+
+	return value
+}
+
+func (r *boxReader) skip(size int) {
+	if r.err == nil && r.pos+size > len(r.data) {
+		r.err = fmt.Errorf("%w: heif iloc box truncated", ErrCorrupted)
+		return
+	}
+	r.pos += size
+}
@@ -0,0 +1,121 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	jpegSOS   = 0xDA
+	jpegEOI   = 0xD9
+	jpegAPP0  = 0xE0
+	jpegAPP1  = 0xE1
+	jpegAPP2  = 0xE2
+	jpegAPP14 = 0xEE // Adobe, нужен для правильных цветов
+	jpegCOM   = 0xFE
+)
+
+// stripJPEG удаляет сегменты APP1 (EXIF, XMP), APP13 (IPTC), комментарии, прочие APPn
+// и данные после конца изображения. Остаются JFIF, ICC-профиль и Adobe.
+// Вместе с EXIF пропадает ориентация снимка, часть фото может показываться повернутой
+func stripJPEG(data []byte) ([]byte, []string, error) {
+	var out bytes.Buffer
+	out.Grow(len(data))
+	out.Write(data[:2])
+
+	removed := make([]string, 0)
+	n := len(data)
+	i := 2
+	for i < n {
+		if data[i] != 0xFF {
+			return nil, nil, fmt.Errorf("%w: jpeg marker expected at %d", ErrCorrupted, i)
+		}
+		start := i
+		for i < n && data[i] == 0xFF {
+			i++
+		}
+		if i >= n {
+			break
+		}
+		marker := data[i]
+		i++
+
+		switch {
+		case marker == jpegEOI:
+			out.Write(data[start:i])
+			if i < n {
+				removed = addOnce(removed, "вложенные миниатюры")
+			}
+			return out.Bytes(), removed, nil
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			out.Write(data[start:i])
+			continue
+		}
+
+		if i+2 > n {
+			break
+		}
+		end := i + int(binary.BigEndian.Uint16(data[i:]))
+		if end < i+2 || end > n {
+			return nil, nil, fmt.Errorf("%w: jpeg segment out of range", ErrCorrupted)
+		}
+
+		if name, drop := jpegMetadata(marker, data[i+2:end]); drop {
+			removed = addOnce(removed, name)
+		} else {
+			out.Write(data[start:end])
+		}
+		i = end
+
+		if marker == jpegSOS {
+			scanEnd := jpegScanEnd(data, i)
+			out.Write(data[i:scanEnd])
+			i = scanEnd
+		}
+	}
+
+	return nil, nil, fmt.Errorf("%w: jpeg end of image not found", ErrCorrupted)
+}
+
+// jpegMetadata сегмент с метаданными и его название
+func jpegMetadata(marker byte, payload []byte) (string, bool) {
+	switch {
+	case marker == jpegAPP1 && bytes.HasPrefix(payload, []byte("Exif\x00")):
+		return "EXIF", true
+	case marker == jpegAPP1:
+		return "XMP", true
+	case marker == jpegAPP2 && bytes.HasPrefix(payload, []byte("ICC_PROFILE\x00")):
+		return "", false
+	case marker == jpegAPP2 && bytes.HasPrefix(payload, []byte("MPF\x00")):
+		// MPF ссылается на миниатюры после конца изображения, которые тоже удаляются
+		return "вложенные миниатюры", true
+	case marker == 0xED:
+		return "IPTC", true
+	case marker == jpegCOM:
+		return "комментарий", true
+	case marker == jpegAPP0 || marker == jpegAPP14:
+		return "", false
+	case marker > jpegAPP0 && marker <= 0xEF:
+		return "служебные данные камеры", true
+	}
+
+	return "", false
+}
+
+// jpegScanEnd конец сжатых данных после SOS: первый маркер, кроме FF00 и RSTn
+func jpegScanEnd(data []byte, i int) int {
+	for ; i+1 < len(data); i++ {
+		if data[i] != 0xFF {
+			continue
+		}
+		next := data[i+1]
+		if next == 0x00 || (next >= 0xD0 && next <= 0xD7) {
+			i++
+			continue
+		}
+		return i
+	}
+
+	return len(data)
+}
@@ -0,0 +1,115 @@
+package metadata
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"slices"
+)
+
+// Format формат файла, из которого умеем удалять метаданные
+type Format string
+
+const (
+	FormatUnknown Format = ""
+	FormatJPEG    Format = "jpeg"
+	FormatPNG     Format = "png"
+	FormatHEIC    Format = "heic"
+	FormatPDF     Format = "pdf"
+	FormatOOXML   Format = "ooxml"
+)
+
+var (
+	// ErrUnsupportedFormat формат файла не поддерживается, удалить метаданные нельзя
+	ErrUnsupportedFormat = errors.New("unsupported file format")
+	// ErrCorrupted файл поврежден или не соответствует своему формату
+	ErrCorrupted = errors.New("corrupted file")
+)
+
+// Result очищенный файл
+type Result struct {
+	Data   []byte
+	Format Format
+	// Removed что было удалено, для подписи в чате админов
+	Removed []string
+	// Kept метаданные, которые удалить не удалось
+	Kept []string
+}
+
+// heifBrands основные бренды контейнера HEIF/HEIC
+var heifBrands = []string{"heic", "heix", "heim", "heis", "hevc", "hevx", "mif1", "msf1", "avif"}
+
+// Detect формат файла по сигнатуре
+func Detect(data []byte) Format {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return FormatJPEG
+	case bytes.HasPrefix(data, pngSignature):
+		return FormatPNG
+	case len(data) >= 12 && string(data[4:8]) == "ftyp" && slices.Contains(heifBrands, string(data[8:12])):
+		return FormatHEIC
+	case bytes.Contains(data[:min(len(data), 1024)], []byte("%PDF-")):
+		return FormatPDF
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")) && isOOXML(data):
+		return FormatOOXML
+	}
+
+	return FormatUnknown
+}
+
+// Strip удаляет из файла метаданные: EXIF и XMP из JPEG, PNG и HEIC,
+// свойства документа и авторов правок из PDF и документов Office (docx, xlsx, pptx)
+func Strip(data []byte) (Result, error) {
+	format := Detect(data)
+
+	var (
+		clean   []byte
+		removed []string
+		kept    []string
+		err     error
+	)
+	switch format {
+	case FormatJPEG:
+		clean, removed, err = stripJPEG(data)
+	case FormatPNG:
+		clean, removed, err = stripPNG(data)
+	case FormatHEIC:
+		clean, removed, err = stripHEIC(data)
+	case FormatPDF:
+		clean, removed, kept = stripPDF(data)
+	case FormatOOXML:
+		clean, removed, err = stripOOXML(data)
+	default:
+		return Result{}, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{Data: clean, Format: format, Removed: removed, Kept: kept}, nil
+}
+
+// isOOXML zip-архив документа Office
+func isOOXML(data []byte) bool {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return false
+	}
+
+	for _, file := range reader.File {
+		if file.Name == "[Content_Types].xml" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addOnce добавляет значение в список, если его там еще нет
+func addOnce(list []string, value string) []string {
+	if slices.Contains(list, value) {
+		return list
+	}
+
+	return append(list, value)
+}
@@ -0,0 +1,388 @@
+package metadata
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"slices"
+	"testing"
+)
+
+// jpegSegment сегмент JPEG с маркером и длиной
+func jpegSegment(marker byte, payload string) []byte {
+	segment := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// testJPEG JPEG с EXIF, комментарием и миниатюрой после конца изображения
+func testJPEG() []byte {
+	return slices.Concat(
+		[]byte{0xFF, 0xD8},
+		jpegSegment(jpegAPP0, "JFIF\x00\x01\x01"),
+		jpegSegment(jpegAPP1, "Exif\x00\x00GPS-SECRET"),
+		jpegSegment(jpegCOM, "COMMENT-SECRET"),
+		jpegSegment(0xDB, "quant"),
+		jpegSegment(jpegSOS, "scan"),
+		[]byte{0x12, 0xFF, 0x00, 0x34, 0xFF, 0xD0, 0x56},
+		[]byte{0xFF, jpegEOI},
+		[]byte("THUMBNAIL-SECRET"),
+	)
+}
+
+// pngChunk чанк PNG с контрольной суммой
+func pngChunk(chunkType, payload string) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+	chunk = append(chunk, chunkType...)
+	chunk = append(chunk, payload...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE([]byte(chunkType+payload)))
+}
+
+// testPNG PNG с текстовым полем, EXIF и временем создания
+func testPNG() []byte {
+	return slices.Concat(
+		pngSignature,
+		pngChunk("IHDR", "\x00\x00\x00\x01\x00\x00\x00\x01\x08\x02\x00\x00\x00"),
+		pngChunk("tEXt", "Author\x00TEXT-SECRET"),
+		pngChunk("eXIf", "EXIF-SECRET"),
+		pngChunk("tIME", "\x07\xe8\x01\x01\x00\x00\x00"),
+		pngChunk("IDAT", "pixels"),
+		pngChunk("IEND", ""),
+		[]byte("TRAILER-SECRET"),
+	)
+}
+
+// isoBoxBytes бокс ISO BMFF с 32-битным размером
+func isoBoxBytes(boxType string, payload ...[]byte) []byte {
+	body := slices.Concat(payload...)
+	box := binary.BigEndian.AppendUint32(nil, uint32(len(body)+8))
+	box = append(box, boxType...)
+	return append(box, body...)
+}
+
+// testHEIC HEIC с элементом Exif, данные которого лежат в mdat по смещению offset.
+// Если offset < 0, смещение вычисляется так, чтобы указывать на данные в mdat
+func testHEIC(offset int64, length uint32) []byte {
+	exif := []byte("\x00\x00\x00\x06Exif\x00\x00HEIC-GPS-SECRET")
+	if length == 0 {
+		length = uint32(len(exif))
+	}
+
+	build := func(offset uint32) []byte {
+		// infe версии 2: item_ID, item_protection_index, item_type
+		infe := isoBoxBytes("infe", []byte{2, 0, 0, 0, 0, 1, 0, 0}, []byte("Exif"), []byte{0})
+		iinf := isoBoxBytes("iinf", []byte{0, 0, 0, 0, 0, 1}, infe)
+		// iloc версии 0: offset_size 4, length_size 4, base_offset_size 0, один элемент с одним куском
+		iloc := isoBoxBytes("iloc",
+			[]byte{0, 0, 0, 0, 0x44, 0x00, 0, 1},
+			[]byte{0, 1, 0, 0, 0, 1},
+			binary.BigEndian.AppendUint32(nil, offset),
+			binary.BigEndian.AppendUint32(nil, length),
+		)
+		meta := isoBoxBytes("meta", []byte{0, 0, 0, 0}, iinf, iloc)
+		ftyp := isoBoxBytes("ftyp", []byte("heic"), []byte{0, 0, 0, 0}, []byte("mif1heic"))
+
+		return slices.Concat(ftyp, meta, isoBoxBytes("mdat", exif, []byte("IMAGE-DATA")))
+	}
+
+	if offset >= 0 {
+		return build(uint32(offset))
+	}
+	// mdat идет последним, его данные начинаются через 8 байт заголовка
+	probe := build(0)
+	return build(uint32(len(probe) - len(exif) - len("IMAGE-DATA")))
+}
+
+const testPDFTemplate = "%PDF-1.4\n" +
+	"1 0 obj\n<< /Author (PDF-AUTHOR-SECRET) /Producer <504446> /Nested (a (b) c) >>\nendobj\n" +
+	"2 0 obj\n<< /Type /Metadata /Subtype /XML /Length 26 >>\nstream\n<x:xmpmeta>XMP-SECRET</x>\nendstream\nendobj\n" +
+	"trailer\n<< /Info 1 0 R >>\n%%EOF\n"
+
+// testOOXML документ Word с автором, организацией и автором правки
+func testOOXML(t testing.TB) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	files := []struct{ name, content string }{
+		{"[Content_Types].xml", `<Types/>`},
+		{"docProps/core.xml", `<cp:coreProperties><dc:creator>CORE-AUTHOR-SECRET</dc:creator></cp:coreProperties>`},
+		{"docProps/app.xml", `<Properties><Company>COMPANY-SECRET</Company><Pages>1</Pages></Properties>`},
+		{"word/document.xml", `<w:document><w:ins w:author="REVISION-SECRET" w:date="2024"/>text</w:document>`},
+	}
+	for _, file := range files {
+		fw, err := w.Create(file.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = fw.Write([]byte(file.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// ooxmlContent распакованное содержимое всех файлов архива
+func ooxmlContent(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("cleaned document is not a zip: %v", err)
+	}
+	var all []byte
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, content...)
+	}
+
+	return all
+}
+
+func TestStrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		format  Format
+		removed []string
+		kept    []string
+		// secrets не должны остаться в очищенном файле
+		secrets []string
+		// keep должно остаться в очищенном файле
+		keep []string
+		// sameSize файл очищается на месте без изменения длины
+		sameSize bool
+	}{
+		{
+			name:    "jpeg",
+			data:    testJPEG(),
+			format:  FormatJPEG,
+			removed: []string{"EXIF", "комментарий", "вложенные миниатюры"},
+			secrets: []string{"GPS-SECRET", "COMMENT-SECRET", "THUMBNAIL-SECRET"},
+			keep:    []string{"JFIF", "quant", "\x12\xFF\x00\x34\xFF\xD0\x56"},
+		},
+		{
+			name:    "png",
+			data:    testPNG(),
+			format:  FormatPNG,
+			removed: []string{"текстовые поля", "EXIF", "время создания"},
+			secrets: []string{"TEXT-SECRET", "EXIF-SECRET", "TRAILER-SECRET"},
+			keep:    []string{"IHDR", "pixels", "IEND"},
+		},
+		{
+			name:     "heic",
+			data:     testHEIC(-1, 0),
+			format:   FormatHEIC,
+			removed:  []string{"EXIF"},
+			secrets:  []string{"HEIC-GPS-SECRET"},
+			keep:     []string{"IMAGE-DATA"},
+			sameSize: true,
+		},
+		{
+			name:     "pdf",
+			data:     []byte(testPDFTemplate),
+			format:   FormatPDF,
+			removed:  []string{"свойства документа (автор, программа, даты)", "XMP"},
+			secrets:  []string{"PDF-AUTHOR-SECRET", "<504446>", "XMP-SECRET"},
+			keep:     []string{"/Author (", "/Info 1 0 R", "endstream"},
+			sameSize: true,
+		},
+		{
+			name: "pdf compressed metadata",
+			data: []byte("%PDF-1.5\n3 0 obj\n<< /Type /Metadata /Subtype /XML /Filter /FlateDecode >>\nstream\nxx\nendstream\nendobj\n" +
+				"trailer\n<< /Info 9 0 R >>\n"),
+			format:   FormatPDF,
+			kept:     []string{"сжатые метаданные XMP", "свойства документа в сжатых объектах"},
+			sameSize: true,
+		},
+		{
+			name:    "ooxml",
+			data:    testOOXML(t),
+			format:  FormatOOXML,
+			removed: []string{"автор и свойства документа", "организация", "авторы исправлений и комментариев"},
+			secrets: []string{"CORE-AUTHOR-SECRET", "COMPANY-SECRET", "REVISION-SECRET"},
+			keep:    []string{"<Pages>1</Pages>", "text"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := bytes.Clone(tt.data)
+
+			result, err := Strip(tt.data)
+			if err != nil {
+				t.Fatalf("Strip() error = %v", err)
+			}
+			if !bytes.Equal(tt.data, original) {
+				t.Error("Strip() modified its input")
+			}
+			if result.Format != tt.format {
+				t.Errorf("Format = %q, want %q", result.Format, tt.format)
+			}
+			if !sameSet(result.Removed, tt.removed) {
+				t.Errorf("Removed = %q, want %q", result.Removed, tt.removed)
+			}
+			if !sameSet(result.Kept, tt.kept) {
+				t.Errorf("Kept = %q, want %q", result.Kept, tt.kept)
+			}
+			if tt.sameSize && len(result.Data) != len(tt.data) {
+				t.Errorf("len(Data) = %d, want %d", len(result.Data), len(tt.data))
+			}
+
+			content := result.Data
+			if tt.format == FormatOOXML {
+				content = ooxmlContent(t, result.Data)
+			}
+			for _, secret := range tt.secrets {
+				if bytes.Contains(content, []byte(secret)) {
+					t.Errorf("cleaned file still contains %q", secret)
+				}
+			}
+			for _, keep := range tt.keep {
+				if !bytes.Contains(content, []byte(keep)) {
+					t.Errorf("cleaned file lost %q", keep)
+				}
+			}
+		})
+	}
+}
+
+func TestStripErrors(t *testing.T) {
+	jpeg := testJPEG()
+	png := testPNG()
+	heic := testHEIC(-1, 0)
+
+	// infe с пустым телом
+	emptyInfe := slices.Concat(
+		isoBoxBytes("ftyp", []byte("heic"), []byte{0, 0, 0, 0}),
+		isoBoxBytes("meta", []byte{0, 0, 0, 0},
+			isoBoxBytes("iinf", []byte{0, 0, 0, 0, 0, 1}, isoBoxBytes("infe")),
+			isoBoxBytes("iloc", []byte{0, 0, 0, 0, 0x44, 0x00, 0, 0}),
+		),
+	)
+	// iloc версии 1 с 8-байтными base_offset и offset, сумма которых переполняет uint64
+	overflowIloc := slices.Concat(
+		isoBoxBytes("ftyp", []byte("heic"), []byte{0, 0, 0, 0}),
+		isoBoxBytes("meta", []byte{0, 0, 0, 0},
+			isoBoxBytes("iinf", []byte{0, 0, 0, 0, 0, 1},
+				isoBoxBytes("infe", []byte{2, 0, 0, 0, 0, 1, 0, 0}, []byte("Exif"), []byte{0})),
+			isoBoxBytes("iloc",
+				[]byte{1, 0, 0, 0, 0x88, 0x80, 0, 1},
+				[]byte{0, 1, 0, 0, 0, 0, 0, 1},
+				bytes.Repeat([]byte{0xFF}, 8),
+				bytes.Repeat([]byte{0xFF}, 8),
+				binary.BigEndian.AppendUint64(nil, 4),
+			),
+		),
+	)
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{name: "empty", data: nil, want: ErrUnsupportedFormat},
+		{name: "text", data: []byte("just a text file"), want: ErrUnsupportedFormat},
+		{name: "zip without content types", data: []byte("PK\x03\x04garbage"), want: ErrUnsupportedFormat},
+		{name: "jpeg without end of image", data: jpeg[:len(jpeg)-len("THUMBNAIL-SECRET")-2], want: ErrCorrupted},
+		{name: "jpeg segment out of range", data: []byte{0xFF, 0xD8, 0xFF, jpegAPP1, 0xFF, 0xFF, 'E'}, want: ErrCorrupted},
+		{name: "jpeg garbage after header", data: []byte{0xFF, 0xD8, 0xFF, 0x00, 0x01}, want: ErrCorrupted},
+		{name: "png without IEND", data: png[:len(png)-len("TRAILER-SECRET")-12], want: ErrCorrupted},
+		{name: "png chunk out of range", data: slices.Concat(pngSignature, []byte{0x7F, 0xFF, 0xFF, 0xFF}, []byte("IDAT")), want: ErrCorrupted},
+		{name: "heic truncated", data: heic[:40], want: ErrCorrupted},
+		{name: "heic empty infe", data: emptyInfe, want: nil},
+		{name: "heic extent out of range", data: testHEIC(-1, 1<<30), want: ErrCorrupted},
+		{name: "heic extent past the end", data: testHEIC(1<<31, 0), want: ErrCorrupted},
+		{name: "heic offset overflow", data: overflowIloc, want: ErrCorrupted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Strip(tt.data)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Strip() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Strip() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want Format
+	}{
+		{name: "jpeg", data: testJPEG(), want: FormatJPEG},
+		{name: "png", data: testPNG(), want: FormatPNG},
+		{name: "heic", data: testHEIC(-1, 0), want: FormatHEIC},
+		{name: "pdf", data: []byte(testPDFTemplate), want: FormatPDF},
+		{name: "pdf with preamble", data: []byte("garbage\n%PDF-1.7\n"), want: FormatPDF},
+		{name: "ooxml", data: testOOXML(t), want: FormatOOXML},
+		{name: "mp4", data: isoBoxBytes("ftyp", []byte("isom")), want: FormatUnknown},
+		{name: "empty", data: nil, want: FormatUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.data); got != tt.want {
+				t.Errorf("Detect() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// FuzzStrip поврежденные файлы не должны ронять бота, а очистка на месте - менять длину файла
+func FuzzStrip(f *testing.F) {
+	f.Add(testJPEG())
+	f.Add(testPNG())
+	f.Add(testHEIC(-1, 0))
+	f.Add([]byte(testPDFTemplate))
+	f.Add(testOOXML(f))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		result, err := Strip(data)
+		if err != nil {
+			return
+		}
+		if result.Data == nil {
+			t.Fatal("Strip() returned nil data without error")
+		}
+		if (result.Format == FormatHEIC || result.Format == FormatPDF) && len(result.Data) != len(data) {
+			t.Fatalf("%s: len(Data) = %d, want %d", result.Format, len(result.Data), len(data))
+		}
+	})
+}
+
+func sameSet(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for _, value := range want {
+		if !slices.Contains(got, value) {
+			return false
+		}
+	}
+
+	return true
+}
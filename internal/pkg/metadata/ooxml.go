@@ -0,0 +1,107 @@
+package metadata
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// ooxmlMaxEntrySize предел размера распакованного файла архива, защита от zip-бомб
+	ooxmlMaxEntrySize = 64 << 20
+
+	emptyCoreProperties = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\r\n" +
+		`<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties"` +
+		` xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/"` +
+		` xmlns:dcmitype="http://purl.org/dc/dcmitype/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"></cp:coreProperties>`
+	emptyCustomProperties = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\r\n" +
+		`<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/custom-properties"` +
+		` xmlns:vt="http://schemas.openxmlformats.org/officeDocument/2006/docPropsVTypes"></Properties>`
+)
+
+var (
+	// ooxmlAppFields поля docProps/app.xml, по которым можно узнать организацию
+	ooxmlAppFields = regexp.MustCompile(`(?s)<(Company|Manager|HyperlinkBase)>.*?</(Company|Manager|HyperlinkBase)>`)
+	// ooxmlAuthorAttrs авторы правок и комментариев в word/*.xml
+	ooxmlAuthorAttrs = regexp.MustCompile(`\b(w:author|w:initials|w15:author|w15:userId|w15:providerId)="[^"]*"`)
+	// ooxmlZipTime время изменения всех файлов архива, чтобы не выдавать часовой пояс автора
+	ooxmlZipTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// stripOOXML очищает документ Office: свойства документа, организацию,
+// пользовательские свойства и авторов исправлений и комментариев
+func stripOOXML(data []byte) ([]byte, []string, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrCorrupted, err)
+	}
+
+	var out bytes.Buffer
+	writer := zip.NewWriter(&out)
+	removed := make([]string, 0)
+	for _, file := range reader.File {
+		content, err := readZipFile(file)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch {
+		case file.Name == "docProps/core.xml":
+			content = []byte(emptyCoreProperties)
+			removed = addOnce(removed, "автор и свойства документа")
+		case file.Name == "docProps/custom.xml":
+			content = []byte(emptyCustomProperties)
+			removed = addOnce(removed, "пользовательские свойства")
+		case file.Name == "docProps/app.xml":
+			if cleaned := ooxmlAppFields.ReplaceAll(content, []byte("<$1></$1>")); !bytes.Equal(cleaned, content) {
+				content = cleaned
+				removed = addOnce(removed, "организация")
+			}
+		case strings.HasPrefix(file.Name, "word/") && strings.HasSuffix(file.Name, ".xml"):
+			if cleaned := ooxmlAuthorAttrs.ReplaceAll(content, []byte(`$1=""`)); !bytes.Equal(cleaned, content) {
+				content = cleaned
+				removed = addOnce(removed, "авторы исправлений и комментариев")
+			}
+		}
+
+		header := &zip.FileHeader{
+			Name:     file.Name,
+			Method:   file.Method,
+			Modified: ooxmlZipTime,
+		}
+		w, err := writer.CreateHeader(header)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err = w.Write(content); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err = writer.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	return out.Bytes(), removed, nil
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorrupted, err)
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(io.LimitReader(rc, ooxmlMaxEntrySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorrupted, err)
+	}
+	if len(content) > ooxmlMaxEntrySize {
+		return nil, fmt.Errorf("%w: %s is too large", ErrCorrupted, file.Name)
+	}
+
+	return content, nil
+}
@@ -0,0 +1,150 @@
+package metadata
+
+import (
+	"bytes"
+	"regexp"
+)
+
+var (
+	pdfInfoRef    = regexp.MustCompile(`/Info\s+(\d+)\s+(\d+)\s+R`)
+	pdfObject     = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+	pdfMetadata   = regexp.MustCompile(`/Type\s*/Metadata\b`)
+	pdfXMLSubtype = regexp.MustCompile(`/Subtype\s*/XML\b`)
+	pdfFilter     = regexp.MustCompile(`/Filter\b`)
+	pdfStream     = regexp.MustCompile(`\bstream\r?\n`)
+	pdfEndStream  = regexp.MustCompile(`\r?\n?endstream\b`)
+)
+
+// pdfObjectRange объект PDF: номер, поколение и границы от «obj» до «endobj»
+type pdfObjectRange struct {
+	ref   string
+	start int
+	end   int
+}
+
+// stripPDF затирает пробелами строки словаря Info (автор, программа, даты создания)
+// и несжатые потоки XMP. Длина файла не меняется, поэтому таблица xref остается верной.
+// Словари и потоки внутри сжатых объектов изменить на месте нельзя, они попадают в kept
+func stripPDF(data []byte) ([]byte, []string, []string) {
+	clean := bytes.Clone(data)
+	removed := make([]string, 0)
+	kept := make([]string, 0)
+
+	infoRefs := make(map[string]bool)
+	for _, match := range pdfInfoRef.FindAllSubmatch(data, -1) {
+		infoRefs[string(match[1])+" "+string(match[2])] = true
+	}
+
+	foundInfo := make(map[string]bool)
+	for _, object := range pdfObjects(data) {
+		body := clean[object.start:object.end]
+
+		if infoRefs[object.ref] {
+			foundInfo[object.ref] = true
+			if blankPDFStrings(body) {
+				removed = addOnce(removed, "свойства документа (автор, программа, даты)")
+			}
+			continue
+		}
+
+		if !pdfMetadata.Match(body) || !pdfXMLSubtype.Match(body) {
+			continue
+		}
+		streamStart := pdfStream.FindIndex(body)
+		if streamStart == nil {
+			continue
+		}
+		if pdfFilter.Match(body[:streamStart[0]]) {
+			kept = addOnce(kept, "сжатые метаданные XMP")
+			continue
+		}
+		streamEnd := pdfEndStream.FindIndex(body[streamStart[1]:])
+		if streamEnd == nil {
+			continue
+		}
+		fill(body[streamStart[1]:streamStart[1]+streamEnd[0]], ' ')
+		removed = addOnce(removed, "XMP")
+	}
+
+	if len(foundInfo) < len(infoRefs) {
+		kept = addOnce(kept, "свойства документа в сжатых объектах")
+	}
+
+	return clean, removed, kept
+}
+
+// pdfObjects косвенные объекты файла, включая объекты из инкрементальных обновлений
+func pdfObjects(data []byte) []pdfObjectRange {
+	matches := pdfObject.FindAllSubmatchIndex(data, -1)
+	objects := make([]pdfObjectRange, 0, len(matches))
+	for _, match := range matches {
+		end := bytes.Index(data[match[1]:], []byte("endobj"))
+		if end < 0 {
+			continue
+		}
+		objects = append(objects, pdfObjectRange{
+			ref:   string(data[match[2]:match[3]]) + " " + string(data[match[4]:match[5]]),
+			start: match[1],
+			end:   match[1] + end,
+		})
+	}
+
+	return objects
+}
+
+// blankPDFStrings заменяет пробелами содержимое всех строк объекта: (литеральных) и <шестнадцатеричных>
+func blankPDFStrings(body []byte) bool {
+	changed := false
+	for i := 0; i < len(body); i++ {
+		switch {
+		case body[i] == '(':
+			end := literalStringEnd(body, i)
+			if end < 0 {
+				return changed
+			}
+			fill(body[i+1:end], ' ')
+			changed = changed || end > i+1
+			i = end
+		case body[i] == '<' && i+1 < len(body) && body[i+1] == '<':
+			i++
+		case body[i] == '<':
+			end := bytes.IndexByte(body[i:], '>')
+			if end < 0 {
+				return changed
+			}
+			fill(body[i+1:i+end], ' ')
+			changed = changed || end > 1
+			i += end
+		case body[i] == '>' && i+1 < len(body) && body[i+1] == '>':
+			i++
+		}
+	}
+
+	return changed
+}
+
+// literalStringEnd позиция закрывающей скобки литеральной строки с учетом вложенных скобок и экранирования
+func literalStringEnd(body []byte, start int) int {
+	depth := 0
+	for i := start; i < len(body); i++ {
+		switch body[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+func fill(b []byte, value byte) {
+	for i := range b {
+		b[i] = value
+	}
+}
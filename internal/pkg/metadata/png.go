@@ -0,0 +1,50 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// pngMetadataChunks чанки PNG с метаданными, XMP хранится в iTXt
+var pngMetadataChunks = map[string]string{
+	"eXIf": "EXIF",
+	"tEXt": "текстовые поля",
+	"zTXt": "текстовые поля",
+	"iTXt": "текстовые поля и XMP",
+	"tIME": "время создания",
+}
+
+// stripPNG удаляет чанки с метаданными и данные после IEND.
+// Чанки копируются целиком, поэтому контрольные суммы не пересчитываются
+func stripPNG(data []byte) ([]byte, []string, error) {
+	var out bytes.Buffer
+	out.Grow(len(data))
+	out.Write(pngSignature)
+
+	removed := make([]string, 0)
+	i := len(pngSignature)
+	for i+8 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		chunkType := string(data[i+4 : i+8])
+		end := i + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, nil, fmt.Errorf("%w: png chunk %q out of range", ErrCorrupted, chunkType)
+		}
+
+		if name, ok := pngMetadataChunks[chunkType]; ok {
+			removed = addOnce(removed, name)
+		} else {
+			out.Write(data[i:end])
+		}
+		i = end
+
+		if chunkType == "IEND" {
+			return out.Bytes(), removed, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("%w: png IEND not found", ErrCorrupted)
+}
@@ -0,0 +1,116 @@
+package ratelimit
+
+import (
+	"medrussia_news_bot/internal/config"
+	"sync"
+	"time"
+)
+
+// sweepInterval как часто удаляются записи неактивных пользователей
+const sweepInterval = 10 * time.Minute
+
+// Decision результат проверки сообщения пользователя
+type Decision struct {
+	// Allowed сообщение можно обрабатывать
+	Allowed bool
+	// JustMuted пользователь превысил лимит этим сообщением и заглушен до MutedUntil
+	JustMuted  bool
+	MutedUntil time.Time
+	// Trips сколько раз пользователь превышал лимит за окно trip_window
+	Trips int
+}
+
+// bucket корзина токенов пользователя
+type bucket struct {
+	tokens     float64
+	updatedAt  time.Time
+	mutedUntil time.Time
+	trips      []time.Time
+}
+
+// Limiter ограничение частоты сообщений пользователей по алгоритму token bucket.
+// Превысивший лимит пользователь заглушается на mute_duration
+type Limiter struct {
+	cfg       config.RateLimitConfig
+	now       func() time.Time
+	mu        sync.Mutex
+	buckets   map[int64]*bucket
+	sweptAt   time.Time
+	isEnabled bool
+}
+
+// NewLimiter конструктор, при rate = 0 ограничение выключено
+func NewLimiter(cfg config.RateLimitConfig) *Limiter {
+	return &Limiter{
+		cfg:       cfg,
+		now:       time.Now,
+		buckets:   make(map[int64]*bucket),
+		isEnabled: cfg.Rate > 0 && cfg.Burst > 0,
+	}
+}
+
+// Allow списывает токен за сообщение пользователя userID
+func (l *Limiter) Allow(userID int64) Decision {
+	if !l.isEnabled {
+		return Decision{Allowed: true}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[userID]
+	if !ok {
+		b = &bucket{tokens: float64(l.cfg.Burst), updatedAt: now}
+		l.buckets[userID] = b
+	}
+
+	if now.Before(b.mutedUntil) {
+		return Decision{MutedUntil: b.mutedUntil, Trips: len(b.trips)}
+	}
+
+	b.tokens = min(float64(l.cfg.Burst), b.tokens+now.Sub(b.updatedAt).Seconds()*l.cfg.Rate)
+	b.updatedAt = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return Decision{Allowed: true}
+	}
+
+	b.mutedUntil = now.Add(l.cfg.MuteDuration)
+	// после заглушения пользователь начинает с полной корзиной
+	b.tokens = float64(l.cfg.Burst)
+	b.updatedAt = b.mutedUntil
+	b.trips = append(l.recentTrips(b, now), now)
+
+	return Decision{JustMuted: true, MutedUntil: b.mutedUntil, Trips: len(b.trips)}
+}
+
+// recentTrips превышения лимита внутри окна trip_window
+func (l *Limiter) recentTrips(b *bucket, now time.Time) []time.Time {
+	trips := b.trips[:0]
+	for _, trip := range b.trips {
+		if now.Sub(trip) < l.cfg.TripWindow {
+			trips = append(trips, trip)
+		}
+	}
+
+	return trips
+}
+
+// sweep удаляет пользователей с полной корзиной, без заглушения и без недавних превышений
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.sweptAt) < sweepInterval {
+		return
+	}
+	l.sweptAt = now
+
+	for userID, b := range l.buckets {
+		b.trips = l.recentTrips(b, now)
+		full := b.tokens+now.Sub(b.updatedAt).Seconds()*l.cfg.Rate >= float64(l.cfg.Burst)
+		if full && !now.Before(b.mutedUntil) && len(b.trips) == 0 {
+			delete(l.buckets, userID)
+		}
+	}
+}
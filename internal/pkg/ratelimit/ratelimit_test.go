@@ -0,0 +1,152 @@
+package ratelimit
+
+import (
+	"medrussia_news_bot/internal/config"
+	"testing"
+	"time"
+)
+
+// newTestLimiter лимитер с часами, которые двигает тест
+func newTestLimiter(cfg config.RateLimitConfig) (*Limiter, *time.Time) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewLimiter(cfg)
+	limiter.now = func() time.Time { return now }
+
+	return limiter, &now
+}
+
+var testConfig = config.RateLimitConfig{
+	Rate:         0.5,
+	Burst:        3,
+	MuteDuration: time.Minute,
+	TripWindow:   time.Hour,
+}
+
+func TestAllowBurstAndMute(t *testing.T) {
+	limiter, now := newTestLimiter(testConfig)
+
+	for i := range testConfig.Burst {
+		if d := limiter.Allow(1); !d.Allowed {
+			t.Fatalf("message %d rejected within burst: %+v", i+1, d)
+		}
+	}
+
+	d := limiter.Allow(1)
+	if d.Allowed || !d.JustMuted || d.Trips != 1 || !d.MutedUntil.Equal(now.Add(time.Minute)) {
+		t.Fatalf("message over burst = %+v, want just muted until %s", d, now.Add(time.Minute))
+	}
+
+	// другой пользователь не затронут
+	if d := limiter.Allow(2); !d.Allowed {
+		t.Errorf("other user rejected: %+v", d)
+	}
+
+	*now = now.Add(30 * time.Second)
+	if d = limiter.Allow(1); d.Allowed || d.JustMuted || d.Trips != 1 {
+		t.Errorf("message while muted = %+v, want rejected without a new trip", d)
+	}
+
+	// после заглушения корзина полная
+	*now = now.Add(30 * time.Second)
+	for i := range testConfig.Burst {
+		if d := limiter.Allow(1); !d.Allowed {
+			t.Fatalf("message %d after mute rejected: %+v", i+1, d)
+		}
+	}
+	if d = limiter.Allow(1); !d.JustMuted || d.Trips != 2 {
+		t.Errorf("second trip = %+v, want just muted with 2 trips", d)
+	}
+}
+
+func TestAllowRefill(t *testing.T) {
+	limiter, now := newTestLimiter(testConfig)
+
+	for range testConfig.Burst {
+		limiter.Allow(1)
+	}
+
+	// Rate 0.5 в секунду: за 2 секунды восстанавливается один токен
+	*now = now.Add(2 * time.Second)
+	if d := limiter.Allow(1); !d.Allowed {
+		t.Fatalf("message after refill rejected: %+v", d)
+	}
+	if d := limiter.Allow(1); d.Allowed || !d.JustMuted {
+		t.Errorf("second message after one refilled token = %+v, want muted", d)
+	}
+
+	// корзина не наполняется выше Burst
+	limiter, now = newTestLimiter(testConfig)
+	*now = now.Add(time.Hour)
+	allowed := 0
+	for range testConfig.Burst + 1 {
+		if limiter.Allow(1).Allowed {
+			allowed++
+		}
+	}
+	if allowed != testConfig.Burst {
+		t.Errorf("allowed %d messages after idle hour, want %d", allowed, testConfig.Burst)
+	}
+}
+
+func TestAllowTripWindow(t *testing.T) {
+	limiter, now := newTestLimiter(testConfig)
+
+	trip := func() Decision {
+		t.Helper()
+		var d Decision
+		for range testConfig.Burst + 1 {
+			d = limiter.Allow(1)
+		}
+		if !d.JustMuted {
+			t.Fatalf("expected mute, got %+v", d)
+		}
+		*now = now.Add(testConfig.MuteDuration)
+		return d
+	}
+
+	trip()
+	if d := trip(); d.Trips != 2 {
+		t.Errorf("trips = %d, want 2", d.Trips)
+	}
+
+	// превышения старше окна забываются
+	*now = now.Add(testConfig.TripWindow)
+	if d := trip(); d.Trips != 1 {
+		t.Errorf("trips after window = %d, want 1", d.Trips)
+	}
+}
+
+func TestAllowDisabled(t *testing.T) {
+	limiter, _ := newTestLimiter(config.RateLimitConfig{Burst: 3})
+	for range 100 {
+		if d := limiter.Allow(1); !d.Allowed {
+			t.Fatalf("disabled limiter rejected a message: %+v", d)
+		}
+	}
+}
+
+func TestSweep(t *testing.T) {
+	limiter, now := newTestLimiter(testConfig)
+
+	limiter.Allow(1)
+	for range testConfig.Burst + 1 {
+		limiter.Allow(2)
+	}
+
+	// через sweepInterval корзина пользователя 1 полная, у пользователя 2 недавнее превышение
+	*now = now.Add(sweepInterval)
+	limiter.Allow(3)
+
+	if _, ok := limiter.buckets[1]; ok {
+		t.Error("idle user with full bucket was not swept")
+	}
+	if _, ok := limiter.buckets[2]; !ok {
+		t.Error("user with a recent trip was swept")
+	}
+
+	*now = now.Add(testConfig.TripWindow)
+	limiter.Allow(3)
+	if _, ok := limiter.buckets[2]; ok {
+		t.Error("user was not swept after the trip window")
+	}
+}
@@ -0,0 +1,227 @@
+package regions
+
+import (
+	"math"
+	"slices"
+	"strings"
+)
+
+const (
+	// earthRadiusKm радиус Земли для расчета расстояний
+	earthRadiusKm = 6371
+	// maxNearestKm дальше от всех административных центров точка считается вне России:
+	// ближайший центр для нее ничего не говорит о регионе
+	maxNearestKm = 800
+)
+
+// Region субъект Российской Федерации. Code - код субъекта,
+// Lat и Lon - координаты административного центра
+type Region struct {
+	Code int64
+	Name string
+	Lat  float64
+	Lon  float64
+	// CityRadiusKm для городов федерального значения: точки в этом радиусе относятся к городу,
+	// остальные точки к городу не относятся, даже если он ближайший
+	CityRadiusKm float64
+}
+
+var all = []Region{
+	{Code: 1, Name: "Адыгея", Lat: 44.61, Lon: 40.10},
+	{Code: 2, Name: "Башкортостан", Lat: 54.74, Lon: 55.97},
+	{Code: 3, Name: "Бурятия", Lat: 51.83, Lon: 107.58},
+	{Code: 4, Name: "Алтай (Республика)", Lat: 51.96, Lon: 85.96},
+	{Code: 5, Name: "Дагестан", Lat: 42.98, Lon: 47.50},
+	{Code: 6, Name: "Ингушетия", Lat: 43.17, Lon: 44.81},
+	{Code: 7, Name: "Кабардино-Балкария", Lat: 43.49, Lon: 43.61},
+	{Code: 8, Name: "Калмыкия", Lat: 46.31, Lon: 44.26},
+	{Code: 9, Name: "Карачаево-Черкесия", Lat: 44.23, Lon: 42.05},
+	{Code: 10, Name: "Карелия", Lat: 61.79, Lon: 34.35},
+	{Code: 11, Name: "Коми", Lat: 61.67, Lon: 50.84},
+	{Code: 12, Name: "Марий Эл", Lat: 56.63, Lon: 47.89},
+	{Code: 13, Name: "Мордовия", Lat: 54.18, Lon: 45.17},
+	{Code: 14, Name: "Якутия", Lat: 62.03, Lon: 129.73},
+	{Code: 15, Name: "Северная Осетия — Алания", Lat: 43.02, Lon: 44.68},
+	{Code: 16, Name: "Татарстан", Lat: 55.79, Lon: 49.12},
+	{Code: 17, Name: "Тыва", Lat: 51.72, Lon: 94.45},
+	{Code: 18, Name: "Удмуртия", Lat: 56.85, Lon: 53.20},
+	{Code: 19, Name: "Хакасия", Lat: 53.72, Lon: 91.44},
+	{Code: 20, Name: "Чечня", Lat: 43.32, Lon: 45.69},
+	{Code: 21, Name: "Чувашия", Lat: 56.15, Lon: 47.25},
+	{Code: 22, Name: "Алтайский край", Lat: 53.35, Lon: 83.78},
+	{Code: 23, Name: "Краснодарский край", Lat: 45.04, Lon: 38.98},
+	{Code: 24, Name: "Красноярский край", Lat: 56.01, Lon: 92.87},
+	{Code: 25, Name: "Приморский край", Lat: 43.12, Lon: 131.89},
+	{Code: 26, Name: "Ставропольский край", Lat: 45.04, Lon: 41.97},
+	{Code: 27, Name: "Хабаровский край", Lat: 48.48, Lon: 135.08},
+	{Code: 28, Name: "Амурская область", Lat: 50.27, Lon: 127.54},
+	{Code: 29, Name: "Архангельская область", Lat: 64.54, Lon: 40.54},
+	{Code: 30, Name: "Астраханская область", Lat: 46.35, Lon: 48.04},
+	{Code: 31, Name: "Белгородская область", Lat: 50.60, Lon: 36.59},
+	{Code: 32, Name: "Брянская область", Lat: 53.24, Lon: 34.36},
+	{Code: 33, Name: "Владимирская область", Lat: 56.13, Lon: 40.41},
+	{Code: 34, Name: "Волгоградская область", Lat: 48.71, Lon: 44.51},
+	{Code: 35, Name: "Вологодская область", Lat: 59.22, Lon: 39.89},
+	{Code: 36, Name: "Воронежская область", Lat: 51.67, Lon: 39.18},
+	{Code: 37, Name: "Ивановская область", Lat: 57.00, Lon: 40.97},
+	{Code: 38, Name: "Иркутская область", Lat: 52.29, Lon: 104.28},
+	{Code: 39, Name: "Калининградская область", Lat: 54.71, Lon: 20.51},
+	{Code: 40, Name: "Калужская область", Lat: 54.51, Lon: 36.26},
+	{Code: 41, Name: "Камчатский край", Lat: 53.04, Lon: 158.65},
+	{Code: 42, Name: "Кемеровская область", Lat: 55.35, Lon: 86.09},
+	{Code: 43, Name: "Кировская область", Lat: 58.60, Lon: 49.67},
+	{Code: 44, Name: "Костромская область", Lat: 57.77, Lon: 40.93},
+	{Code: 45, Name: "Курганская область", Lat: 55.44, Lon: 65.34},
+	{Code: 46, Name: "Курская область", Lat: 51.73, Lon: 36.19},
+	{Code: 47, Name: "Ленинградская область", Lat: 59.90, Lon: 32.30},
+	{Code: 48, Name: "Липецкая область", Lat: 52.61, Lon: 39.57},
+	{Code: 49, Name: "Магаданская область", Lat: 59.56, Lon: 150.80},
+	{Code: 50, Name: "Московская область", Lat: 55.75, Lon: 37.62},
+	{Code: 51, Name: "Мурманская область", Lat: 68.97, Lon: 33.07},
+	{Code: 52, Name: "Нижегородская область", Lat: 56.33, Lon: 44.00},
+	{Code: 53, Name: "Новгородская область", Lat: 58.52, Lon: 31.27},
+	{Code: 54, Name: "Новосибирская область", Lat: 55.03, Lon: 82.92},
+	{Code: 55, Name: "Омская область", Lat: 54.99, Lon: 73.37},
+	{Code: 56, Name: "Оренбургская область", Lat: 51.77, Lon: 55.10},
+	{Code: 57, Name: "Орловская область", Lat: 52.97, Lon: 36.06},
+	{Code: 58, Name: "Пензенская область", Lat: 53.20, Lon: 45.00},
+	{Code: 59, Name: "Пермский край", Lat: 58.01, Lon: 56.25},
+	{Code: 60, Name: "Псковская область", Lat: 57.82, Lon: 28.33},
+	{Code: 61, Name: "Ростовская область", Lat: 47.23, Lon: 39.72},
+	{Code: 62, Name: "Рязанская область", Lat: 54.63, Lon: 39.74},
+	{Code: 63, Name: "Самарская область", Lat: 53.20, Lon: 50.15},
+	{Code: 64, Name: "Саратовская область", Lat: 51.53, Lon: 46.03},
+	{Code: 65, Name: "Сахалинская область", Lat: 46.96, Lon: 142.73},
+	{Code: 66, Name: "Свердловская область", Lat: 56.84, Lon: 60.61},
+	{Code: 67, Name: "Смоленская область", Lat: 54.78, Lon: 32.05},
+	{Code: 68, Name: "Тамбовская область", Lat: 52.72, Lon: 41.45},
+	{Code: 69, Name: "Тверская область", Lat: 56.86, Lon: 35.90},
+	{Code: 70, Name: "Томская область", Lat: 56.48, Lon: 84.95},
+	{Code: 71, Name: "Тульская область", Lat: 54.19, Lon: 37.62},
+	{Code: 72, Name: "Тюменская область", Lat: 57.15, Lon: 65.53},
+	{Code: 73, Name: "Ульяновская область", Lat: 54.32, Lon: 48.40},
+	{Code: 74, Name: "Челябинская область", Lat: 55.16, Lon: 61.40},
+	{Code: 75, Name: "Забайкальский край", Lat: 52.03, Lon: 113.50},
+	{Code: 76, Name: "Ярославская область", Lat: 57.63, Lon: 39.87},
+	{Code: 77, Name: "Москва", Lat: 55.75, Lon: 37.62, CityRadiusKm: 25},
+	{Code: 78, Name: "Санкт-Петербург", Lat: 59.94, Lon: 30.31, CityRadiusKm: 25},
+	{Code: 79, Name: "Еврейская автономная область", Lat: 48.79, Lon: 132.92},
+	{Code: 83, Name: "Ненецкий автономный округ", Lat: 67.64, Lon: 53.01},
+	{Code: 86, Name: "Ханты-Мансийский автономный округ — Югра", Lat: 61.00, Lon: 69.02},
+	{Code: 87, Name: "Чукотский автономный округ", Lat: 64.73, Lon: 177.51},
+	{Code: 89, Name: "Ямало-Ненецкий автономный округ", Lat: 66.53, Lon: 66.61},
+	{Code: 90, Name: "Запорожская область", Lat: 46.85, Lon: 35.37},
+	{Code: 91, Name: "Крым", Lat: 44.95, Lon: 34.10},
+	{Code: 92, Name: "Севастополь", Lat: 44.62, Lon: 33.52, CityRadiusKm: 15},
+	{Code: 93, Name: "Донецкая Народная Республика", Lat: 48.00, Lon: 37.80},
+	{Code: 94, Name: "Луганская Народная Республика", Lat: 48.57, Lon: 39.31},
+	{Code: 95, Name: "Херсонская область", Lat: 46.64, Lon: 32.62},
+}
+
+// All все субъекты в алфавитном порядке
+func All() []Region {
+	sorted := slices.Clone(all)
+	slices.SortFunc(sorted, func(a, b Region) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return sorted
+}
+
+// ByCode субъект по коду
+func ByCode(code int64) (Region, bool) {
+	for _, region := range all {
+		if region.Code == code {
+			return region, true
+		}
+	}
+
+	return Region{}, false
+}
+
+// Letters первые буквы названий субъектов в алфавитном порядке
+func Letters() []string {
+	letters := make([]string, 0)
+	for _, region := range All() {
+		letter := firstLetter(region.Name)
+		if !slices.Contains(letters, letter) {
+			letters = append(letters, letter)
+		}
+	}
+
+	return letters
+}
+
+// ByLetter субъекты, названия которых начинаются с буквы letter
+func ByLetter(letter string) []Region {
+	found := make([]Region, 0)
+	for _, region := range All() {
+		if firstLetter(region.Name) == letter {
+			found = append(found, region)
+		}
+	}
+
+	return found
+}
+
+// Search субъекты, в названии которых есть query, без учета регистра
+func Search(query string) []Region {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+
+	found := make([]Region, 0)
+	for _, region := range All() {
+		if strings.Contains(strings.ToLower(region.Name), query) {
+			found = append(found, region)
+		}
+	}
+
+	return found
+}
+
+// Nearest субъект с ближайшим к точке административным центром,
+// false если точка дальше maxNearestKm от всех центров
+func Nearest(lat, lon float64) (Region, bool) {
+	var nearest Region
+	minDistance := math.Inf(1)
+	for _, region := range all {
+		distance := distanceKm(lat, lon, region.Lat, region.Lon)
+		if region.CityRadiusKm > 0 {
+			if distance <= region.CityRadiusKm {
+				return region, true
+			}
+			continue
+		}
+		if distance < minDistance {
+			nearest, minDistance = region, distance
+		}
+	}
+
+	if minDistance > maxNearestKm {
+		return Region{}, false
+	}
+
+	return nearest, true
+}
+
+func firstLetter(name string) string {
+	for _, r := range name {
+		return string(r)
+	}
+
+	return ""
+}
+
+// distanceKm расстояние между точками по формуле гаверсинусов
+func distanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
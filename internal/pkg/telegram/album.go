@@ -0,0 +1,86 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// MediaGroupItem элемент альбома
+type MediaGroupItem struct {
+	// Kind тип вложения: photo, video, document или audio
+	Kind    string
+	FileID  string
+	Caption string
+	// Data содержимое файла для загрузки вместо FileID, например очищенная от метаданных копия
+	Data     []byte
+	FileName string
+}
+
+// SendMediaGroupToAdminChat отправка альбома пользователя в чат админов одним сообщением
+func (bot *Bot) SendMediaGroupToAdminChat(
+	replyToMessageID int64,
+	items []MediaGroupItem,
+) (messageIDs []int64, err error) {
+	media := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		var file tgbotapi.RequestFileData = tgbotapi.FileID(item.FileID)
+		if item.Data != nil {
+			file = tgbotapi.FileBytes{Name: item.FileName, Bytes: item.Data}
+		}
+		caption := truncateCaption(item.Caption)
+
+		switch item.Kind {
+		case "photo":
+			photo := tgbotapi.NewInputMediaPhoto(file)
+			photo.Caption = caption
+			media = append(media, photo)
+		case "video":
+			video := tgbotapi.NewInputMediaVideo(file)
+			video.Caption = caption
+			media = append(media, video)
+		case "document":
+			document := tgbotapi.NewInputMediaDocument(file)
+			document.Caption = caption
+			media = append(media, document)
+		case "audio":
+			audio := tgbotapi.NewInputMediaAudio(file)
+			audio.Caption = caption
+			media = append(media, audio)
+		default:
+			return nil, fmt.Errorf("%w: %s в альбоме", ErrUnsupportedMessageKind, item.Kind)
+		}
+	}
+
+	mediaGroup := tgbotapi.NewMediaGroup(bot.adminChatID, media)
+	mediaGroup.ReplyToMessageID = int(replyToMessageID)
+
+	messages, err := bot.Bot.SendMediaGroup(mediaGroup)
+	// в MediaGroupConfig библиотеки нет allow_sending_without_reply, как у одиночных вложений.
+	// Если карточку удалили, альбом отправляется без ответа, а не теряется целиком
+	if err != nil && mediaGroup.ReplyToMessageID != 0 && replyNotFound(err) {
+		mediaGroup.ReplyToMessageID = 0
+		messages, err = bot.Bot.SendMediaGroup(mediaGroup)
+	}
+	if err != nil {
+		bot.logger.Error("Ошибка пересылки альбома: " + err.Error())
+		return nil, err
+	}
+
+	messageIDs = make([]int64, 0, len(messages))
+	for _, message := range messages {
+		messageIDs = append(messageIDs, int64(message.MessageID))
+	}
+
+	return messageIDs, nil
+}
+
+// replyNotFound Telegram отклонил запрос, потому что сообщения, на которое отвечали, уже нет
+func replyNotFound(err error) bool {
+	var apiErr *tgbotapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusBadRequest &&
+		strings.Contains(strings.ToLower(apiErr.Message), "replied")
+}
@@ -0,0 +1,42 @@
+package telegram
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// SetCategoryMenu заменяет клавиатуру карточки меню выбора темы
+func (bot *Bot) SetCategoryMenu(messageID int64, card CardState, options []CategoryOption) error {
+	editMarkup := tgbotapi.NewEditMessageReplyMarkup(bot.adminChatID, int(messageID), card.categoryMarkup(options))
+
+	_, err := bot.Bot.Send(editMarkup)
+	if err != nil {
+		bot.logger.Error("Ошибка при показе меню тем в чате админов: " + err.Error())
+	}
+
+	return err
+}
+
+// SendCategoryChoice отправляет пользователю сообщение с клавиатурой выбора темы
+func (bot *Bot) SendCategoryChoice(chatID int64, text string, options []CategoryOption) (messageID int64, err error) {
+	msg := tgbotapi.NewMessage(chatID, text)
+	if len(options) > 0 {
+		msg.ReplyMarkup = userCategoryMarkup(options)
+	}
+
+	message, err := bot.Bot.Send(msg)
+	if err != nil {
+		bot.logger.Error("Ошибка отправки выбора темы: " + err.Error())
+	}
+
+	return int64(message.MessageID), err
+}
+
+// ConfirmUserChoice заменяет текст сообщения с выбором темы или региона, клавиатура при этом убирается
+func (bot *Bot) ConfirmUserChoice(chatID, messageID int64, text string) error {
+	_, err := bot.Bot.Send(tgbotapi.NewEditMessageText(chatID, int(messageID), text))
+	if err != nil {
+		bot.logger.Error("Ошибка подтверждения выбора: " + err.Error())
+	}
+
+	return err
+}
@@ -0,0 +1,40 @@
+package telegram
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Telegram убирает inline кнопки при редактировании текста, поэтому клавиатура передается заново,
+// card = nil - карточка без кнопок
+
+// EditAdminCardText изменение текста карточки обращения в чате админов
+func (bot *Bot) EditAdminCardText(messageID int64, text string, card *CardState) error {
+	edit := tgbotapi.NewEditMessageText(bot.adminChatID, int(messageID), text)
+	if card != nil {
+		markup := card.markup()
+		edit.ReplyMarkup = &markup
+	}
+
+	_, err := bot.Bot.Send(edit)
+	if err != nil {
+		bot.logger.Error("Ошибка редактирования карточки в чате админов: " + err.Error())
+	}
+
+	return err
+}
+
+// EditAdminCardCaption изменение подписи к медиа карточки обращения в чате админов
+func (bot *Bot) EditAdminCardCaption(messageID int64, caption string, card *CardState) error {
+	edit := tgbotapi.NewEditMessageCaption(bot.adminChatID, int(messageID), truncateCaption(caption))
+	if card != nil {
+		markup := card.markup()
+		edit.ReplyMarkup = &markup
+	}
+
+	_, err := bot.Bot.Send(edit)
+	if err != nil {
+		bot.logger.Error("Ошибка редактирования подписи в чате админов: " + err.Error())
+	}
+
+	return err
+}
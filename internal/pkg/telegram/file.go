@@ -0,0 +1,95 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// downloadTimeout время на скачивание одного файла
+const downloadTimeout = 2 * time.Minute
+
+// ErrFileTooLarge файл больше допустимого размера
+var ErrFileTooLarge = errors.New("file is too large")
+
+// DownloadFile скачивание файла пользователя через Bot API, файлы больше maxSize не скачиваются
+func (bot *Bot) DownloadFile(fileID string, maxSize int64) ([]byte, error) {
+	url, err := bot.Bot.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file url: %w", err)
+	}
+
+	client := http.Client{Timeout: downloadTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		// в тексте ошибки ссылка с токеном бота
+		return nil, errors.New("failed to download file")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, errors.New("failed to read file")
+	}
+	if int64(len(data)) > maxSize {
+		return nil, ErrFileTooLarge
+	}
+
+	return data, nil
+}
+
+// SendDocumentBytesToAdminChat загрузка документа в чат админов, например очищенной от метаданных копии
+func (bot *Bot) SendDocumentBytesToAdminChat(
+	replyToMessageID int64,
+	card CardState,
+	fileName string,
+	data []byte,
+	caption string,
+) (messageID int64, err error) {
+	document := tgbotapi.NewDocument(bot.adminChatID, tgbotapi.FileBytes{Name: fileName, Bytes: data})
+	document.BaseChat = bot.adminCardChat(replyToMessageID, card)
+	document.Caption = truncateCaption(caption)
+
+	return bot.sendToAdminChat(document)
+}
+
+// ReplyWithFileInAdminChat ответ файлом на сообщение в чате админов
+func (bot *Bot) ReplyWithFileInAdminChat(
+	replyToMessageID int64,
+	fileName string,
+	data []byte,
+	caption string,
+) (messageID int64, err error) {
+	document := tgbotapi.NewDocument(bot.adminChatID, tgbotapi.FileBytes{Name: fileName, Bytes: data})
+	document.ReplyToMessageID = int(replyToMessageID)
+	document.AllowSendingWithoutReply = true
+	document.Caption = truncateCaption(caption)
+
+	return bot.sendToAdminChat(document)
+}
+
+// SendFileToSuperAdmin отправка файла суперадмину в личные сообщения: загрузкой data
+// или по fileID, если файл не скачивался
+func (bot *Bot) SendFileToSuperAdmin(chatID int64, fileName string, data []byte, fileID string, caption string) (messageID int64, err error) {
+	var file tgbotapi.RequestFileData = tgbotapi.FileID(fileID)
+	if data != nil {
+		file = tgbotapi.FileBytes{Name: fileName, Bytes: data}
+	}
+	document := tgbotapi.NewDocument(chatID, file)
+	document.Caption = truncateCaption(caption)
+
+	message, err := bot.Bot.Send(document)
+	if err != nil {
+		bot.logger.Error("Ошибка отправки файла суперадмину: " + err.Error())
+	}
+
+	return int64(message.MessageID), err
+}
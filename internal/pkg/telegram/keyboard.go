@@ -0,0 +1,125 @@
+package telegram
+
+import (
+	"medrussia_news_bot/internal/pkg/callback"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// CardState состояние обращения, по которому рисуется клавиатура карточки
+type CardState struct {
+	AppealID   int64
+	InProgress bool
+	// Assignee имя ответственного редактора
+	Assignee string
+	Urgent   bool
+	Closed   bool
+	Spam     bool
+	Banned   bool
+	// Category название темы обращения
+	Category string
+	// Region название региона обращения
+	Region string
+}
+
+// CategoryOption тема обращения в клавиатуре выбора
+type CategoryOption struct {
+	ID    int64
+	Title string
+}
+
+// button кнопка действия над обращением карточки. В данных кнопки только ID обращения,
+// пользователь определяется на сервере
+func (c CardState) button(text, action string) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData(text, callback.MustEncode(action, c.AppealID))
+}
+
+// mark кнопка-отметка без действия
+func (c CardState) mark(text string) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData(text, callback.MustEncode(callback.ActionIgnore))
+}
+
+// markup клавиатура карточки обращения в чате админов
+func (c CardState) markup() tgbotapi.InlineKeyboardMarkup {
+	history := c.button("📜 Показать историю", callback.ActionHistory)
+	category := c.button("🏷 Без темы", callback.ActionCategoryMenu)
+	if c.Category != "" {
+		category = c.button("🏷 "+c.Category, callback.ActionCategoryMenu)
+	}
+	region := c.mark("📍 Регион не указан")
+	if c.Region != "" {
+		region = c.mark("📍 " + c.Region)
+	}
+
+	if c.Closed {
+		status := c.mark("⭕️ Обращение закрыто ⭕️️")
+		if c.Spam {
+			status = c.mark("🗑 Спам")
+		} else if c.Banned {
+			status = c.mark("🚫 Заблокирован, обращение закрыто")
+		}
+
+		return tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(status),
+			tgbotapi.NewInlineKeyboardRow(c.button("🔄 Переоткрыть", callback.ActionReopen), history),
+			tgbotapi.NewInlineKeyboardRow(category, region),
+		)
+	}
+
+	take := c.button("🙋 Взять в работу", callback.ActionTake)
+	if c.InProgress {
+		take = c.mark("✅ В работе")
+		if c.Assignee != "" {
+			take = c.mark("✅ В работе: " + c.Assignee)
+		}
+	}
+
+	urgent := c.button("🔥 Срочно", callback.ActionUrgent)
+	if c.Urgent {
+		urgent = c.button("🔥 Срочно ✓", callback.ActionUrgent)
+	}
+
+	ban := c.button("🚫 Заблокировать", callback.ActionBan)
+	if c.Banned {
+		ban = c.mark("🚫 Заблокирован")
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(take, urgent),
+		tgbotapi.NewInlineKeyboardRow(c.button("🗑 Спам", callback.ActionSpam), ban),
+		tgbotapi.NewInlineKeyboardRow(c.button("Закрыть обращение ❇️", callback.ActionClose)),
+		tgbotapi.NewInlineKeyboardRow(category, region),
+		tgbotapi.NewInlineKeyboardRow(history),
+	)
+}
+
+// categoryMarkup клавиатура выбора темы на карточке
+func (c CardState) categoryMarkup(options []CategoryOption) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(options)+1)
+	for _, option := range options {
+		text := option.Title
+		if option.Title == c.Category {
+			text = "✓ " + text
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(text, callback.MustEncode(callback.ActionSetCategory, c.AppealID, option.ID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("↩️ Назад", callback.MustEncode(callback.ActionSetCategory, c.AppealID, 0)),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// userCategoryMarkup клавиатура выбора темы для пользователя
+func userCategoryMarkup(options []CategoryOption) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(options))
+	for _, option := range options {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(option.Title, callback.MustEncode(callback.ActionUserCategory, option.ID)),
+		))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
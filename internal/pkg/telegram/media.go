@@ -0,0 +1,121 @@
+package telegram
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// captionLimit максимальная длина подписи к медиа в Telegram
+const captionLimit = 1024
+
+// adminCardChat базовые параметры карточки обращения в чате админов
+func (bot *Bot) adminCardChat(replyToMessageID int64, card CardState) tgbotapi.BaseChat {
+	baseChat := tgbotapi.BaseChat{
+		ChatID:      bot.adminChatID,
+		ReplyMarkup: card.markup(),
+	}
+	if replyToMessageID != 0 {
+		baseChat.ReplyToMessageID = int(replyToMessageID)
+		baseChat.AllowSendingWithoutReply = true
+	}
+
+	return baseChat
+}
+
+// sendToAdminChat отправка медиа в чат админов
+func (bot *Bot) sendToAdminChat(c tgbotapi.Chattable) (messageID int64, err error) {
+	message, err := bot.Bot.Send(c)
+	if err != nil {
+		bot.logger.Error("Ошибка пересылки медиа: " + err.Error())
+	}
+
+	return int64(message.MessageID), err
+}
+
+// truncateCaption обрезает подпись до лимита Telegram
+func truncateCaption(caption string) string {
+	runes := []rune(caption)
+	if len(runes) <= captionLimit {
+		return caption
+	}
+
+	return string(runes[:captionLimit-1]) + "…"
+}
+
+// SendPhotoToAdminChat отправка фото пользователя в чат админов
+func (bot *Bot) SendPhotoToAdminChat(replyToMessageID int64, card CardState, fileID, caption string) (messageID int64, err error) {
+	photo := tgbotapi.NewPhoto(bot.adminChatID, tgbotapi.FileID(fileID))
+	photo.BaseChat = bot.adminCardChat(replyToMessageID, card)
+	photo.Caption = truncateCaption(caption)
+
+	return bot.sendToAdminChat(photo)
+}
+
+// SendVideoToAdminChat отправка видео пользователя в чат админов
+func (bot *Bot) SendVideoToAdminChat(replyToMessageID int64, card CardState, fileID, caption string) (messageID int64, err error) {
+	video := tgbotapi.NewVideo(bot.adminChatID, tgbotapi.FileID(fileID))
+	video.BaseChat = bot.adminCardChat(replyToMessageID, card)
+	video.Caption = truncateCaption(caption)
+
+	return bot.sendToAdminChat(video)
+}
+
+// SendVideoNoteToAdminChat отправка видеосообщения (кружка) пользователя в чат админов
+func (bot *Bot) SendVideoNoteToAdminChat(replyToMessageID int64, card CardState, fileID string) (messageID int64, err error) {
+	videoNote := tgbotapi.NewVideoNote(bot.adminChatID, 0, tgbotapi.FileID(fileID))
+	videoNote.BaseChat = bot.adminCardChat(replyToMessageID, card)
+
+	return bot.sendToAdminChat(videoNote)
+}
+
+// SendVoiceToAdminChat отправка голосового сообщения пользователя в чат админов
+func (bot *Bot) SendVoiceToAdminChat(replyToMessageID int64, card CardState, fileID, caption string) (messageID int64, err error) {
+	voice := tgbotapi.NewVoice(bot.adminChatID, tgbotapi.FileID(fileID))
+	voice.BaseChat = bot.adminCardChat(replyToMessageID, card)
+	voice.Caption = truncateCaption(caption)
+
+	return bot.sendToAdminChat(voice)
+}
+
+// SendAudioToAdminChat отправка аудиофайла пользователя в чат админов
+func (bot *Bot) SendAudioToAdminChat(replyToMessageID int64, card CardState, fileID, caption string) (messageID int64, err error) {
+	audio := tgbotapi.NewAudio(bot.adminChatID, tgbotapi.FileID(fileID))
+	audio.BaseChat = bot.adminCardChat(replyToMessageID, card)
+	audio.Caption = truncateCaption(caption)
+
+	return bot.sendToAdminChat(audio)
+}
+
+// SendDocumentToAdminChat отправка документа пользователя в чат админов
+func (bot *Bot) SendDocumentToAdminChat(replyToMessageID int64, card CardState, fileID, caption string) (messageID int64, err error) {
+	document := tgbotapi.NewDocument(bot.adminChatID, tgbotapi.FileID(fileID))
+	document.BaseChat = bot.adminCardChat(replyToMessageID, card)
+	document.Caption = truncateCaption(caption)
+
+	return bot.sendToAdminChat(document)
+}
+
+// SendAnimationToAdminChat отправка GIF-анимации пользователя в чат админов
+func (bot *Bot) SendAnimationToAdminChat(replyToMessageID int64, card CardState, fileID, caption string) (messageID int64, err error) {
+	animation := tgbotapi.NewAnimation(bot.adminChatID, tgbotapi.FileID(fileID))
+	animation.BaseChat = bot.adminCardChat(replyToMessageID, card)
+	animation.Caption = truncateCaption(caption)
+
+	return bot.sendToAdminChat(animation)
+}
+
+// SendStickerToAdminChat отправка стикера пользователя в чат админов
+func (bot *Bot) SendStickerToAdminChat(replyToMessageID int64, card CardState, fileID string) (messageID int64, err error) {
+	sticker := tgbotapi.NewSticker(bot.adminChatID, tgbotapi.FileID(fileID))
+	sticker.BaseChat = bot.adminCardChat(replyToMessageID, card)
+
+	return bot.sendToAdminChat(sticker)
+}
+
+// SendContactToAdminChat отправка контакта, которым поделился пользователь, в чат админов
+func (bot *Bot) SendContactToAdminChat(replyToMessageID int64, card CardState, phoneNumber, firstName, lastName string) (messageID int64, err error) {
+	contact := tgbotapi.NewContact(bot.adminChatID, phoneNumber, firstName)
+	contact.BaseChat = bot.adminCardChat(replyToMessageID, card)
+	contact.LastName = lastName
+
+	return bot.sendToAdminChat(contact)
+}
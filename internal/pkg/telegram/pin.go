@@ -0,0 +1,49 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Mention упоминание участника чата, которое приходит ему уведомлением
+type Mention struct {
+	UserID int64
+	Name   string
+}
+
+// PinInAdminChat закрепляет сообщение в чате админов
+func (bot *Bot) PinInAdminChat(messageID int64) error {
+	pin := tgbotapi.PinChatMessageConfig{
+		ChatID:    bot.adminChatID,
+		MessageID: int(messageID),
+	}
+
+	_, err := bot.Bot.Request(pin)
+	if err != nil {
+		bot.logger.Error("Ошибка закрепления сообщения в чате админов: " + err.Error())
+	}
+
+	return err
+}
+
+// MentionInAdminChat ответ на сообщение в чате админов с упоминанием участников
+func (bot *Bot) MentionInAdminChat(replyToMessageID int64, text string, mentions []Mention) (messageID int64, err error) {
+	names := make([]string, 0, len(mentions))
+	for _, mention := range mentions {
+		names = append(names, fmt.Sprintf(`<a href="tg://user?id=%d">%s</a>`, mention.UserID, html.EscapeString(mention.Name)))
+	}
+
+	msg := tgbotapi.NewMessage(bot.adminChatID, html.EscapeString(text)+"\n"+strings.Join(names, ", "))
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.ReplyToMessageID = int(replyToMessageID)
+
+	message, err := bot.Bot.Send(msg)
+	if err != nil {
+		bot.logger.Error("Ошибка упоминания в чате админов: " + err.Error())
+	}
+
+	return int64(message.MessageID), err
+}
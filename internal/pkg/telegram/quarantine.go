@@ -0,0 +1,25 @@
+package telegram
+
+import (
+	"medrussia_news_bot/internal/pkg/callback"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// SendQuarantineCard отправляет в чат админов сообщение из карантина с кнопками «Не спам» и «Удалить»
+func (bot *Bot) SendQuarantineCard(quarantineID int64, text string) (messageID int64, err error) {
+	msg := tgbotapi.NewMessage(bot.adminChatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Не спам", callback.MustEncode(callback.ActionRelease, quarantineID)),
+			tgbotapi.NewInlineKeyboardButtonData("🗑 Удалить", callback.MustEncode(callback.ActionDiscard, quarantineID)),
+		),
+	)
+
+	message, err := bot.Bot.Send(msg)
+	if err != nil {
+		bot.logger.Error("Ошибка отправки сообщения из карантина: " + err.Error())
+	}
+
+	return int64(message.MessageID), err
+}
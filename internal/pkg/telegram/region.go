@@ -0,0 +1,102 @@
+package telegram
+
+import (
+	"medrussia_news_bot/internal/pkg/callback"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// regionLettersPerRow букв в строке клавиатуры выбора региона
+const regionLettersPerRow = 6
+
+// RegionOption регион в клавиатуре выбора
+type RegionOption struct {
+	Code int64
+	Name string
+}
+
+// regionLettersMarkup клавиатура выбора первой буквы региона
+func regionLettersMarkup(letters []string) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(letters)/regionLettersPerRow+1)
+	row := make([]tgbotapi.InlineKeyboardButton, 0, regionLettersPerRow)
+	for i, letter := range letters {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(letter, callback.MustEncode(callback.ActionRegionLetter, int64(i))))
+		if len(row) == regionLettersPerRow {
+			rows = append(rows, row)
+			row = make([]tgbotapi.InlineKeyboardButton, 0, regionLettersPerRow)
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// regionOptionsMarkup клавиатура выбора региона из списка
+func regionOptionsMarkup(options []RegionOption) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(options)+1)
+	for _, option := range options {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(option.Name, callback.MustEncode(callback.ActionUserRegion, option.Code)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("↩️ Другая буква", callback.MustEncode(callback.ActionRegionBack)),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// SendRegionChoice отправляет пользователю сообщение с клавиатурой выбора региона по первой букве
+func (bot *Bot) SendRegionChoice(chatID int64, text string, letters []string) (messageID int64, err error) {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = regionLettersMarkup(letters)
+
+	message, err := bot.Bot.Send(msg)
+	if err != nil {
+		bot.logger.Error("Ошибка отправки выбора региона: " + err.Error())
+	}
+
+	return int64(message.MessageID), err
+}
+
+// ShowRegionLetters возвращает в сообщении клавиатуру выбора первой буквы региона
+func (bot *Bot) ShowRegionLetters(chatID, messageID int64, letters []string) error {
+	_, err := bot.Bot.Send(tgbotapi.NewEditMessageReplyMarkup(chatID, int(messageID), regionLettersMarkup(letters)))
+	if err != nil {
+		bot.logger.Error("Ошибка показа букв регионов: " + err.Error())
+	}
+
+	return err
+}
+
+// ShowRegionOptions показывает в сообщении список регионов на выбранную букву
+func (bot *Bot) ShowRegionOptions(chatID, messageID int64, options []RegionOption) error {
+	_, err := bot.Bot.Send(tgbotapi.NewEditMessageReplyMarkup(chatID, int(messageID), regionOptionsMarkup(options)))
+	if err != nil {
+		bot.logger.Error("Ошибка показа списка регионов: " + err.Error())
+	}
+
+	return err
+}
+
+// SendRegionConfirm предложение подтвердить регион, определенный по геопозиции, или выбрать другой
+func (bot *Bot) SendRegionConfirm(chatID int64, text string, option RegionOption) (messageID int64, err error) {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ "+option.Name, callback.MustEncode(callback.ActionUserRegion, option.Code)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✏️ Другой регион", callback.MustEncode(callback.ActionRegionBack)),
+		),
+	)
+
+	message, err := bot.Bot.Send(msg)
+	if err != nil {
+		bot.logger.Error("Ошибка отправки подтверждения региона: " + err.Error())
+	}
+
+	return int64(message.MessageID), err
+}
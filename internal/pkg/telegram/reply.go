@@ -0,0 +1,141 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// adminReplyPrefix префикс ответа администрации пользователю
+const adminReplyPrefix = "Ответ администрации бота:"
+
+// ErrUnsupportedMessageKind тип сообщения админа нельзя переслать пользователю
+var ErrUnsupportedMessageKind = errors.New("unsupported message kind")
+
+// adminReplyText добавляет к тексту или подписи префикс ответа администрации
+func adminReplyText(text string) string {
+	if text == "" {
+		return adminReplyPrefix
+	}
+
+	return fmt.Sprintf("%s \n\n %s", adminReplyPrefix, text)
+}
+
+// ForwardAdminMessageToUser пересылка ответа админа пользователю ответом на его сообщение replyToMessageID,
+// возвращает ID доставленного сообщения
+func (bot *Bot) ForwardAdminMessageToUser(chatID, replyToMessageID int64, msg *tgbotapi.Message) (sentMessageID int64, err error) {
+	var reply tgbotapi.Chattable
+
+	baseChat := tgbotapi.BaseChat{
+		ChatID:                   chatID,
+		ReplyToMessageID:         int(replyToMessageID),
+		AllowSendingWithoutReply: true,
+	}
+
+	switch {
+	case msg.Text != "":
+		message := tgbotapi.NewMessage(chatID, adminReplyText(msg.Text))
+		message.BaseChat = baseChat
+		reply = message
+	case len(msg.Photo) > 0:
+		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileID(msg.Photo[len(msg.Photo)-1].FileID))
+		photo.BaseChat = baseChat
+		photo.Caption = truncateCaption(adminReplyText(msg.Caption))
+		reply = photo
+	case msg.Animation != nil:
+		animation := tgbotapi.NewAnimation(chatID, tgbotapi.FileID(msg.Animation.FileID))
+		animation.BaseChat = baseChat
+		animation.Caption = truncateCaption(adminReplyText(msg.Caption))
+		reply = animation
+	case msg.Video != nil:
+		video := tgbotapi.NewVideo(chatID, tgbotapi.FileID(msg.Video.FileID))
+		video.BaseChat = baseChat
+		video.Caption = truncateCaption(adminReplyText(msg.Caption))
+		reply = video
+	case msg.Voice != nil:
+		voice := tgbotapi.NewVoice(chatID, tgbotapi.FileID(msg.Voice.FileID))
+		voice.BaseChat = baseChat
+		voice.Caption = truncateCaption(adminReplyText(msg.Caption))
+		reply = voice
+	case msg.Audio != nil:
+		audio := tgbotapi.NewAudio(chatID, tgbotapi.FileID(msg.Audio.FileID))
+		audio.BaseChat = baseChat
+		audio.Caption = truncateCaption(adminReplyText(msg.Caption))
+		reply = audio
+	case msg.Document != nil:
+		document := tgbotapi.NewDocument(chatID, tgbotapi.FileID(msg.Document.FileID))
+		document.BaseChat = baseChat
+		document.Caption = truncateCaption(adminReplyText(msg.Caption))
+		reply = document
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedMessageKind, MessageKindName(msg))
+	}
+
+	message, err := bot.Bot.Send(reply)
+	if err != nil {
+		bot.logger.Error(fmt.Sprintf("failed to forward message: %v", err))
+		return 0, err
+	}
+
+	return int64(message.MessageID), nil
+}
+
+// MessageKindName человекочитаемое название типа сообщения
+func MessageKindName(msg *tgbotapi.Message) string {
+	switch {
+	case msg.Text != "":
+		return "текст"
+	case len(msg.Photo) > 0:
+		return "фото"
+	case msg.Animation != nil:
+		return "GIF-анимация"
+	case msg.Video != nil:
+		return "видео"
+	case msg.VideoNote != nil:
+		return "видеосообщение"
+	case msg.Voice != nil:
+		return "голосовое сообщение"
+	case msg.Audio != nil:
+		return "аудио"
+	case msg.Document != nil:
+		return "документ"
+	case msg.Sticker != nil:
+		return "стикер"
+	case msg.Contact != nil:
+		return "контакт"
+	case msg.Location != nil:
+		return "геопозиция"
+	case msg.Poll != nil:
+		return "опрос"
+	}
+
+	return "неизвестный тип"
+}
+
+// EditAdminReply перенос правки ответа админа на копию, доставленную пользователю
+func (bot *Bot) EditAdminReply(chatID, messageID int64, msg *tgbotapi.Message) error {
+	var edit tgbotapi.Chattable
+	if msg.Text != "" {
+		edit = tgbotapi.NewEditMessageText(chatID, int(messageID), adminReplyText(msg.Text))
+	} else {
+		edit = tgbotapi.NewEditMessageCaption(chatID, int(messageID), truncateCaption(adminReplyText(msg.Caption)))
+	}
+
+	_, err := bot.Bot.Send(edit)
+	if err != nil {
+		bot.logger.Error(fmt.Sprintf("failed to edit admin reply: %v", err))
+	}
+
+	return err
+}
+
+// RecallAdminReply удаление ответа админа из чата пользователя
+func (bot *Bot) RecallAdminReply(chatID, messageID int64) error {
+	_, err := bot.Bot.Request(tgbotapi.NewDeleteMessage(chatID, int(messageID)))
+	if err != nil {
+		bot.logger.Error(fmt.Sprintf("failed to recall admin reply: %v", err))
+	}
+
+	return err
+}
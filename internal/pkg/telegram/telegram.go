@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"medrussia_news_bot/internal/config"
+	"medrussia_news_bot/internal/pkg/callback"
 	"strconv"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -16,8 +17,9 @@ type Bot struct {
 }
 
 func NewTelegramBot(cfg *config.Config, logger *slog.Logger) *Bot {
+	// режим отладки библиотеки не включается: он пишет в лог запросы и ответы Telegram целиком,
+	// с ID, именем и текстом источника, мимо защиты источников и шифрования
 	bot, err := tgbotapi.NewBotAPI(cfg.Bot.Token)
-	bot.Debug = true
 	if err != nil {
 		panic("can't create bot instance")
 	}
@@ -72,30 +74,30 @@ func (bot *Bot) ForwardMessage(msg *tgbotapi.Message) {
 	return
 }
 
-func (bot *Bot) ForwardAdminMessageToUser(chatID int64, msg *tgbotapi.Message) int64 {
-	forwardConfig := tgbotapi.NewMessage(
-		chatID,
-		fmt.Sprintf("Ответ администрации бота: \n\n %s", msg.Text),
-	)
+func (bot *Bot) SendMessageToAdmin(
+	text string,
+) (messageID int64, err error) {
+	msg := tgbotapi.NewMessage(bot.adminChatID, text)
 
-	// Отправляем сообщение
-	_, err := bot.Bot.Send(forwardConfig)
+	message, err := bot.Bot.Send(msg)
 	if err != nil {
-		_, _ = bot.Bot.Send(tgbotapi.NewMessage(bot.adminChatID, "Ошибка при отправке сообщения"))
-		bot.logger.Error(fmt.Sprintf("failed to forward message: %v", err))
+		bot.logger.Error("Ошибка пересылки сообщения: " + err.Error())
 	}
 
-	return int64(msg.MessageID)
+	return int64(message.MessageID), err
 }
 
-func (bot *Bot) SendMessageToAdmin(
-	text string,
+// ReplyInAdminChat ответ на сообщение в чате админов
+func (bot *Bot) ReplyInAdminChat(
+	replyToMessageID int64, text string,
 ) (messageID int64, err error) {
 	msg := tgbotapi.NewMessage(bot.adminChatID, text)
+	msg.ReplyToMessageID = int(replyToMessageID)
+	msg.AllowSendingWithoutReply = true
 
 	message, err := bot.Bot.Send(msg)
 	if err != nil {
-		bot.logger.Error("Ошибка пересылки сообщения: " + err.Error())
+		bot.logger.Error("Ошибка отправки сообщения в чат админов: " + err.Error())
 	}
 
 	return int64(message.MessageID), err
@@ -114,9 +116,10 @@ func (bot *Bot) SendMessageToSuperAdmin(
 	return int64(message.MessageID), err
 }
 
+// ForwardMessageToAdminChat отправка карточки обращения в чат админов с клавиатурой действий
 func (bot *Bot) ForwardMessageToAdminChat(
-	replyToMessageID,
-	chatID int64,
+	replyToMessageID int64,
+	card CardState,
 	text string,
 ) (messageID int64, err error) {
 	msg := tgbotapi.NewMessage(bot.adminChatID, text)
@@ -125,21 +128,13 @@ func (bot *Bot) ForwardMessageToAdminChat(
 		msg.ReplyToMessageID = int(replyToMessageID)
 	}
 
-	// callback читается как "'действие' _ 'ID пользователя'
-	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(
-			"Закрыть обращение ❇️",
-			fmt.Sprintf("close_%d", chatID),
-		)),
-	)
+	msg.ReplyMarkup = card.markup()
 
 	message, err := bot.Bot.Send(msg)
 	if err != nil {
 		bot.logger.Error("Ошибка пересылки сообщения: " + err.Error())
 	}
 
-	bot.logger.Info(fmt.Sprintf("Сообщение пользователя: %v", message))
-
 	return int64(message.MessageID), err
 }
 
@@ -159,30 +154,59 @@ func (bot *Bot) CleanMessageButtonsInAdminChat(
 	return int64(message.MessageID), err
 }
 
-func (bot *Bot) SetCloseButtonInAdminChat(
+// MarkCardInAdminChat заменяет клавиатуру сообщения в чате админов одной кнопкой-отметкой без действия
+func (bot *Bot) MarkCardInAdminChat(
 	messageID int64,
+	text string,
+) (editedMessageID int64, err error) {
+	markup := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(text, callback.MustEncode(callback.ActionIgnore)),
+		),
+	)
+
+	editMarkup := tgbotapi.NewEditMessageReplyMarkup(bot.adminChatID, int(messageID), markup)
+
+	message, err := bot.Bot.Send(editMarkup)
+	if err != nil {
+		bot.logger.Error("Ошибка при отметке сообщения в чате админов: " + err.Error())
+	}
+
+	return int64(message.MessageID), err
+}
+
+// SetCardKeyboard перерисовывает клавиатуру карточки под текущее состояние обращения
+func (bot *Bot) SetCardKeyboard(
+	messageID int64,
+	card CardState,
 ) (editedMessageID int64, err error) {
 	if messageID == 0 {
 		return
 	}
 
-	closedMarkup := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(
-			"⭕️ Обращение закрыто ⭕️️",
-			"ignore",
-		)),
-	)
-
-	editMarkup := tgbotapi.NewEditMessageReplyMarkup(bot.adminChatID, int(messageID), closedMarkup)
+	editMarkup := tgbotapi.NewEditMessageReplyMarkup(bot.adminChatID, int(messageID), card.markup())
 
 	message, err := bot.Bot.Send(editMarkup)
 	if err != nil {
-		bot.logger.Error("Ошибка при изменение кнопки на закрытие в чате админов: " + err.Error())
+		bot.logger.Error("Ошибка при изменении клавиатуры карточки в чате админов: " + err.Error())
 	}
 
 	return int64(message.MessageID), err
 }
 
+// AnswerCallback ответ на нажатие inline кнопки, без него у нажавшего крутится индикатор загрузки
+func (bot *Bot) AnswerCallback(callbackID, text string, alert bool) error {
+	answer := tgbotapi.NewCallback(callbackID, text)
+	answer.ShowAlert = alert
+
+	_, err := bot.Bot.Request(answer)
+	if err != nil {
+		bot.logger.Error("Ошибка ответа на callback: " + err.Error())
+	}
+
+	return err
+}
+
 func (bot *Bot) SendMessageAndGetId(msg tgbotapi.MessageConfig) int {
 	sentMessage, err := bot.Bot.Send(msg)
 	if err != nil {
@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"medrussia_news_bot/internal/pkg/filestore"
+)
+
+// reencryptBatchSize строк таблицы за один запрос при перешифровании
+const reencryptBatchSize = 500
+
+// NewReencryptApp приложение для команды reencrypt: база, ключи и хранилище файлов без бота и сервера
+func NewReencryptApp(ctx context.Context) *App {
+	a := &App{}
+
+	a.initLogger(ctx).
+		initConfig(ctx).
+		initPgxConn(ctx).
+		initKeyring(ctx).
+		initRepo(ctx).
+		initFileStore(ctx)
+
+	return a
+}
+
+// Reencrypt перешифровывает строки базы и файлы под активный ключ из конфига.
+// Команду можно прерывать и запускать повторно, уже перешифрованные данные пропускаются.
+// Обычный режим меняет только обертку ключей данных. Если старый ключ скомпрометирован,
+// нужен full: тогда все данные шифруются новыми ключами данных и повторный запуск
+// после прерывания перешифровывает все заново
+func (a *App) Reencrypt(ctx context.Context, full bool) error {
+	target := a.keyring.ActiveKeyID()
+	switch {
+	case target == "":
+		a.logger.Info("encryption is disabled, decrypting all data")
+	case full:
+		a.logger.Info(fmt.Sprintf("reencrypting all data with new data keys under key %s", target))
+	default:
+		a.logger.Info(fmt.Sprintf("reencrypting data with key %s", target))
+	}
+
+	stats, err := a.repo.Reencrypt(ctx, reencryptBatchSize, full)
+	for _, stat := range stats {
+		a.logger.Info(fmt.Sprintf("table %s: %d rows reencrypted", stat.Table, stat.Rows))
+	}
+	if err != nil {
+		return err
+	}
+
+	keys, err := a.repo.ListStoredFileKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	var changed, missing int
+	for _, key := range keys {
+		ok, err := a.files.Reencrypt(ctx, key, full)
+		if errors.Is(err, filestore.ErrNotFound) {
+			a.logger.Warn(fmt.Sprintf("file %s not found in store", key))
+			missing++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if ok {
+			changed++
+		}
+	}
+	a.logger.Info(fmt.Sprintf("files: %d of %d reencrypted, %d missing", changed, len(keys), missing))
+
+	return nil
+}
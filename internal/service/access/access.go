@@ -0,0 +1,151 @@
+package access
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"medrussia_news_bot/internal/config"
+	"medrussia_news_bot/internal/infrastructure/repo"
+	"slices"
+)
+
+// Role роль участника чата админов
+type Role string
+
+const (
+	RoleSuperAdmin Role = "superadmin"
+	RoleEditor     Role = "editor"
+	RoleViewer     Role = "viewer"
+)
+
+// Permission право на действие в чате админов
+type Permission int
+
+const (
+	// PermissionRead просмотр обращений, истории и отчетов
+	PermissionRead Permission = iota
+	// PermissionReply ответы пользователям, их правка и отзыв
+	PermissionReply
+	// PermissionManage закрытие, назначение, отметки срочности, спама и блокировки
+	PermissionManage
+	// PermissionAdmin управление ролями и журнал аудита
+	PermissionAdmin
+)
+
+var (
+	// ErrUnknownRole неизвестная роль
+	ErrUnknownRole = errors.New("unknown role")
+	// ErrConfigRole роль задана в конфиге и не может быть изменена командой
+	ErrConfigRole = errors.New("role is set in config")
+)
+
+// ParseRole разбор названия роли
+func ParseRole(name string) (Role, error) {
+	switch role := Role(name); role {
+	case RoleSuperAdmin, RoleEditor, RoleViewer:
+		return role, nil
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrUnknownRole, name)
+}
+
+// Can есть ли у роли право permission
+func (r Role) Can(permission Permission) bool {
+	switch r {
+	case RoleSuperAdmin:
+		return true
+	case RoleEditor:
+		return permission != PermissionAdmin
+	case RoleViewer:
+		return permission == PermissionRead
+	}
+
+	return false
+}
+
+// Title название роли для сообщений в чате
+func (r Role) Title() string {
+	switch r {
+	case RoleSuperAdmin:
+		return "суперадмин"
+	case RoleEditor:
+		return "редактор"
+	case RoleViewer:
+		return "наблюдатель"
+	}
+
+	return string(r)
+}
+
+// Repo хранилище ролей, выданных командой
+type Repo interface {
+	GetEditorRole(ctx context.Context, tgID int64) (string, error)
+	SetEditorRole(ctx context.Context, tgID int64, role string, grantedBy int64) error
+}
+
+// Service проверка прав участников чата админов
+type Service struct {
+	cfg    config.RolesConfig
+	repo   Repo
+	logger *slog.Logger
+}
+
+// NewService конструктор
+func NewService(cfg config.RolesConfig, repo Repo, logger *slog.Logger) *Service {
+	return &Service{
+		cfg:    cfg,
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Role роль участника: суперадмины из конфига, затем роль из базы,
+// затем списки редакторов и наблюдателей из конфига и роль по умолчанию
+func (s *Service) Role(ctx context.Context, tgID int64) Role {
+	if slices.Contains(s.cfg.SuperAdmins, tgID) {
+		return RoleSuperAdmin
+	}
+
+	name, err := s.repo.GetEditorRole(ctx, tgID)
+	switch {
+	case err == nil:
+		role, err := ParseRole(name)
+		if err == nil {
+			return role
+		}
+		s.logger.Error(fmt.Sprintf("Некорректная роль участника %d: %s", tgID, err))
+	case !errors.Is(err, repo.ErrEditorRoleNotFound):
+		// при ошибке базы не даем прав больше, чем у наблюдателя
+		s.logger.Error(fmt.Sprintf("%s", err))
+		return RoleViewer
+	}
+
+	switch {
+	case slices.Contains(s.cfg.Editors, tgID):
+		return RoleEditor
+	case slices.Contains(s.cfg.Viewers, tgID):
+		return RoleViewer
+	}
+
+	role, err := ParseRole(s.cfg.Default)
+	if err != nil {
+		return RoleViewer
+	}
+
+	return role
+}
+
+// Can есть ли у участника право permission
+func (s *Service) Can(ctx context.Context, tgID int64, permission Permission) bool {
+	return s.Role(ctx, tgID).Can(permission)
+}
+
+// SetRole выдача роли участнику. Суперадмины задаются только в конфиге
+func (s *Service) SetRole(ctx context.Context, tgID int64, role Role, grantedBy int64) error {
+	if role == RoleSuperAdmin || slices.Contains(s.cfg.SuperAdmins, tgID) {
+		return ErrConfigRole
+	}
+
+	return s.repo.SetEditorRole(ctx, tgID, string(role), grantedBy)
+}
@@ -0,0 +1,213 @@
+package alert
+
+import (
+	"fmt"
+	"log/slog"
+	"medrussia_news_bot/internal/config"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Severity уровень важности оповещения
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+// ParseSeverity разбор уровня из конфига, неизвестный уровень считается warning
+func ParseSeverity(name string) Severity {
+	switch strings.ToLower(name) {
+	case "info":
+		return SeverityInfo
+	case "error":
+		return SeverityError
+	case "critical":
+		return SeverityCritical
+	}
+
+	return SeverityWarning
+}
+
+// String метка уровня в тексте оповещения
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "ℹ️ INFO"
+	case SeverityWarning:
+		return "⚠️ WARNING"
+	case SeverityError:
+		return "❗️ ERROR"
+	case SeverityCritical:
+		return "🆘 CRITICAL"
+	}
+
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+// Sender отправка сообщения в чат telegram
+type Sender interface {
+	SendMessageToSuperAdmin(chatID int64, text string) (int64, error)
+}
+
+// suppressed повторы оповещения внутри окна дедупликации
+type suppressed struct {
+	sentAt  time.Time
+	count   int
+	last    string
+	flusher *time.Timer
+}
+
+// Service рассылка оповещений об ошибках.
+// Оповещения одного уровня и категории внутри окна дедупликации не отправляются повторно:
+// по истечении окна уходит одна сводка с количеством повторов и последним текстом
+type Service struct {
+	sender          Sender
+	logger          *slog.Logger
+	recipients      []int64
+	chatID          int64
+	minSeverity     Severity
+	directSeverity  Severity
+	dedupWindow     time.Duration
+	now             func() time.Time
+	mu              sync.Mutex
+	recentlyAlerted map[string]*suppressed
+}
+
+// NewService конструктор. Если получатели не заданы в конфиге, оповещения получают fallbackRecipients
+func NewService(cfg config.AlertsConfig, fallbackRecipients []int64, sender Sender, logger *slog.Logger) *Service {
+	recipients := cfg.Recipients
+	if len(recipients) == 0 {
+		recipients = fallbackRecipients
+	}
+
+	return &Service{
+		sender:          sender,
+		logger:          logger,
+		recipients:      recipients,
+		chatID:          cfg.ChatID,
+		minSeverity:     ParseSeverity(cfg.MinSeverity),
+		directSeverity:  ParseSeverity(cfg.DirectSeverity),
+		dedupWindow:     cfg.DedupWindow,
+		now:             time.Now,
+		recentlyAlerted: make(map[string]*suppressed),
+	}
+}
+
+// Warning оповещение уровня warning
+func (s *Service) Warning(category, message string) {
+	s.Notify(SeverityWarning, category, message)
+}
+
+// Error оповещение уровня error
+func (s *Service) Error(category, message string) {
+	s.Notify(SeverityError, category, message)
+}
+
+// Critical оповещение уровня critical
+func (s *Service) Critical(category, message string) {
+	s.Notify(SeverityCritical, category, message)
+}
+
+// Notify логирует оповещение и рассылает его: в чат оповещений начиная с min_severity,
+// получателям в личку начиная с direct_severity. category - постоянный ключ вида «forward»:
+// текст с номером обращения и ошибкой меняется, а повторы все равно считаются одним оповещением
+func (s *Service) Notify(severity Severity, category, message string) {
+	s.log(severity, message)
+
+	if severity < s.minSeverity {
+		return
+	}
+	if !s.dedup(severity, category, message) {
+		return
+	}
+
+	s.dispatch(severity, fmt.Sprintf("%s\n\n%s", severity, message))
+}
+
+// dispatch отправка текста в чат оповещений и получателям по уровню
+func (s *Service) dispatch(severity Severity, text string) {
+	if s.chatID != 0 {
+		s.send(s.chatID, text)
+	}
+	if severity < s.directSeverity && s.chatID != 0 {
+		return
+	}
+	for _, recipient := range s.recipients {
+		s.send(recipient, text)
+	}
+}
+
+// dedup ok = false если оповещение этого уровня и категории уже отправлялось внутри окна.
+// Первый подавленный повтор ставит таймер, который по истечении окна отправит сводку
+func (s *Service) dedup(severity Severity, category, message string) bool {
+	if s.dedupWindow <= 0 {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for key, entry := range s.recentlyAlerted {
+		if now.Sub(entry.sentAt) >= s.dedupWindow && entry.count == 0 {
+			delete(s.recentlyAlerted, key)
+		}
+	}
+
+	key := fmt.Sprintf("%d:%s", severity, category)
+	entry, found := s.recentlyAlerted[key]
+	if !found {
+		s.recentlyAlerted[key] = &suppressed{sentAt: now}
+		return true
+	}
+
+	entry.count++
+	entry.last = message
+	if entry.flusher == nil {
+		entry.flusher = time.AfterFunc(s.dedupWindow-now.Sub(entry.sentAt), func() {
+			s.flush(severity, key)
+		})
+	}
+
+	return false
+}
+
+// flush сводка подавленных повторов по истечении окна. Запись удаляется,
+// следующее оповещение категории снова отправляется сразу
+func (s *Service) flush(severity Severity, key string) {
+	s.mu.Lock()
+	entry, found := s.recentlyAlerted[key]
+	delete(s.recentlyAlerted, key)
+	s.mu.Unlock()
+
+	if !found || entry.count == 0 {
+		return
+	}
+
+	s.dispatch(severity, fmt.Sprintf("%s\n\nЕще %d похожих оповещений за %s, последнее:\n%s",
+		severity, entry.count, s.dedupWindow, entry.last))
+}
+
+// send ошибки отправки только логируются, иначе падение telegram порождало бы новые оповещения
+func (s *Service) send(chatID int64, text string) {
+	_, err := s.sender.SendMessageToSuperAdmin(chatID, text)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("Ошибка отправки оповещения в чат %d: %s", chatID, err))
+	}
+}
+
+func (s *Service) log(severity Severity, message string) {
+	switch {
+	case severity >= SeverityError:
+		s.logger.Error(message)
+	case severity == SeverityWarning:
+		s.logger.Warn(message)
+	default:
+		s.logger.Info(message)
+	}
+}
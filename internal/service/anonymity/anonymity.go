@@ -0,0 +1,172 @@
+package anonymity
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"medrussia_news_bot/internal/config"
+	"medrussia_news_bot/internal/infrastructure/repo"
+	"strconv"
+	"strings"
+)
+
+// pseudonymPrefix начало псевдонима в чате админов
+const pseudonymPrefix = "Источник #"
+
+const (
+	// pseudonymLen сколько hex-символов HMAC входит в псевдоним: 12 символов - 48 бит,
+	// при 24 битах совпадения становились вероятными уже на нескольких тысячах источников
+	pseudonymLen = 12
+	// legacyPseudonymLen длина псевдонимов на старых карточках, по ней еще можно найти источник
+	legacyPseudonymLen = 6
+	// revealCandidates сколько источников ищется по псевдониму: больше одного - неоднозначно
+	revealCandidates = 2
+)
+
+var (
+	// ErrNoSecret режим включен без ключа
+	ErrNoSecret = errors.New("anonymity secret is not set")
+	// ErrAmbiguousPseudonym под короткий псевдоним подходит несколько источников
+	ErrAmbiguousPseudonym = errors.New("ambiguous pseudonym")
+)
+
+// Identity настоящие данные пользователя для сохранения
+type Identity struct {
+	UserID    int64
+	UserName  string
+	FirstName string
+	LastName  string
+}
+
+// Repo хранилище настоящих данных источников
+type Repo interface {
+	SaveSourceIdentity(ctx context.Context, identity repo.SourceIdentity) error
+	GetSourceIdentity(ctx context.Context, userID int64) (*repo.SourceIdentity, error)
+	FindSourceIdentities(ctx context.Context, prefix string, limit int) ([]repo.SourceIdentity, error)
+	ListLegacyPseudonymUsers(ctx context.Context, length int) ([]int64, error)
+	SetSourcePseudonym(ctx context.Context, userID int64, pseudonym string) error
+}
+
+// Service псевдонимы источников. Псевдоним - HMAC-SHA256 telegram ID с ключом из конфига,
+// поэтому он не меняется между обращениями, но не позволяет восстановить ID без ключа
+type Service struct {
+	enabled bool
+	secret  []byte
+	repo    Repo
+	logger  *slog.Logger
+}
+
+// NewService конструктор, включенный режим требует ключ
+func NewService(cfg config.AnonymityConfig, repo Repo, logger *slog.Logger) (*Service, error) {
+	if cfg.Enabled && cfg.Secret == "" {
+		return nil, ErrNoSecret
+	}
+
+	return &Service{
+		enabled: cfg.Enabled,
+		secret:  []byte(cfg.Secret),
+		repo:    repo,
+		logger:  logger,
+	}, nil
+}
+
+// Enabled включен ли режим защиты источников
+func (s *Service) Enabled() bool {
+	return s.enabled
+}
+
+// Pseudonym псевдоним пользователя, например «Источник #A7F3C1B20D94»
+func (s *Service) Pseudonym(userID int64) string {
+	return pseudonymPrefix + s.code(userID)
+}
+
+func (s *Service) code(userID int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(strconv.FormatInt(userID, 10)))
+
+	return strings.ToUpper(hex.EncodeToString(mac.Sum(nil))[:pseudonymLen])
+}
+
+// Remember сохраняет настоящие данные пользователя отдельно от переписки.
+// Без включенного режима ничего не сохраняется
+func (s *Service) Remember(ctx context.Context, identity Identity) {
+	if !s.enabled {
+		return
+	}
+
+	err := s.repo.SaveSourceIdentity(ctx, repo.SourceIdentity{
+		UserID:    identity.UserID,
+		Pseudonym: s.code(identity.UserID),
+		UserName:  nullString(identity.UserName),
+		FirstName: nullString(identity.FirstName),
+		LastName:  nullString(identity.LastName),
+	})
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("%s", err))
+	}
+}
+
+// Reveal настоящие данные по псевдониму: принимается как «Источник #A7F3C1B20D94», так и «A7F3C1B20D94».
+// Короткий псевдоним со старых карточек подходит, только если он указывает на одного источника
+func (s *Service) Reveal(ctx context.Context, pseudonym string) (*repo.SourceIdentity, error) {
+	code := strings.TrimSpace(pseudonym)
+	code = strings.TrimPrefix(code, pseudonymPrefix)
+	code = strings.ToUpper(strings.TrimPrefix(code, "#"))
+	if len(code) < legacyPseudonymLen || len(code) > pseudonymLen || !isHex(code) {
+		return nil, repo.ErrSourceIdentityNotFound
+	}
+
+	identities, err := s.repo.FindSourceIdentities(ctx, code, revealCandidates)
+	if err != nil {
+		return nil, err
+	}
+	switch len(identities) {
+	case 0:
+		return nil, repo.ErrSourceIdentityNotFound
+	case 1:
+		return &identities[0], nil
+	default:
+		return nil, ErrAmbiguousPseudonym
+	}
+}
+
+// UpgradePseudonyms удлиняет псевдонимы, сохраненные до перехода на pseudonymLen символов.
+// Старый псевдоним - начало нового, поэтому старые карточки по-прежнему находятся через Reveal
+func (s *Service) UpgradePseudonyms(ctx context.Context) error {
+	if !s.enabled {
+		return nil
+	}
+
+	userIDs, err := s.repo.ListLegacyPseudonymUsers(ctx, pseudonymLen)
+	if err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		if err = s.repo.SetSourcePseudonym(ctx, userID, s.code(userID)); err != nil {
+			return err
+		}
+	}
+	if len(userIDs) > 0 {
+		s.logger.Info(fmt.Sprintf("Обновлены псевдонимы %d источников", len(userIDs)))
+	}
+
+	return nil
+}
+
+// RevealUser настоящие данные по telegram ID
+func (s *Service) RevealUser(ctx context.Context, userID int64) (*repo.SourceIdentity, error) {
+	return s.repo.GetSourceIdentity(ctx, userID)
+}
+
+func isHex(code string) bool {
+	return strings.Trim(code, "0123456789ABCDEF") == ""
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"medrussia_news_bot/internal/infrastructure/repo"
+)
+
+// Действия редакторов, которые пишутся в журнал аудита
+const (
+	ActionReply     = "reply"
+	ActionEditReply = "edit_reply"
+	ActionRecall    = "recall"
+	ActionClose     = "close"
+	ActionReopen    = "reopen"
+	ActionTake      = "take"
+	ActionAssign    = "assign"
+	ActionUrgent    = "urgent"
+	ActionNotUrgent = "not_urgent"
+	ActionSpam      = "spam"
+	ActionBan       = "ban"
+	ActionUnban     = "unban"
+	ActionExport    = "export"
+	ActionRole      = "role"
+	ActionRelease   = "release"
+	ActionDiscard   = "discard"
+	ActionCategory  = "category"
+	ActionReveal    = "reveal"
+)
+
+// Repo хранилище журнала аудита
+type Repo interface {
+	AddAuditRecord(ctx context.Context, record repo.AuditRecord) error
+	ListAuditRecords(ctx context.Context, appealID int64) ([]repo.AuditRecord, error)
+}
+
+// Payload дополнительные данные действия
+type Payload map[string]any
+
+// Service журнал действий редакторов
+type Service struct {
+	repo   Repo
+	logger *slog.Logger
+}
+
+// NewService конструктор
+func NewService(repo Repo, logger *slog.Logger) *Service {
+	return &Service{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Record записывает действие редактора actorID, appealID = 0 если действие не относится к обращению.
+// Ошибки записи только логируются, чтобы аудит не ломал обработку действий
+func (s *Service) Record(ctx context.Context, actorID int64, action string, appealID int64, payload Payload) {
+	record := repo.AuditRecord{
+		ActorID:  actorID,
+		Action:   action,
+		AppealID: sql.NullInt64{Int64: appealID, Valid: appealID != 0},
+	}
+
+	if len(payload) > 0 {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			s.logger.Error(fmt.Sprintf("Ошибка сериализации данных аудита: %s", err))
+		} else {
+			record.Payload = data
+		}
+	}
+
+	if err := s.repo.AddAuditRecord(ctx, record); err != nil {
+		s.logger.Error(fmt.Sprintf("Ошибка записи в журнал аудита: %s", err))
+	}
+}
+
+// Trail журнал действий по обращению
+func (s *Service) Trail(ctx context.Context, appealID int64) ([]repo.AuditRecord, error) {
+	return s.repo.ListAuditRecords(ctx, appealID)
+}
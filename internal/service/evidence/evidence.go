@@ -0,0 +1,250 @@
+package evidence
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"medrussia_news_bot/internal/config"
+	"medrussia_news_bot/internal/infrastructure/repo"
+	"net/http"
+	"time"
+)
+
+// shortHashLen сколько символов хеша показывается на карточке
+const shortHashLen = 12
+
+// ErrNoEvidence у обращения нет полученных файлов
+var ErrNoEvidence = errors.New("no evidence for appeal")
+
+// Downloader скачивание файлов пользователей
+type Downloader interface {
+	DownloadFile(fileID string, maxSize int64) ([]byte, error)
+}
+
+// Repo хранилище сведений о материалах
+type Repo interface {
+	AddEvidenceItem(ctx context.Context, item repo.EvidenceItem) (*repo.EvidenceItem, error)
+	ListAppealEvidence(ctx context.Context, appealID int64) ([]repo.EvidenceItem, error)
+	SetEvidenceDelivery(ctx context.Context, item repo.EvidenceItem) error
+}
+
+// File полученный от пользователя файл
+type File struct {
+	AppealID      int64
+	UserID        int64
+	UserMessageID int64
+	Kind          string
+	FileID        string
+	FileUniqueID  string
+	MimeType      string
+	// SentAt время отправки сообщения по данным Telegram
+	SentAt time.Time
+	// ReceivedAt время получения ботом. Для сообщений из карантина - время попадания в карантин,
+	// а не решения редактора. Если не задано, ставится текущее
+	ReceivedAt time.Time
+}
+
+// Delivery что получила редакция вместо оригинала
+type Delivery struct {
+	// OriginalID запись оригинала в media_originals для выдачи через /original
+	OriginalID int64
+	// Data очищенная копия, которую выдают /file и API
+	Data []byte
+	// Held документ задержан, редакция получила только заглушку
+	Held bool
+}
+
+// Manifest опись материалов обращения. Data - JSON описи, Signature - подпись Ed25519 этих байт
+type Manifest struct {
+	Data      []byte
+	Signature []byte
+	PublicKey ed25519.PublicKey
+	Items     int
+}
+
+// manifestDocument содержимое описи
+type manifestDocument struct {
+	AppealID    int64          `json:"appeal_id"`
+	GeneratedAt time.Time      `json:"generated_at"`
+	Algorithm   string         `json:"hash_algorithm"`
+	Items       []manifestItem `json:"items"`
+}
+
+// manifestItem файл в описи. Size и SHA256 относятся к файлу в том виде, в каком его прислал
+// источник. Если редакции выдавалась копия без метаданных, ее размер и хеш в CleanedSize
+// и CleanedSHA256, а оригинал доступен суперадмину по OriginalID
+type manifestItem struct {
+	N             int       `json:"n"`
+	Kind          string    `json:"kind"`
+	FileUniqueID  string    `json:"file_unique_id"`
+	MimeType      string    `json:"mime_type,omitempty"`
+	Size          int64     `json:"size,omitempty"`
+	SHA256        string    `json:"sha256,omitempty"`
+	CleanedSize   int64     `json:"cleaned_size,omitempty"`
+	CleanedSHA256 string    `json:"cleaned_sha256,omitempty"`
+	Held          bool      `json:"held,omitempty"`
+	OriginalID    int64     `json:"original_id,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	SentAt        time.Time `json:"sent_at"`
+	ReceivedAt    time.Time `json:"received_at"`
+}
+
+// Service учет полученных материалов: хеш и время получения каждого файла и подписанная опись
+type Service struct {
+	cfg        config.EvidenceConfig
+	downloader Downloader
+	repo       Repo
+	key        ed25519.PrivateKey
+	logger     *slog.Logger
+}
+
+// NewService конструктор, ключ подписи - base64 seed Ed25519
+func NewService(cfg config.EvidenceConfig, downloader Downloader, repo Repo, logger *slog.Logger) (*Service, error) {
+	s := &Service{
+		cfg:        cfg,
+		downloader: downloader,
+		repo:       repo,
+		logger:     logger,
+	}
+	if cfg.SigningKey == "" {
+		return s, nil
+	}
+
+	seed, err := base64.StdEncoding.DecodeString(cfg.SigningKey)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("evidence signing key must be base64 of %d bytes", ed25519.SeedSize)
+	}
+	s.key = ed25519.NewKeyFromSeed(seed)
+
+	return s, nil
+}
+
+// Enabled включен ли учет материалов
+func (s *Service) Enabled() bool {
+	return s.cfg.Enabled
+}
+
+// Record скачивает файл, сохраняет его хеш и возвращает запись вместе с содержимым,
+// чтобы следующие шаги не скачивали файл повторно. Если файл скачать не удалось,
+// запись сохраняется с причиной, а содержимое пустое
+func (s *Service) Record(ctx context.Context, file File) (*repo.EvidenceItem, []byte) {
+	item := repo.EvidenceItem{
+		AppealID:      file.AppealID,
+		UserID:        file.UserID,
+		UserMessageID: file.UserMessageID,
+		Kind:          file.Kind,
+		FileUniqueID:  file.FileUniqueID,
+		MimeType:      nullString(file.MimeType),
+		SentAt:        file.SentAt,
+		ReceivedAt:    file.ReceivedAt,
+	}
+
+	data, err := s.downloader.DownloadFile(file.FileID, s.cfg.MaxFileSize)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("Не удалось скачать файл обращения #%d: %s", file.AppealID, err))
+		item.Error = nullString(err.Error())
+	} else {
+		sum := sha256.Sum256(data)
+		item.SHA256 = nullString(hex.EncodeToString(sum[:]))
+		item.Size = sql.NullInt64{Int64: int64(len(data)), Valid: true}
+		if !item.MimeType.Valid {
+			item.MimeType = nullString(http.DetectContentType(data))
+		}
+	}
+
+	saved, err := s.repo.AddEvidenceItem(ctx, item)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("%s", err))
+		return nil, data
+	}
+
+	return saved, data
+}
+
+// RecordDelivery дописывает к записи, что получила редакция: хеш очищенной копии или отметку
+// о задержанном документе, чтобы опись сходилась с файлами, которые выдают /file и API
+func (s *Service) RecordDelivery(ctx context.Context, item *repo.EvidenceItem, delivery Delivery) {
+	if item == nil {
+		return
+	}
+
+	item.Held = delivery.Held
+	item.OriginalID = sql.NullInt64{Int64: delivery.OriginalID, Valid: delivery.OriginalID != 0}
+	if delivery.Data != nil {
+		sum := sha256.Sum256(delivery.Data)
+		item.CleanedSHA256 = nullString(hex.EncodeToString(sum[:]))
+		item.CleanedSize = sql.NullInt64{Int64: int64(len(delivery.Data)), Valid: true}
+	}
+
+	if err := s.repo.SetEvidenceDelivery(ctx, *item); err != nil {
+		s.logger.Error(fmt.Sprintf("%s", err))
+	}
+}
+
+// Manifest подписанная опись всех материалов обращения
+func (s *Service) Manifest(ctx context.Context, appealID int64) (*Manifest, error) {
+	items, err := s.repo.ListAppealEvidence(ctx, appealID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, ErrNoEvidence
+	}
+
+	document := manifestDocument{
+		AppealID:    appealID,
+		GeneratedAt: time.Now().UTC(),
+		Algorithm:   "SHA-256",
+		Items:       make([]manifestItem, 0, len(items)),
+	}
+	for i, item := range items {
+		document.Items = append(document.Items, manifestItem{
+			N:             i + 1,
+			Kind:          item.Kind,
+			FileUniqueID:  item.FileUniqueID,
+			MimeType:      item.MimeType.String,
+			Size:          item.Size.Int64,
+			SHA256:        item.SHA256.String,
+			CleanedSize:   item.CleanedSize.Int64,
+			CleanedSHA256: item.CleanedSHA256.String,
+			Held:          item.Held,
+			OriginalID:    item.OriginalID.Int64,
+			Error:         item.Error.String,
+			SentAt:        item.SentAt.UTC(),
+			ReceivedAt:    item.ReceivedAt.UTC(),
+		})
+	}
+
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	manifest := &Manifest{Data: data, Items: len(items)}
+	if s.key != nil {
+		manifest.Signature = ed25519.Sign(s.key, data)
+		manifest.PublicKey = s.key.Public().(ed25519.PublicKey)
+	}
+
+	return manifest, nil
+}
+
+// ShortHash начало хеша для карточки
+func ShortHash(item *repo.EvidenceItem) string {
+	if item == nil || !item.SHA256.Valid {
+		return ""
+	}
+
+	return item.SHA256.String[:shortHashLen]
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
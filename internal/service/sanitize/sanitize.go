@@ -0,0 +1,198 @@
+package sanitize
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"medrussia_news_bot/internal/config"
+	"medrussia_news_bot/internal/infrastructure/repo"
+	"medrussia_news_bot/internal/pkg/filestore"
+	"medrussia_news_bot/internal/pkg/metadata"
+	"path"
+	"strings"
+)
+
+var (
+	// ErrNothingRemoved метаданных в файле не нашлось, файл можно переслать как есть
+	ErrNothingRemoved = errors.New("no metadata found")
+	// ErrMetadataKept часть метаданных удалить не удалось
+	ErrMetadataKept = errors.New("metadata could not be removed")
+	// ErrNotChecked документ не проверялся: формат не поддерживается или файл не удалось скачать.
+	// Без Strict такой документ пересылается как есть с пометкой, что метаданные не удалены
+	ErrNotChecked = errors.New("document was not checked for metadata")
+)
+
+// Downloader скачивание файлов пользователей
+type Downloader interface {
+	DownloadFile(fileID string, maxSize int64) ([]byte, error)
+}
+
+// Repo хранилище сведений об оригиналах
+type Repo interface {
+	AddMediaOriginal(ctx context.Context, original repo.MediaOriginal) (int64, error)
+	SetMediaOriginalMessage(ctx context.Context, id, adminChatMessageID int64) error
+}
+
+// File документ пользователя
+type File struct {
+	AppealID     int64
+	UserID       int64
+	FileID       string
+	FileUniqueID string
+	FileName     string
+	// Data уже скачанное содержимое, если пустое - файл скачивается
+	Data []byte
+}
+
+// Cleaned очищенная копия документа или задержанный документ
+type Cleaned struct {
+	// OriginalID запись об оригинале в media_originals, 0 если оригинал сохранить не удалось
+	OriginalID int64
+	Format     metadata.Format
+	// Data очищенная копия, nil для задержанного документа
+	Data    []byte
+	Removed []string
+	Kept    []string
+	// Held почему документ задержан: формат поддерживается, но очистить файл не удалось,
+	// а в режиме Strict - и непроверенный документ. Задержанный документ редакции
+	// не передается, оригинал доступен суперадминам
+	Held error
+}
+
+// Service очистка документов от метаданных. Очищенная копия уходит редакции,
+// оригинал сохраняется в хранилище файлов под префиксом originals/, который не выдается редакторам.
+// Документ поддерживаемого формата, который очистить не удалось, редакции не пересылается
+type Service struct {
+	cfg        config.SanitizeConfig
+	downloader Downloader
+	repo       Repo
+	store      filestore.FileStore
+	logger     *slog.Logger
+}
+
+// NewService конструктор
+func NewService(cfg config.SanitizeConfig, downloader Downloader, repo Repo, store filestore.FileStore, logger *slog.Logger) *Service {
+	return &Service{
+		cfg:        cfg,
+		downloader: downloader,
+		repo:       repo,
+		store:      store,
+		logger:     logger,
+	}
+}
+
+// Enabled включена ли очистка
+func (s *Service) Enabled() bool {
+	return s.cfg.Enabled
+}
+
+// Clean скачивает документ и удаляет из него метаданные. Если удалять нечего - ErrNothingRemoved,
+// и документ можно переслать как есть. Если формат не поддерживается или файл не удалось скачать -
+// ErrNotChecked с причиной, документ тоже пересылается как есть. Документ поддерживаемого формата,
+// который не удалось разобрать или очистить полностью, задерживается: возвращается Cleaned с Held.
+// Ошибка вместе с результатом означает, что не удалось сохранить оригинал
+func (s *Service) Clean(ctx context.Context, file File) (*Cleaned, error) {
+	data := file.Data
+	if data == nil {
+		var err error
+		data, err = s.downloader.DownloadFile(file.FileID, s.cfg.MaxFileSize)
+		if err != nil {
+			return s.notChecked(ctx, file, nil, metadata.FormatUnknown, err)
+		}
+	}
+
+	result, err := metadata.Strip(data)
+	if errors.Is(err, metadata.ErrUnsupportedFormat) {
+		return s.notChecked(ctx, file, data, metadata.Detect(data), err)
+	}
+	if err != nil {
+		return s.hold(ctx, file, data, metadata.Detect(data), err)
+	}
+	if len(result.Kept) > 0 {
+		held, err := s.hold(ctx, file, data, result.Format, fmt.Errorf("%w: %s", ErrMetadataKept, strings.Join(result.Kept, ", ")))
+		held.Removed, held.Kept = result.Removed, result.Kept
+		return held, err
+	}
+	if len(result.Removed) == 0 {
+		return nil, ErrNothingRemoved
+	}
+
+	cleaned := &Cleaned{
+		Format:  result.Format,
+		Data:    result.Data,
+		Removed: result.Removed,
+	}
+	id, err := s.saveOriginal(ctx, file, data, repo.MediaOriginal{
+		Format:  string(result.Format),
+		Removed: strings.Join(result.Removed, ", "),
+	})
+	cleaned.OriginalID = id
+
+	return cleaned, err
+}
+
+// notChecked документ, который не удалось проверить: пересылается как есть, а в режиме Strict задерживается
+func (s *Service) notChecked(ctx context.Context, file File, data []byte, format metadata.Format, reason error) (*Cleaned, error) {
+	if s.cfg.Strict {
+		return s.hold(ctx, file, data, format, reason)
+	}
+
+	return nil, fmt.Errorf("%w: %w", ErrNotChecked, reason)
+}
+
+// hold задерживает документ: оригинал сохраняется под originals/, если его удалось скачать
+func (s *Service) hold(ctx context.Context, file File, data []byte, format metadata.Format, reason error) (*Cleaned, error) {
+	held := &Cleaned{Format: format, Held: reason}
+
+	id, err := s.saveOriginal(ctx, file, data, repo.MediaOriginal{
+		Format:     string(format),
+		FileID:     sql.NullString{String: file.FileID, Valid: file.FileID != ""},
+		HeldReason: sql.NullString{String: reason.Error(), Valid: true},
+	})
+	held.OriginalID = id
+
+	return held, err
+}
+
+// saveOriginal сохраняет оригинал в хранилище, если он скачан, и запись о нем в media_originals
+func (s *Service) saveOriginal(ctx context.Context, file File, data []byte, original repo.MediaOriginal) (int64, error) {
+	if data != nil {
+		key, err := s.storeOriginal(ctx, file, data)
+		if err != nil {
+			return 0, err
+		}
+		original.StoragePath, original.Size = key, int64(len(data))
+	}
+
+	original.AppealID = file.AppealID
+	original.UserID = file.UserID
+	original.FileUniqueID = file.FileUniqueID
+	original.FileName = sql.NullString{String: file.FileName, Valid: file.FileName != ""}
+
+	return s.repo.AddMediaOriginal(ctx, original)
+}
+
+// LinkMessage связывает оригинал с карточкой очищенной копии в чате админов
+func (s *Service) LinkMessage(ctx context.Context, cleaned *Cleaned, adminChatMessageID int64) {
+	if cleaned == nil || cleaned.OriginalID == 0 {
+		return
+	}
+
+	err := s.repo.SetMediaOriginalMessage(ctx, cleaned.OriginalID, adminChatMessageID)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("%s", err))
+	}
+}
+
+// storeOriginal сохраняет оригинал и возвращает его ключ в хранилище.
+// Имя файла от пользователя в ключе не используется
+func (s *Service) storeOriginal(ctx context.Context, file File, data []byte) (string, error) {
+	key := fmt.Sprintf("originals/%d/%s", file.AppealID, path.Base(file.FileUniqueID))
+	if err := s.store.Put(ctx, key, data, ""); err != nil {
+		return "", fmt.Errorf("failed to store original: %w", err)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,179 @@
+package sanitize
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"log/slog"
+	"medrussia_news_bot/internal/config"
+	"medrussia_news_bot/internal/infrastructure/repo"
+	"medrussia_news_bot/internal/pkg/filestore"
+	"medrussia_news_bot/internal/pkg/metadata"
+	"slices"
+	"testing"
+)
+
+type fakeDownloader struct {
+	data []byte
+	err  error
+}
+
+func (d fakeDownloader) DownloadFile(string, int64) ([]byte, error) {
+	return d.data, d.err
+}
+
+type fakeRepo struct {
+	originals []repo.MediaOriginal
+}
+
+func (r *fakeRepo) AddMediaOriginal(_ context.Context, original repo.MediaOriginal) (int64, error) {
+	r.originals = append(r.originals, original)
+	return int64(len(r.originals)), nil
+}
+
+func (r *fakeRepo) SetMediaOriginalMessage(context.Context, int64, int64) error {
+	return nil
+}
+
+// jpegWithExif минимальный JPEG с сегментом EXIF
+func jpegWithExif() []byte {
+	exif := []byte("Exif\x00\x00GPS")
+	segment := binary.BigEndian.AppendUint16([]byte{0xFF, 0xE1}, uint16(len(exif)+2))
+	return slices.Concat([]byte{0xFF, 0xD8}, segment, exif, []byte{0xFF, 0xD9})
+}
+
+func TestClean(t *testing.T) {
+	errTooLarge := errors.New("file is too large")
+
+	tests := []struct {
+		name       string
+		file       File
+		downloader fakeDownloader
+		strict     bool
+		wantErr    error
+		wantHeld   error
+		wantData   bool
+		// wantStored оригинал сохранен в хранилище
+		wantStored bool
+		// wantRecord записан оригинал в media_originals
+		wantRecord bool
+	}{
+		{
+			name:       "metadata removed",
+			file:       File{Data: jpegWithExif()},
+			wantData:   true,
+			wantStored: true,
+			wantRecord: true,
+		},
+		{
+			name:    "nothing to remove",
+			file:    File{Data: []byte{0xFF, 0xD8, 0xFF, 0xD9}},
+			wantErr: ErrNothingRemoved,
+		},
+		{
+			name:    "unsupported format is not checked",
+			file:    File{Data: []byte("RIFF\x00\x00\x00\x00WEBPVP8 ")},
+			wantErr: metadata.ErrUnsupportedFormat,
+		},
+		{
+			name:       "unsupported format is held in strict mode",
+			file:       File{Data: []byte("RIFF\x00\x00\x00\x00WEBPVP8 ")},
+			strict:     true,
+			wantHeld:   metadata.ErrUnsupportedFormat,
+			wantStored: true,
+			wantRecord: true,
+		},
+		{
+			name:       "corrupted file is held",
+			file:       File{Data: []byte{0xFF, 0xD8, 0xFF, 0xE1, 0xFF, 0xFF}},
+			wantHeld:   metadata.ErrCorrupted,
+			wantStored: true,
+			wantRecord: true,
+		},
+		{
+			name: "compressed metadata is held",
+			file: File{Data: []byte("%PDF-1.5\n3 0 obj\n<< /Type /Metadata /Subtype /XML /Filter /FlateDecode >>\n" +
+				"stream\nxx\nendstream\nendobj\n")},
+			wantHeld:   ErrMetadataKept,
+			wantStored: true,
+			wantRecord: true,
+		},
+		{
+			name:       "download failure is not checked",
+			file:       File{FileID: "file-id"},
+			downloader: fakeDownloader{err: errTooLarge},
+			wantErr:    errTooLarge,
+		},
+		{
+			name:       "download failure is held without original in strict mode",
+			file:       File{FileID: "file-id"},
+			downloader: fakeDownloader{err: errTooLarge},
+			strict:     true,
+			wantHeld:   errTooLarge,
+			wantRecord: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := filestore.NewLocal(t.TempDir())
+			originals := &fakeRepo{}
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			service := NewService(config.SanitizeConfig{Enabled: true, MaxFileSize: 1 << 20, Strict: tt.strict}, tt.downloader, originals, store, logger)
+
+			tt.file.AppealID, tt.file.FileUniqueID = 7, "unique"
+			cleaned, err := service.Clean(context.Background(), tt.file)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("Clean() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if errors.Is(tt.wantErr, ErrNothingRemoved) == errors.Is(err, ErrNotChecked) {
+					t.Errorf("Clean() error = %v, want ErrNotChecked: %v", err, !errors.Is(tt.wantErr, ErrNothingRemoved))
+				}
+				if len(originals.originals) != 0 {
+					t.Error("document forwarded as is must not have an original record")
+				}
+				return
+			}
+
+			if !errors.Is(cleaned.Held, tt.wantHeld) || (tt.wantHeld == nil && cleaned.Held != nil) {
+				t.Errorf("Held = %v, want %v", cleaned.Held, tt.wantHeld)
+			}
+			if (cleaned.Data != nil) != tt.wantData {
+				t.Errorf("Data = %v, want data: %v", cleaned.Data != nil, tt.wantData)
+			}
+			if cleaned.Held != nil && cleaned.Data != nil {
+				t.Error("held document must not have a copy for editors")
+			}
+
+			if len(originals.originals) != 1 {
+				if tt.wantRecord {
+					t.Fatalf("media_originals records = %d, want 1", len(originals.originals))
+				}
+				return
+			}
+			original := originals.originals[0]
+			if original.HeldReason.Valid != (tt.wantHeld != nil) {
+				t.Errorf("HeldReason = %q, want held: %v", original.HeldReason.String, tt.wantHeld != nil)
+			}
+			if tt.wantHeld != nil && original.FileID.String != tt.file.FileID {
+				t.Errorf("FileID = %q, want %q", original.FileID.String, tt.file.FileID)
+			}
+
+			if !tt.wantStored {
+				if original.StoragePath != "" {
+					t.Errorf("StoragePath = %q, want empty", original.StoragePath)
+				}
+				return
+			}
+			data, err := store.Get(context.Background(), original.StoragePath)
+			if err != nil {
+				t.Fatalf("original not stored: %v", err)
+			}
+			if !slices.Equal(data, tt.file.Data) {
+				t.Error("stored original differs from the received file")
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package spam
+
+import (
+	"fmt"
+	"medrussia_news_bot/internal/config"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// defaultPhrases типичные фразы рекламы и мошенничества.
+// Слова о заработке и доходе сюда не входят: они часто встречаются в жалобах на зарплату
+var defaultPhrases = []string{
+	"криптовалют",
+	"инвестиц",
+	"usdt",
+	"binance",
+	"бинанс",
+	"казино",
+	"ставки на спорт",
+	"пиши в лс",
+	"пишите в лс",
+	"подписывайтесь на канал",
+	"переходи по ссылке",
+	"без вложений",
+	"раскрутка",
+	"накрутка",
+}
+
+// linkPattern ссылки в тексте, которые telegram не разметил сущностями
+var linkPattern = regexp.MustCompile(`(?i)(https?://|www\.|t\.me/|telegram\.me/)\S+`)
+
+// DefaultRules встроенные правила фильтра
+func DefaultRules(cfg config.SpamConfig) []Rule {
+	return []Rule{
+		LinkDensityRule{MaxLinks: cfg.MaxLinks},
+		PhraseRule{Phrases: slices.Concat(defaultPhrases, cfg.Phrases)},
+		ForwardedAdRule{},
+		NewUserBurstRule{Window: cfg.NewUserWindow, Burst: cfg.NewUserBurst},
+	}
+}
+
+// LinkDensityRule много ссылок или сообщение почти из одних ссылок
+type LinkDensityRule struct {
+	MaxLinks int
+}
+
+// Check проверка сообщения
+func (r LinkDensityRule) Check(message Message) (float64, string) {
+	links := max(message.Links, len(linkPattern.FindAllString(message.Text, -1)))
+	if links == 0 {
+		return 0, ""
+	}
+
+	words := len(strings.Fields(message.Text))
+	switch {
+	case r.MaxLinks > 0 && links > r.MaxLinks:
+		return 1, fmt.Sprintf("ссылок: %d", links)
+	case words > 0 && float64(links)/float64(words) > 0.3:
+		return 0.6, "сообщение почти из одних ссылок"
+	}
+
+	return 0.2, "есть ссылки"
+}
+
+// PhraseRule известные фразы рекламы и мошенничества
+type PhraseRule struct {
+	Phrases []string
+}
+
+// Check проверка сообщения
+func (r PhraseRule) Check(message Message) (float64, string) {
+	text := strings.ToLower(message.Text)
+
+	found := make([]string, 0)
+	for _, phrase := range r.Phrases {
+		phrase = strings.ToLower(strings.TrimSpace(phrase))
+		if phrase != "" && strings.Contains(text, phrase) {
+			found = append(found, phrase)
+		}
+	}
+	if len(found) == 0 {
+		return 0, ""
+	}
+
+	return 0.5 * float64(len(found)), "фразы: " + strings.Join(found, ", ")
+}
+
+// ForwardedAdRule пересланный из канала пост со ссылками - типичная реклама
+type ForwardedAdRule struct{}
+
+// Check проверка сообщения
+func (ForwardedAdRule) Check(message Message) (float64, string) {
+	if !message.ForwardedFromChannel {
+		return 0, ""
+	}
+	if message.Links > 0 || linkPattern.MatchString(message.Text) {
+		return 0.8, "пересланный пост канала со ссылками"
+	}
+
+	return 0.2, "пересланный пост канала"
+}
+
+// NewUserBurstRule новый пользователь сразу присылает много сообщений
+type NewUserBurstRule struct {
+	Window time.Duration
+	Burst  int
+}
+
+// Check проверка сообщения
+func (r NewUserBurstRule) Check(message Message) (float64, string) {
+	if r.Burst <= 0 {
+		return 0, ""
+	}
+
+	activity := message.Activity
+	isNew := !activity.FirstMessageAt.Valid || message.ReceivedAt.Sub(activity.FirstMessageAt.Time) < r.Window
+	if !isNew || activity.RecentMessages+1 < r.Burst {
+		return 0, ""
+	}
+
+	return 0.6, fmt.Sprintf("новый пользователь, %d сообщений за %s", activity.RecentMessages+1, r.Window)
+}
@@ -0,0 +1,124 @@
+package spam
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"medrussia_news_bot/internal/config"
+	"medrussia_news_bot/internal/infrastructure/repo"
+	"strings"
+	"time"
+)
+
+// Message сообщение пользователя и его активность, по которым работают правила
+type Message struct {
+	UserID int64
+	// Text текст или подпись к вложению
+	Text string
+	// Links количество ссылок в сущностях сообщения
+	Links int
+	// ForwardedFromChannel сообщение переслано из канала
+	ForwardedFromChannel bool
+	Activity             repo.UserActivity
+	ReceivedAt           time.Time
+}
+
+// Verdict решение классификатора
+type Verdict struct {
+	Spam    bool
+	Score   float64
+	Reasons []string
+}
+
+// Reason причины решения одной строкой
+func (v Verdict) Reason() string {
+	return strings.Join(v.Reasons, "; ")
+}
+
+// Classifier этап проверки сообщения перед пересылкой редакции
+type Classifier interface {
+	Classify(ctx context.Context, message Message) Verdict
+}
+
+// Rule правило фильтра: score > 0 если сообщение похоже на спам, reason - объяснение для редакторов
+type Rule interface {
+	Check(message Message) (score float64, reason string)
+}
+
+// RuleClassifier классификатор, суммирующий очки правил. Сообщение считается спамом,
+// если сумма очков достигла порога
+type RuleClassifier struct {
+	threshold float64
+	rules     []Rule
+}
+
+// NewRuleClassifier конструктор
+func NewRuleClassifier(threshold float64, rules ...Rule) *RuleClassifier {
+	return &RuleClassifier{
+		threshold: threshold,
+		rules:     rules,
+	}
+}
+
+// Classify проверка сообщения всеми правилами
+func (c *RuleClassifier) Classify(_ context.Context, message Message) Verdict {
+	var verdict Verdict
+	for _, rule := range c.rules {
+		score, reason := rule.Check(message)
+		if score <= 0 {
+			continue
+		}
+		verdict.Score += score
+		verdict.Reasons = append(verdict.Reasons, reason)
+	}
+	verdict.Spam = verdict.Score >= c.threshold
+
+	return verdict
+}
+
+// Repo источник активности пользователя
+type Repo interface {
+	GetUserActivity(ctx context.Context, userID int64, since time.Time) (repo.UserActivity, error)
+}
+
+// Filter фильтр спама: дополняет сообщение активностью пользователя и передает классификатору
+type Filter struct {
+	cfg        config.SpamConfig
+	classifier Classifier
+	repo       Repo
+	logger     *slog.Logger
+}
+
+// NewFilter конструктор, если classifier = nil используются встроенные правила
+func NewFilter(cfg config.SpamConfig, classifier Classifier, repo Repo, logger *slog.Logger) *Filter {
+	if classifier == nil {
+		classifier = NewRuleClassifier(cfg.Threshold, DefaultRules(cfg)...)
+	}
+
+	return &Filter{
+		cfg:        cfg,
+		classifier: classifier,
+		repo:       repo,
+		logger:     logger,
+	}
+}
+
+// Check проверка сообщения, при выключенном фильтре сообщение всегда не спам
+func (f *Filter) Check(ctx context.Context, message Message) Verdict {
+	if !f.cfg.Enabled {
+		return Verdict{}
+	}
+
+	if message.ReceivedAt.IsZero() {
+		message.ReceivedAt = time.Now()
+	}
+
+	activity, err := f.repo.GetUserActivity(ctx, message.UserID, message.ReceivedAt.Add(-f.cfg.NewUserWindow))
+	if err != nil {
+		// без активности правила новых аккаунтов просто не сработают
+		f.logger.Error(fmt.Sprintf("%s", err))
+	}
+	message.Activity = activity
+
+	return f.classifier.Classify(ctx, message)
+}
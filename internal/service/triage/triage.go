@@ -0,0 +1,84 @@
+package triage
+
+import (
+	"fmt"
+	"medrussia_news_bot/internal/config"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// defaultKeywords основы слов, по которым обращение сразу становится срочным
+var defaultKeywords = []string{
+	"угроз",
+	"угрожа",
+	"избил",
+	"избиени",
+	"нападени",
+	"напали",
+	"уголовное дело",
+	"уголовного дела",
+	"обыск",
+	"преследован",
+}
+
+// defaultPatterns встроенные выражения для слов, которые как подстрока дают ложные срабатывания:
+// «задержан» без контекста совпадает с «задержана зарплата».
+// \b в Go работает только с ASCII, поэтому границы слов для кириллицы записаны явно
+var defaultPatterns = []string{
+	`(^|[^\p{L}])задержа(н|л)(а|ы|о|и)?\s+(врач|сотрудни|медик|фельдшер|медсестр|медбрат|главврач|заведующ)`,
+	`(врач|сотрудни|медик|фельдшер|медсестр|медбрат|главврач|заведующ)\p{L}*\s+(был[аи]?\s+)?задержан`,
+}
+
+// Matcher правила срочности: подстроки без учета регистра и регулярные выражения
+type Matcher struct {
+	keywords []string
+	patterns []*regexp.Regexp
+}
+
+// NewMatcher конструктор, ключевые слова из конфига дополняют встроенные
+func NewMatcher(cfg config.TriageConfig) (*Matcher, error) {
+	keywords := make([]string, 0, len(defaultKeywords)+len(cfg.Keywords))
+	for _, keyword := range slices.Concat(defaultKeywords, cfg.Keywords) {
+		keyword = strings.ToLower(strings.TrimSpace(keyword))
+		if keyword != "" && !slices.Contains(keywords, keyword) {
+			keywords = append(keywords, keyword)
+		}
+	}
+
+	patterns := make([]*regexp.Regexp, 0, len(defaultPatterns)+len(cfg.Patterns))
+	for _, pattern := range slices.Concat(defaultPatterns, cfg.Patterns) {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid triage pattern %q: %w", pattern, err)
+		}
+		patterns = append(patterns, re)
+	}
+
+	return &Matcher{
+		keywords: keywords,
+		patterns: patterns,
+	}, nil
+}
+
+// Match сработавшие правила для текста или подписи сообщения
+func (m *Matcher) Match(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lower := strings.ToLower(text)
+
+	var matched []string
+	for _, keyword := range m.keywords {
+		if strings.Contains(lower, keyword) {
+			matched = append(matched, keyword)
+		}
+	}
+	for _, re := range m.patterns {
+		if found := re.FindString(text); found != "" {
+			matched = append(matched, strings.TrimSpace(found))
+		}
+	}
+
+	return matched
+}
@@ -0,0 +1,58 @@
+package triage
+
+import (
+	"medrussia_news_bot/internal/config"
+	"slices"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	matcher, err := NewMatcher(config.TriageConfig{
+		Keywords: []string{" Увольнени ", "обыск"},
+		Patterns: []string{`прокуратур\p{L}*`},
+	})
+	if err != nil {
+		t.Fatalf("NewMatcher() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "empty", text: "", want: nil},
+		{name: "no rules", text: "Прошу рассказать о нехватке лекарств", want: nil},
+		{name: "keyword in any case", text: "Главврачу УГРОЖАЮТ", want: []string{"угрожа"}},
+		{name: "several keywords", text: "После обыска начались угрозы", want: []string{"угроз", "обыск"}},
+		{name: "keyword from config", text: "Массовые увольнения в больнице", want: []string{"увольнени"}},
+		{name: "pattern from config", text: "Жалоба в Прокуратуру", want: []string{"Прокуратуру"}},
+		{name: "detained doctor", text: "Вчера задержан врач скорой", want: []string{"задержан врач"}},
+		{name: "detained plural", text: "Задержали медиков на смене", want: []string{"Задержали медик"}},
+		{name: "staff noun first", text: "Наш главврач был задержан утром", want: []string{"главврач был задержан"}},
+		{name: "delayed salary", text: "Задержана зарплата за три месяца", want: nil},
+		{name: "delayed salary plural", text: "Нам задержали зарплату и премии", want: nil},
+		{name: "word ending in задержан", text: "незадержанный врач", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matcher.Match(tt.text); !slices.Equal(got, tt.want) {
+				t.Errorf("Match(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMatcher(t *testing.T) {
+	matcher, err := NewMatcher(config.TriageConfig{Keywords: []string{"УГРОЗ", " ", ""}})
+	if err != nil {
+		t.Fatalf("NewMatcher() error = %v", err)
+	}
+	if len(matcher.keywords) != len(defaultKeywords) {
+		t.Errorf("keywords = %d, want duplicates and empty keywords dropped", len(matcher.keywords))
+	}
+
+	if _, err = NewMatcher(config.TriageConfig{Patterns: []string{"(незакрытая"}}); err == nil {
+		t.Error("NewMatcher() accepted an invalid pattern")
+	}
+}
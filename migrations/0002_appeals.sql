@@ -0,0 +1,19 @@
+create table if not exists appeals
+(
+    id bigserial primary key,
+    user_id bigint not null,
+    status varchar(32) not null default 'open',
+    opened_at timestamptz not null default now(),
+    closed_at timestamptz,
+    closed_by bigint
+);
+
+create index if not exists appeals_user_id_idx on appeals (user_id);
+
+-- у пользователя может быть только одно незакрытое обращение
+create unique index if not exists appeals_user_id_open_idx on appeals (user_id) where closed_at is null;
+
+-- переносим активные диалоги в обращения
+insert into appeals (user_id)
+select user_id from users_dialog where available
+on conflict do nothing;
@@ -0,0 +1,21 @@
+create table if not exists messages
+(
+    id bigserial primary key,
+    appeal_id bigint references appeals (id),
+    user_id bigint not null,
+    -- user_to_admin или admin_to_user
+    direction varchar(16) not null,
+    author_id bigint not null,
+    user_chat_message_id bigint,
+    admin_chat_message_id bigint,
+    text text,
+    caption text,
+    media_kind varchar(32),
+    file_id text,
+    file_unique_id text,
+    media_group_id text,
+    created_at timestamptz not null default now()
+);
+
+create index if not exists messages_appeal_id_idx on messages (appeal_id);
+create index if not exists messages_user_id_created_at_idx on messages (user_id, created_at);
@@ -0,0 +1,13 @@
+-- связь сообщения в чате админов с сообщением в чате пользователя
+create table if not exists message_links
+(
+    admin_chat_message_id bigint primary key,
+    user_id bigint not null,
+    user_message_id bigint not null,
+    appeal_id bigint references appeals (id),
+    -- user_to_admin: карточка пользователя в чате админов, admin_to_user: ответ редактора и его копия у пользователя
+    direction varchar(16) not null,
+    created_at timestamptz not null default now()
+);
+
+create index if not exists message_links_user_message_idx on message_links (user_id, user_message_id);
@@ -0,0 +1,12 @@
+-- история правок сообщений, исходная версия остается в messages
+create table if not exists message_edits
+(
+    id bigserial primary key,
+    message_id bigint not null references messages (id),
+    editor_id bigint not null,
+    text text,
+    caption text,
+    edited_at timestamptz not null default now()
+);
+
+create index if not exists message_edits_message_id_idx on message_edits (message_id);
@@ -0,0 +1,2 @@
+-- ответ редактора отозван командой /recall и удален у пользователя
+alter table message_links add column if not exists recalled_at timestamptz;
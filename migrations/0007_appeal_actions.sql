@@ -0,0 +1,21 @@
+alter table appeals add column if not exists urgent bool not null default false;
+alter table appeals add column if not exists assigned_to bigint;
+
+-- история действий редакторов над обращением
+create table if not exists appeal_events
+(
+    id bigserial primary key,
+    appeal_id bigint not null references appeals (id),
+    action varchar(32) not null,
+    editor_id bigint not null,
+    created_at timestamptz not null default now()
+);
+
+create index if not exists appeal_events_appeal_id_idx on appeal_events (appeal_id);
+
+create table if not exists bans
+(
+    user_id bigint primary key,
+    banned_by bigint not null,
+    created_at timestamptz not null default now()
+);
@@ -0,0 +1,13 @@
+-- участники чата админов, обновляются при каждом их действии в чате
+create table if not exists editors
+(
+    tg_id bigint primary key,
+    username varchar(64),
+    first_name varchar(128),
+    last_name varchar(128),
+    updated_at timestamptz not null default now()
+);
+
+create index if not exists editors_username_idx on editors (lower(username));
+
+create index if not exists appeals_assigned_to_idx on appeals (assigned_to) where closed_at is null;
@@ -0,0 +1,13 @@
+-- журнал всех действий редакторов в чате админов
+create table if not exists audit_log
+(
+    id bigserial primary key,
+    actor_id bigint not null,
+    action varchar(32) not null,
+    appeal_id bigint references appeals (id),
+    payload jsonb,
+    created_at timestamptz not null default now()
+);
+
+create index if not exists audit_log_appeal_id_idx on audit_log (appeal_id);
+create index if not exists audit_log_actor_id_idx on audit_log (actor_id, created_at);
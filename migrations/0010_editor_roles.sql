@@ -0,0 +1,9 @@
+-- роли участников чата админов, выданные командой /role.
+-- суперадмины из конфига имеют приоритет над этой таблицей
+create table if not exists editor_roles
+(
+    tg_id bigint primary key,
+    role varchar(16) not null,
+    granted_by bigint not null,
+    updated_at timestamptz not null default now()
+);
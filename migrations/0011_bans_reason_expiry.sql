@@ -0,0 +1,4 @@
+-- причина и срок блокировки, notified_at - когда пользователю сообщили о блокировке
+alter table bans add column if not exists reason text;
+alter table bans add column if not exists expires_at timestamptz;
+alter table bans add column if not exists notified_at timestamptz;
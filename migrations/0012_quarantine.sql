@@ -0,0 +1,19 @@
+-- сообщения, которые фильтр счел спамом. raw_update - исходный вебхук, по нему сообщение
+-- пересылается редакции, если его выпустят из карантина кнопкой «Не спам»
+create table if not exists quarantine
+(
+    id bigserial primary key,
+    user_id bigint not null,
+    user_message_id bigint not null,
+    raw_update jsonb not null,
+    preview text not null default '',
+    score double precision not null,
+    reasons text not null default '',
+    created_at timestamptz not null default now(),
+    reviewed_at timestamptz,
+    reviewed_by bigint,
+    released bool not null default false
+);
+
+create index if not exists quarantine_pending_idx on quarantine (created_at) where reviewed_at is null;
+create index if not exists quarantine_user_id_idx on quarantine (user_id, created_at);
@@ -0,0 +1,25 @@
+-- темы обращений из приветственного сообщения
+create table if not exists categories
+(
+    id serial primary key,
+    code varchar(32) not null unique,
+    title text not null,
+    sort int not null default 0,
+    active bool not null default true
+);
+
+insert into categories (code, title, sort)
+values ('labour', 'Трудовые конфликты и нарушения прав', 10),
+       ('education', 'Медицинское образование и кадры', 20),
+       ('persecution', 'Преследование врачей', 30),
+       ('corruption', 'Коррупция и неэффективное управление', 40),
+       ('shortage', 'Нехватка оборудования, кадров, лекарств', 50),
+       ('threats', 'Угрозы врачам', 60),
+       ('other', 'Другое', 100)
+on conflict (code) do nothing;
+
+alter table appeals add column if not exists category_id int references categories (id);
+create index if not exists appeals_category_id_idx on appeals (category_id, opened_at);
+
+-- тема, выбранная пользователем до первого сообщения, переносится на его следующее обращение
+alter table users_dialog add column if not exists pending_category_id int references categories (id);
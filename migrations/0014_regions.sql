@@ -0,0 +1,6 @@
+-- код субъекта РФ, список субъектов хранится в коде бота
+alter table appeals add column if not exists region_code smallint;
+create index if not exists appeals_region_code_idx on appeals (region_code, opened_at);
+
+-- регион, выбранный пользователем до первого сообщения, переносится на его следующее обращение
+alter table users_dialog add column if not exists pending_region_code smallint;
@@ -0,0 +1,13 @@
+-- настоящие данные пользователей в режиме защиты источников.
+-- в чат админов попадает только псевдоним, данные отсюда показывает /reveal
+create table if not exists source_identities
+(
+    user_id bigint primary key,
+    pseudonym varchar(32) not null,
+    username text,
+    first_name text,
+    last_name text,
+    updated_at timestamptz not null default now()
+);
+
+create index if not exists source_identities_pseudonym_idx on source_identities (pseudonym);
@@ -0,0 +1,19 @@
+-- оригиналы документов, из которых перед пересылкой редакции удалены метаданные.
+-- сами файлы лежат в закрытом хранилище, в чат админов уходит только очищенная копия
+create table if not exists media_originals
+(
+    id bigserial primary key,
+    appeal_id bigint not null references appeals (id),
+    user_id bigint not null,
+    file_unique_id text not null,
+    file_name text,
+    format varchar(16) not null,
+    storage_path text not null,
+    size bigint not null,
+    removed text not null default '',
+    admin_chat_message_id bigint,
+    created_at timestamptz not null default now()
+);
+
+create index if not exists media_originals_appeal_id_idx on media_originals (appeal_id);
+create index if not exists media_originals_admin_chat_message_id_idx on media_originals (admin_chat_message_id);
@@ -0,0 +1,19 @@
+-- учет полученных материалов: хеш оригинала и время получения для подтверждения подлинности.
+-- sha256 пустой, если файл не удалось скачать, причина в error
+create table if not exists evidence
+(
+    id bigserial primary key,
+    appeal_id bigint not null references appeals (id),
+    user_id bigint not null,
+    user_message_id bigint not null,
+    kind varchar(16) not null,
+    file_unique_id text not null,
+    mime_type text,
+    size bigint,
+    sha256 char(64),
+    error text,
+    sent_at timestamptz not null,
+    received_at timestamptz not null default now()
+);
+
+create index if not exists evidence_appeal_id_idx on evidence (appeal_id);
@@ -0,0 +1,6 @@
+-- ключ файла в хранилище (local или s3) для сообщений пользователей с файлами.
+-- документы архивируются очищенными от метаданных, оригиналы - в media_originals
+alter table messages add column if not exists storage_key text;
+alter table messages add column if not exists file_name text;
+
+create index if not exists messages_appeal_id_storage_key_idx on messages (appeal_id) where storage_key is not null;
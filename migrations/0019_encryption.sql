@@ -0,0 +1,18 @@
+-- шифрование содержимого: у каждой строки свой ключ данных enc_data_key, зашифрованный
+-- ключом enc_key_id из конфига. строки без enc_key_id не зашифрованы
+alter table messages add column if not exists enc_key_id text;
+alter table messages add column if not exists enc_data_key bytea;
+
+alter table message_edits add column if not exists enc_key_id text;
+alter table message_edits add column if not exists enc_data_key bytea;
+
+alter table source_identities add column if not exists enc_key_id text;
+alter table source_identities add column if not exists enc_data_key bytea;
+
+alter table media_originals add column if not exists enc_key_id text;
+alter table media_originals add column if not exists enc_data_key bytea;
+
+-- исходный вебхук в карантине хранится строкой, чтобы его можно было зашифровать
+alter table quarantine alter column raw_update type text using raw_update::text;
+alter table quarantine add column if not exists enc_key_id text;
+alter table quarantine add column if not exists enc_data_key bytea;
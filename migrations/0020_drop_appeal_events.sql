@@ -0,0 +1,12 @@
+-- история обращения строится по audit_log. действия, записанные в appeal_events
+-- до появления журнала аудита, переносятся в журнал
+insert into audit_log (actor_id, action, appeal_id, created_at)
+select e.editor_id, e.action, e.appeal_id, e.created_at
+from appeal_events e
+where not exists (
+    select 1 from audit_log a
+    where a.appeal_id = e.appeal_id and a.action = e.action and a.actor_id = e.editor_id
+      and a.created_at between e.created_at - interval '1 minute' and e.created_at + interval '1 minute'
+);
+
+drop table if exists appeal_events;
@@ -0,0 +1,4 @@
+-- псевдонимы удлинены до 12 символов и должны быть уникальны.
+-- короткие псевдонимы бот заменяет при запуске, а до этого они могут совпадать, поэтому в индекс не входят
+drop index if exists source_identities_pseudonym_idx;
+create unique index if not exists source_identities_pseudonym_key on source_identities (pseudonym) where length(pseudonym) >= 12;
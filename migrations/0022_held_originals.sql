@@ -0,0 +1,5 @@
+-- документы, которые не удалось очистить от метаданных, редакции не пересылаются.
+-- held_reason - почему документ задержан, file_id - для выдачи суперадмину файла, который не скачивался.
+-- у таких записей storage_path пустой, если оригинал скачать не удалось
+alter table media_originals add column if not exists file_id text;
+alter table media_originals add column if not exists held_reason text;
@@ -0,0 +1,7 @@
+-- что получила редакция: хеш и размер копии без метаданных, которую выдают /file и API,
+-- отметка о задержанном документе и номер оригинала для /original.
+-- sha256 по-прежнему хеш файла в том виде, в каком его прислал источник
+alter table evidence add column if not exists cleaned_sha256 char(64);
+alter table evidence add column if not exists cleaned_size bigint;
+alter table evidence add column if not exists held boolean not null default false;
+alter table evidence add column if not exists original_id bigint references media_originals (id);
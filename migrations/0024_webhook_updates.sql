@@ -0,0 +1,11 @@
+-- вебхуки в обработке и обработанные: Telegram повторяет update, если не дождался ответа, например
+-- пока бот скачивает и очищает файл. Повтор с тем же update_id не обрабатывается второй раз.
+-- Если обработка упала, запись удаляется, и повтор Telegram обрабатывается заново.
+-- Telegram хранит неполученные обновления сутки, старые записи удаляются при вставке
+create table if not exists webhook_updates
+(
+    update_id bigint primary key,
+    received_at timestamptz not null default now()
+);
+
+create index if not exists webhook_updates_received_at_idx on webhook_updates (received_at);
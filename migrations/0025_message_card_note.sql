@@ -0,0 +1,3 @@
+-- пометки бота на карточке: хеш файла, очистка от метаданных, архив. Нужны, чтобы после правки
+-- сообщения пользователем карточка собиралась заново с теми же пометками
+alter table messages add column if not exists card_note text;